@@ -86,7 +86,6 @@ export default function QuizAttemptPage() {
             'Content-Type': 'application/json',
           },
           body: JSON.stringify({
-            userId: '00000000-0000-0000-0000-000000000001', // TODO: Replace with actual user ID
             quizId: quizId,
             totalQuestions: totalQuestions,
           }),
@@ -184,7 +183,6 @@ export default function QuizAttemptPage() {
         body: JSON.stringify({
           questionId: currentQuestion.id,
           answer: selectedAnswer,
-          isCorrect: true, // TODO: Implement actual answer validation
         }),
       })
 
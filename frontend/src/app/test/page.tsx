@@ -43,7 +43,6 @@ export default function TestPage() {
               'Content-Type': 'application/json',
             },
             body: JSON.stringify({
-              userId: '00000000-0000-0000-0000-000000000001',
               quizId: quizId,
               totalQuestions: 1,
             }),
@@ -219,6 +219,8 @@ export function useQuiz(quizId?: string, options: UseQuizOptions = {}) {
     mutationFn: async (input) => {
       if (!quizId) throw new Error('Quiz ID is required')
       console.log('Updating quiz with input:', JSON.stringify(input, null, 2))
+      // The server rejects updates that do not name the version they edit
+      const version = input.version ?? quiz?.version
       const response = await axios.patch<ApiResponse<Quiz>>(
         `${QUIZ_API_URL}/quizzes/${quizId}`,
         {
@@ -227,7 +229,10 @@ export function useQuiz(quizId?: string, options: UseQuizOptions = {}) {
             ...q,
             type: q.type || 'multiple_choice',
           })),
-        }
+        },
+        version === undefined
+          ? undefined
+          : { headers: { 'If-Match': `"${version}"` } }
       )
       console.log(
         'Update quiz response:',
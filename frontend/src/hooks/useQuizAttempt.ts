@@ -114,7 +114,6 @@ export const useQuizAttempt = () => {
 
       // Create the request payload
       const payload = {
-        userId: '00000000-0000-0000-0000-000000000001', // Test UUID
         quizId,
         totalQuestions,
       }
@@ -275,15 +274,10 @@ export const useQuizAttempt = () => {
         throw new Error('Invalid question ID format')
       }
 
-      // Determine if the answer is correct (in a real app, this would be done on the server)
-      // This is a temporary workaround - for production, the server should validate correctness
-      const isCorrect = true // Assuming all answers are correct for now as a workaround
-
-      // Debugging the exact request payload
+      // Correctness is graded by the study service, never by the client
       const payload = {
         questionId, // Send as a string - the Go server will parse it as UUID
         answer,
-        isCorrect,
       }
       console.log('Request payload:', payload)
 
@@ -44,6 +44,8 @@ export const mockQuiz: Quiz = {
   description: 'A test quiz description',
   topicId: '1',
   creatorId: 'user-1',
+  visibility: 'public',
+  version: 1,
   questions: [
     {
       id: '1',
@@ -14,7 +14,75 @@ export interface Quiz {
   description: string
   topicId?: string
   creatorId: string
+  visibility: 'private' | 'public' | 'shared'
+  version: number
+  revisionId?: string
   questions: Question[]
+  // Random questions drawn from question pools for every attempt
+  draws?: PoolDraw[]
+  settings?: QuizSettings
+  // Set on listings, which leave questions out unless asked for them
+  questionCount?: number
+  attemptCount?: number
+  createdAt: string
+  updatedAt: string
+}
+
+// A field-level problem with a quiz, e.g. field "questions[2].correctAnswer"
+export interface ValidationError {
+  field: string
+  error: string
+}
+
+// The result of POST /api/quizzes/validate
+export interface QuizValidation {
+  valid: boolean
+  errors: ValidationError[]
+}
+
+export type RevealMode = 'immediately' | 'after_submission' | 'never'
+
+// How a quiz is delivered; unset fields impose no limits
+export interface QuizSettings {
+  timeLimit?: number // seconds
+  shuffleQuestions?: boolean
+  shuffleOptions?: boolean
+  maxAttempts?: number
+  passingScore?: number // percentage
+  revealAnswers?: RevealMode
+}
+
+export interface PoolDraw {
+  poolId: string
+  count: number
+  // Only draw questions of these difficulties; all when unset
+  difficulties?: Difficulty[]
+}
+
+export type Difficulty = 'easy' | 'medium' | 'hard'
+
+// A reusable bank of questions that quizzes draw from
+export interface QuestionPool {
+  id: string
+  ownerId: string
+  title: string
+  description?: string
+  visibility: 'private' | 'public'
+  questionCount: number
+  questions?: Question[]
+  createdAt: string
+  updatedAt: string
+}
+
+export interface Topic {
+  id: string
+  parentId?: string
+  slug: string
+  name: string
+  description?: string
+  quizCount: number
+  totalQuizCount: number
+  children?: Topic[]
   createdAt: string
   updatedAt: string
 }
@@ -22,15 +90,70 @@ export interface Quiz {
 export interface Question {
   id: string
   quizId: string
+  poolId?: string
   text: string
-  type: 'multiple_choice' | 'true_false' | 'open_ended'
+  type: 'multiple_choice' | 'true_false' | 'open_ended' | 'multiple_select' | 'numeric' | 'matching' | 'ordering' | 'cloze'
+  difficulty?: Difficulty
   options: string[]
+  prompts?: string[]
   correctAnswer: string
+  correctAnswers?: string[]
+  matches?: string[]
+  correctOrder?: string[]
+  scoring?: 'all_or_nothing' | 'partial_credit'
+  grading?: 'auto' | 'manual' | 'ai'
+  numeric?: NumericAnswer
+  blanks?: ClozeBlank[]
+  acceptedAnswers?: AcceptedAnswer[]
+  rubric?: string
+  media?: Attachment[]
+  // One entry per option, in the same order; null for options without media
+  optionMedia?: (Attachment | null)[]
   explanation?: string
   createdAt: string
   updatedAt: string
 }
 
+// An uploaded image or audio file shown with a question or option. Only
+// mediaId and altText are sent when saving; url and thumbnailUrl are signed
+// links that expire.
+export interface Attachment {
+  mediaId: string
+  kind?: 'image' | 'audio'
+  contentType?: string
+  size?: number
+  width?: number
+  height?: number
+  hasThumbnail?: boolean
+  altText?: string
+  url?: string
+  thumbnailUrl?: string
+}
+
+// A blank of a cloze question, placed in the text as {{1}}, {{2}}, ...
+export interface ClozeBlank {
+  kind: 'text' | 'dropdown'
+  options?: string[]
+  answers?: string[]
+  ignoreCase?: boolean
+  ignoreAccents?: boolean
+}
+
+export interface AcceptedAnswer {
+  answer: string
+  match: 'exact' | 'case_insensitive' | 'whitespace_normalized' | 'regex' | 'levenshtein'
+  maxDistance?: number
+}
+
+export interface NumericAnswer {
+  value: number
+  tolerance?: number
+  toleranceType?: 'absolute' | 'relative'
+  unit?: string
+  units?: { unit: string; multiplier: number }[]
+  unitRequired?: boolean
+}
+
 export interface Option {
   id: string
   text: string
@@ -39,11 +162,17 @@ export interface Option {
 export interface QuizAttempt {
   id: string
   quizId: string
+  quizVersion?: number
+  quizRevisionId?: string
   userId: string
+  status?: 'in_progress' | 'completed' | 'abandoned' | 'pending_review'
   score: number
   answers: Answer[]
   startedAt: string
   completedAt?: string
+  settings?: QuizSettings
+  expiresAt?: string
+  passed?: boolean
 }
 
 export interface Answer {
@@ -51,6 +180,9 @@ export interface Answer {
   selectedOptionId?: string
   textAnswer?: string
   isCorrect: boolean
+  needsReview?: boolean
+  reviewComment?: string
+  reviewedAt?: string
 }
 
 // User-related types
@@ -90,5 +222,7 @@ export interface CreateQuizInput {
 export interface UpdateQuizInput {
   title?: string
   description?: string
+  // Version last read; the server rejects stale updates with 409
+  version?: number
   questions?: Array<Omit<Question, 'quizId' | 'createdAt' | 'updatedAt'>>
 }
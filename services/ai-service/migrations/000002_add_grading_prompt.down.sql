@@ -0,0 +1 @@
+DELETE FROM prompt_templates WHERE id = '5f0c6a52-3c1e-4a8e-9f0a-6b7d2e1c9a40';
@@ -21,6 +21,16 @@ type SaveFeedbackRequest struct {
 	Comment string `json:"comment" validate:"required,min=1,max=1000"`
 }
 
+type GradeAnswerRequest struct {
+	UserID    string `json:"userId" validate:"required,uuid4"`
+	ModelID   string `json:"modelId" validate:"omitempty,uuid4"`
+	PromptID  string `json:"promptId" validate:"omitempty,uuid4"`
+	Question  string `json:"question" validate:"required"`
+	AnswerKey string `json:"answerKey"`
+	Rubric    string `json:"rubric"`
+	Answer    string `json:"answer" validate:"max=10000"`
+}
+
 // Response Models
 
 type GenerationResponse struct {
@@ -36,6 +46,14 @@ type GenerationResponse struct {
 	CreatedAt       time.Time         `json:"createdAt"`
 }
 
+type GradeResponse struct {
+	GenerationID string  `json:"generationId"`
+	ModelUsed    string  `json:"modelUsed"`
+	Score        float64 `json:"score"`
+	Confidence   float64 `json:"confidence"`
+	Rationale    string  `json:"rationale"`
+}
+
 type UserStatsResponse struct {
 	InteractionStats *InteractionStats `json:"interactionStats"`
 	LastGenStats     *GenerationStats  `json:"lastGenStats,omitempty"`
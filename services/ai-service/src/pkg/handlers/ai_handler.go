@@ -6,6 +6,7 @@ import (
 
 	"QuizApp/services/ai-service/src/pkg/api"
 	"QuizApp/services/ai-service/src/pkg/middleware"
+	"QuizApp/services/ai-service/src/pkg/models"
 	"QuizApp/services/ai-service/src/pkg/service"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -34,6 +35,10 @@ func NewAIHandler(router *mux.Router, service *service.AIService, logger *zap.Lo
 		middleware.ValidateRequest(api.SaveFeedbackRequest{}, logger)(
 			http.HandlerFunc(handler.SaveFeedback))).Methods("POST")
 
+	router.Handle("/api/v1/ai/grade",
+		middleware.ValidateRequest(api.GradeAnswerRequest{}, logger)(
+			http.HandlerFunc(handler.GradeAnswer))).Methods("POST")
+
 	router.HandleFunc("/api/v1/ai/stats/{userId}", handler.GetUserStats).Methods("GET")
 }
 
@@ -74,6 +79,45 @@ func (h *AIHandler) GenerateContent(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, response)
 }
 
+// GradeAnswer handles grading of open-ended answers
+func (h *AIHandler) GradeAnswer(w http.ResponseWriter, r *http.Request) {
+	// Get validated request from context
+	req := r.Context().Value("validated_request").(*api.GradeAnswerRequest)
+
+	userID, _ := uuid.Parse(req.UserID) // Already validated by middleware
+	var modelID, promptID uuid.UUID
+	if req.ModelID != "" {
+		modelID, _ = uuid.Parse(req.ModelID)
+	}
+	if req.PromptID != "" {
+		promptID, _ = uuid.Parse(req.PromptID)
+	}
+
+	grade, err := h.service.GradeAnswer(r.Context(), userID, modelID, promptID, models.GradingInput{
+		Question:  req.Question,
+		AnswerKey: req.AnswerKey,
+		Rubric:    req.Rubric,
+		Answer:    req.Answer,
+	})
+	if err != nil {
+		h.logger.Error("Failed to grade answer", zap.Error(err))
+		writeError(w, api.NewErrorResponse(
+			api.ErrCodeInternal,
+			"Failed to grade answer",
+			nil,
+		))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &api.GradeResponse{
+		GenerationID: grade.GenerationID.String(),
+		ModelUsed:    grade.ModelUsed,
+		Score:        grade.Score,
+		Confidence:   grade.Confidence,
+		Rationale:    grade.Rationale,
+	})
+}
+
 // SaveFeedback handles feedback submission
 func (h *AIHandler) SaveFeedback(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
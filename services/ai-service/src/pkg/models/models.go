@@ -31,6 +31,24 @@ type Generation struct {
 	CreatedAt       time.Time        `json:"created_at"`
 }
 
+// GradingInput is an open-ended answer to grade and what to grade it against
+type GradingInput struct {
+	Question  string `json:"question"`
+	AnswerKey string `json:"answer_key"`
+	Rubric    string `json:"rubric"`
+	Answer    string `json:"answer"`
+}
+
+// Grade is an AI model's grade for an answer: the share of the question's
+// credit it earned and the model's confidence, both from 0 to 1
+type Grade struct {
+	GenerationID uuid.UUID `json:"generation_id"`
+	ModelUsed    string    `json:"model_used"`
+	Score        float64   `json:"score"`
+	Confidence   float64   `json:"confidence"`
+	Rationale    string    `json:"rationale"`
+}
+
 // Feedback represents user feedback for an AI generation
 type Feedback struct {
 	ID           uuid.UUID `json:"id"`
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"QuizApp/services/ai-service/src/pkg/models"
+	"github.com/google/uuid"
+)
+
+// GradingPromptID is the prompt template that grades open-ended answers,
+// installed by migration 000002_add_grading_prompt
+var GradingPromptID = uuid.MustParse("5f0c6a52-3c1e-4a8e-9f0a-6b7d2e1c9a40")
+
+// ErrInvalidGrade is returned when a model's reply is not a usable grade
+var ErrInvalidGrade = errors.New("model did not return a valid grade")
+
+// GradeAnswer asks an AI model to grade an open-ended answer against the
+// answer key and rubric. A nil modelID or promptID selects the configured
+// default model or GradingPromptID. The prompt and reply are recorded as a
+// generation, which the grade refers to.
+func (s *AIService) GradeAnswer(ctx context.Context, userID, modelID, promptID uuid.UUID, input models.GradingInput) (*models.Grade, error) {
+	if modelID == uuid.Nil {
+		model, err := s.repo.Models().GetModelByName(ctx, s.config.AI.DefaultModel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default model %q: %w", s.config.AI.DefaultModel, err)
+		}
+		modelID = model.ID
+	}
+	if promptID == uuid.Nil {
+		promptID = GradingPromptID
+	}
+
+	gen, err := s.GenerateContent(ctx, userID, modelID, promptID, map[string]string{
+		"question":   input.Question,
+		"answer_key": input.AnswerKey,
+		"rubric":     input.Rubric,
+		"answer":     input.Answer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	grade, err := parseGrade(gen.GeneratedContent)
+	if err != nil {
+		return nil, err
+	}
+	grade.GenerationID = gen.ID
+	grade.ModelUsed = gen.ModelUsed
+	return grade, nil
+}
+
+// parseGrade reads the JSON grade in a model's reply, ignoring any text
+// around it, and checks that its score and confidence lie between 0 and 1
+func parseGrade(content string) (*models.Grade, error) {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("%w: no JSON object in reply", ErrInvalidGrade)
+	}
+
+	var reply struct {
+		Score      *float64 `json:"score"`
+		Confidence *float64 `json:"confidence"`
+		Rationale  string   `json:"rationale"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &reply); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGrade, err)
+	}
+	switch {
+	case reply.Score == nil || *reply.Score < 0 || *reply.Score > 1:
+		return nil, fmt.Errorf("%w: score must be between 0 and 1", ErrInvalidGrade)
+	case reply.Confidence == nil || *reply.Confidence < 0 || *reply.Confidence > 1:
+		return nil, fmt.Errorf("%w: confidence must be between 0 and 1", ErrInvalidGrade)
+	}
+
+	return &models.Grade{
+		Score:      *reply.Score,
+		Confidence: *reply.Confidence,
+		Rationale:  strings.TrimSpace(reply.Rationale),
+	}, nil
+}
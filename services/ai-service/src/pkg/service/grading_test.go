@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"QuizApp/services/ai-service/src/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGrade(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *models.Grade
+		wantErr bool
+	}{
+		{
+			name:    "plain JSON",
+			content: `{"score": 0.5, "confidence": 0.9, "rationale": " Names one of two causes. "}`,
+			want:    &models.Grade{Score: 0.5, Confidence: 0.9, Rationale: "Names one of two causes."},
+		},
+		{
+			name:    "JSON wrapped in prose",
+			content: "Here is my grade:\n```json\n{\"score\": 1, \"confidence\": 0.75, \"rationale\": \"Correct.\"}\n```",
+			want:    &models.Grade{Score: 1, Confidence: 0.75, Rationale: "Correct."},
+		},
+		{
+			name:    "no JSON",
+			content: "The answer is mostly right.",
+			wantErr: true,
+		},
+		{
+			name:    "missing confidence",
+			content: `{"score": 0.5, "rationale": "Partly right."}`,
+			wantErr: true,
+		},
+		{
+			name:    "score out of range",
+			content: `{"score": 5, "confidence": 1, "rationale": "Out of five."}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGrade(tt.content)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidGrade)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+const crypto = require("crypto");
+
+const UUID_PATTERN =
+  /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/i;
+
+// Headers the services trust; only the gateway may set them
+const IDENTITY_HEADERS = ["x-user-id", "x-user-role", "x-service-token"];
+
+// Decodes one base64url segment of a JWT as JSON, or returns null
+function decodeSegment(segment) {
+  try {
+    return JSON.parse(Buffer.from(segment, "base64url").toString("utf8"));
+  } catch (error) {
+    return null;
+  }
+}
+
+// Verifies an HS256 JWT signed with secret and returns its claims, or null
+// when the token is malformed, wrongly signed or expired
+function verifyToken(token, secret) {
+  const parts = token.split(".");
+  if (parts.length !== 3) {
+    return null;
+  }
+  const [header, payload, signature] = parts;
+
+  if (decodeSegment(header)?.alg !== "HS256") {
+    return null;
+  }
+  const expected = crypto
+    .createHmac("sha256", secret)
+    .update(`${header}.${payload}`)
+    .digest();
+  const given = Buffer.from(signature, "base64url");
+  if (given.length !== expected.length || !crypto.timingSafeEqual(given, expected)) {
+    return null;
+  }
+
+  const claims = decodeSegment(payload);
+  if (!claims || typeof claims.exp !== "number" || claims.exp * 1000 <= Date.now()) {
+    return null;
+  }
+  return claims;
+}
+
+// Sets X-User-ID, and X-User-Role for admins, from the session token in the
+// Authorization header, signed with JWT_SECRET, and drops any identity
+// headers the client sent itself.
+// Requests without a token go on anonymously; invalid tokens are rejected.
+function authenticate(req, res, next) {
+  for (const header of IDENTITY_HEADERS) {
+    delete req.headers[header];
+  }
+
+  const match = /^Bearer (.+)$/.exec(req.headers.authorization || "");
+  if (!match) {
+    return next();
+  }
+
+  const secret = process.env.JWT_SECRET;
+  const claims = secret ? verifyToken(match[1], secret) : null;
+  if (!claims || typeof claims.sub !== "string" || !UUID_PATTERN.test(claims.sub)) {
+    return res.status(401).json({ success: false, error: "Invalid or expired session" });
+  }
+
+  req.headers["x-user-id"] = claims.sub;
+  if (claims.role === "admin") {
+    req.headers["x-user-role"] = "admin";
+  }
+  next();
+}
+
+module.exports = { authenticate, verifyToken };
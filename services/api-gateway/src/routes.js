@@ -3,6 +3,7 @@ const { createProxyMiddleware } = require("http-proxy-middleware");
 const bodyParser = require("body-parser");
 const cors = require("cors");
 const fetch = require("node-fetch");
+const { authenticate } = require("./auth");
 
 const router = express.Router();
 
@@ -22,8 +23,9 @@ router.use(
       "Accept-Encoding",
       "X-CSRF-Token",
       "Cache-Control",
+      "If-Match",
     ],
-    exposedHeaders: ["Content-Length", "Content-Type"],
+    exposedHeaders: ["Content-Length", "Content-Type", "ETag"],
     maxAge: 43200, // 12 hours
   })
 );
@@ -31,6 +33,9 @@ router.use(
 // Parse JSON bodies
 router.use(bodyParser.json());
 
+// Services trust X-User-ID, so it only ever comes from the session token
+router.use(authenticate);
+
 // Add logging middleware for debug
 router.use((req, res, next) => {
   console.log(`[API Gateway] ${req.method} ${req.url}`);
@@ -58,11 +63,13 @@ router.post("/quiz/:quizId/attempt", async (req, res) => {
 
   try {
     // Forward the request to the study service
+    const headers = { "Content-Type": "application/json" };
+    if (req.headers["x-user-id"]) {
+      headers["X-User-ID"] = req.headers["x-user-id"];
+    }
     const response = await fetch("http://study-service:8084/attempts", {
       method: "POST",
-      headers: {
-        "Content-Type": "application/json",
-      },
+      headers,
       body: JSON.stringify(requestBody),
     });
 
@@ -94,7 +101,8 @@ const contentServiceProxy = createProxyMiddleware({
   followRedirects: true,
   autoRewrite: true,
   onProxyReq: (proxyReq, req, res) => {
-    if (req.body && (req.method === "POST" || req.method === "PUT")) {
+    // Re-send bodies the JSON parser consumed; uploads stream through as they are
+    if (req.body && req.is("application/json") && (req.method === "POST" || req.method === "PUT")) {
       const bodyData = JSON.stringify(req.body);
       proxyReq.setHeader("Content-Length", Buffer.byteLength(bodyData));
       proxyReq.write(bodyData);
@@ -21,8 +21,9 @@ app.use(
       "Accept-Encoding",
       "X-CSRF-Token",
       "Cache-Control",
+      "If-Match",
     ],
-    exposedHeaders: ["Content-Length", "Content-Type"],
+    exposedHeaders: ["Content-Length", "Content-Type", "ETag"],
     maxAge: 43200, // 12 hours
   })
 );
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_questions_quiz_id_position;
+ALTER TABLE questions DROP COLUMN IF EXISTS position;
@@ -0,0 +1,12 @@
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+
+-- Preserve the previous creation-time ordering for existing questions
+UPDATE questions q
+SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY quiz_id ORDER BY created_at, id) AS position
+    FROM questions
+) ordered
+WHERE q.id = ordered.id;
+
+CREATE INDEX IF NOT EXISTS idx_questions_quiz_id_position ON questions(quiz_id, position);
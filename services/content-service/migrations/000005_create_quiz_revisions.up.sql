@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS quiz_revisions (
+    id UUID PRIMARY KEY,
+    quiz_id UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (quiz_id, version)
+);
+
+-- The live revision; NULL until a quiz is first written after this migration
+ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS revision_id UUID;
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS update_content_items_updated_at ON content_items;
+DROP TRIGGER IF EXISTS update_study_sets_updated_at ON study_sets;
+DROP TABLE IF EXISTS shared_access;
+DROP TABLE IF EXISTS content_items;
+DROP TABLE IF EXISTS study_sets;
@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS study_sets (
+    id UUID PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    owner_id UUID NOT NULL,
+    visibility VARCHAR(20) NOT NULL DEFAULT 'private' CHECK (visibility IN ('private', 'public', 'shared')),
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_study_sets_owner_id ON study_sets(owner_id);
+CREATE INDEX IF NOT EXISTS idx_study_sets_tags ON study_sets USING GIN (tags);
+
+CREATE TABLE IF NOT EXISTS content_items (
+    id UUID PRIMARY KEY,
+    study_set_id UUID NOT NULL REFERENCES study_sets(id) ON DELETE CASCADE,
+    content_type VARCHAR(20) NOT NULL CHECK (content_type IN ('flashcard', 'quiz', 'note')),
+    question TEXT NOT NULL,
+    answer TEXT NOT NULL DEFAULT '',
+    hints TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_items_study_set_id ON content_items(study_set_id);
+
+CREATE TABLE IF NOT EXISTS shared_access (
+    study_set_id UUID NOT NULL REFERENCES study_sets(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    access_type VARCHAR(20) NOT NULL CHECK (access_type IN ('viewer', 'editor')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (study_set_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shared_access_user_id ON shared_access(user_id);
+
+CREATE TRIGGER update_study_sets_updated_at
+    BEFORE UPDATE ON study_sets
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_content_items_updated_at
+    BEFORE UPDATE ON content_items
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS quiz_shares;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,13 @@
+-- Existing quizzes were visible to everyone, so they start out public
+ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('private', 'public', 'shared'));
+
+CREATE TABLE IF NOT EXISTS quiz_shares (
+    quiz_id UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    access_type VARCHAR(20) NOT NULL CHECK (access_type IN ('viewer', 'editor')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (quiz_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_shares_user_id ON quiz_shares(user_id);
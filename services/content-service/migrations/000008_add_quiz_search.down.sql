@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_quizzes_search_vector;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,10 @@
+ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+
+CREATE INDEX IF NOT EXISTS idx_quizzes_search_vector ON quizzes USING GIN (search_vector);
+
+-- Index existing quizzes the same way the repository does on every write
+UPDATE quizzes SET search_vector =
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(
+        (SELECT string_agg(text, ' ' ORDER BY position) FROM questions WHERE quiz_id = quizzes.id), '')), 'C');
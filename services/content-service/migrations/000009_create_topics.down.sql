@@ -0,0 +1,3 @@
+ALTER TABLE quizzes DROP CONSTRAINT IF EXISTS fk_quizzes_topic_id;
+DROP TRIGGER IF EXISTS update_topics_updated_at ON topics;
+DROP TABLE IF EXISTS topics;
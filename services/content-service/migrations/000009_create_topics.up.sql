@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS topics (
+    id UUID PRIMARY KEY,
+    parent_id UUID REFERENCES topics(id) ON DELETE RESTRICT,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_topics_parent_id ON topics(parent_id);
+
+CREATE TRIGGER update_topics_updated_at
+    BEFORE UPDATE ON topics
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Quizzes were filed under a placeholder topic that never existed; they
+-- become uncategorized until an author picks a real topic
+ALTER TABLE quizzes ALTER COLUMN topic_id DROP NOT NULL;
+UPDATE quizzes SET topic_id = NULL WHERE topic_id IS NOT NULL;
+
+ALTER TABLE quizzes ADD CONSTRAINT fk_quizzes_topic_id
+    FOREIGN KEY (topic_id) REFERENCES topics(id) ON DELETE SET NULL;
@@ -0,0 +1,12 @@
+-- Enum values cannot be dropped, so rebuild the type without multiple_select
+DELETE FROM questions WHERE type = 'multiple_select';
+
+ALTER TABLE questions DROP COLUMN IF EXISTS scoring;
+ALTER TABLE questions DROP COLUMN IF EXISTS correct_answers;
+
+ALTER TABLE questions ALTER COLUMN type DROP DEFAULT;
+ALTER TYPE question_type RENAME TO question_type_old;
+CREATE TYPE question_type AS ENUM ('multiple_choice', 'true_false', 'open_ended');
+ALTER TABLE questions ALTER COLUMN type TYPE question_type USING type::text::question_type;
+ALTER TABLE questions ALTER COLUMN type SET DEFAULT 'multiple_choice';
+DROP TYPE question_type_old;
@@ -0,0 +1,6 @@
+-- Multiple select questions keep their answer key as a set of options
+ALTER TYPE question_type ADD VALUE IF NOT EXISTS 'multiple_select';
+
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS correct_answers TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS scoring VARCHAR(20)
+    CHECK (scoring IN ('all_or_nothing', 'partial_credit'));
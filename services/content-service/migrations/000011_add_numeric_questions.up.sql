@@ -0,0 +1,5 @@
+-- Numeric questions, and a home for structured answer keys that do not fit
+-- the flat options/correct_answer columns
+ALTER TYPE question_type ADD VALUE IF NOT EXISTS 'numeric';
+
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS answer_spec JSONB;
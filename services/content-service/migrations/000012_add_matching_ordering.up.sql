@@ -0,0 +1,3 @@
+-- Matching and ordering questions keep their prompts and answer keys in answer_spec
+ALTER TYPE question_type ADD VALUE IF NOT EXISTS 'matching';
+ALTER TYPE question_type ADD VALUE IF NOT EXISTS 'ordering';
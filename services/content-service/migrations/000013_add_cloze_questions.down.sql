@@ -0,0 +1,9 @@
+-- Enum values cannot be dropped, so rebuild the type without cloze
+DELETE FROM questions WHERE type = 'cloze';
+
+ALTER TABLE questions ALTER COLUMN type DROP DEFAULT;
+ALTER TYPE question_type RENAME TO question_type_old;
+CREATE TYPE question_type AS ENUM ('multiple_choice', 'true_false', 'open_ended', 'multiple_select', 'numeric', 'matching', 'ordering');
+ALTER TABLE questions ALTER COLUMN type TYPE question_type USING type::text::question_type;
+ALTER TABLE questions ALTER COLUMN type SET DEFAULT 'multiple_choice';
+DROP TYPE question_type_old;
@@ -0,0 +1,2 @@
+-- Cloze questions keep their blanks and accepted answers in answer_spec
+ALTER TYPE question_type ADD VALUE IF NOT EXISTS 'cloze';
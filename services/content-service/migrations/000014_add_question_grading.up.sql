@@ -0,0 +1,3 @@
+-- Open-ended answers can be left for the quiz creator to grade
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS grading VARCHAR(20)
+    CONSTRAINT questions_grading_check CHECK (grading IN ('auto', 'manual'));
@@ -0,0 +1,4 @@
+UPDATE questions SET grading = 'manual' WHERE grading = 'ai';
+
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_grading_check;
+ALTER TABLE questions ADD CONSTRAINT questions_grading_check CHECK (grading IN ('auto', 'manual'));
@@ -0,0 +1,3 @@
+-- Open-ended answers can be graded by an AI model, against a rubric kept in answer_spec
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_grading_check;
+ALTER TABLE questions ADD CONSTRAINT questions_grading_check CHECK (grading IN ('auto', 'manual', 'ai'));
@@ -0,0 +1,15 @@
+-- Uploaded images and audio; the files live in the blob store under keys
+-- derived from the ID, and questions reference them from answer_spec
+CREATE TABLE IF NOT EXISTS media (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('image', 'audio')),
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    width INTEGER,
+    height INTEGER,
+    has_thumbnail BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_owner_id ON media(owner_id);
@@ -0,0 +1,4 @@
+ALTER TABLE quizzes DROP COLUMN IF EXISTS draw_rules;
+ALTER TABLE questions DROP COLUMN IF EXISTS difficulty;
+DROP TABLE IF EXISTS pool_questions;
+DROP TABLE IF EXISTS question_pools;
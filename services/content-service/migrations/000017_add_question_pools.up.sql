@@ -0,0 +1,37 @@
+-- Question pools hold reusable questions outside any quiz; quizzes draw
+-- random questions from them per attempt, following quizzes.draw_rules
+CREATE TABLE IF NOT EXISTS question_pools (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    visibility VARCHAR(20) NOT NULL DEFAULT 'private' CHECK (visibility IN ('private', 'public')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_pools_owner_id ON question_pools(owner_id);
+
+CREATE TABLE IF NOT EXISTS pool_questions (
+    id UUID PRIMARY KEY,
+    pool_id UUID NOT NULL REFERENCES question_pools(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    type question_type NOT NULL,
+    difficulty VARCHAR(10) CHECK (difficulty IN ('easy', 'medium', 'hard')),
+    options TEXT[] NOT NULL,
+    correct_answer TEXT NOT NULL,
+    correct_answers TEXT[] NOT NULL DEFAULT '{}',
+    scoring VARCHAR(20) CHECK (scoring IN ('all_or_nothing', 'partial_credit')),
+    grading VARCHAR(20) CHECK (grading IN ('auto', 'manual', 'ai')),
+    answer_spec JSONB,
+    explanation TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pool_questions_pool_id ON pool_questions(pool_id, position);
+
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS difficulty VARCHAR(10) CHECK (difficulty IN ('easy', 'medium', 'hard'));
+
+ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS draw_rules JSONB;
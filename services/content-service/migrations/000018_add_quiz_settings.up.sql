@@ -0,0 +1,3 @@
+-- Delivery settings of quizzes: time limit, shuffling, attempt limit, pass
+-- mark and when answers are revealed. Empty settings impose none of them.
+ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
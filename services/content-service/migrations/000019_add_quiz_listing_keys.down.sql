@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_quizzes_attempt_count_id;
+DROP INDEX IF EXISTS idx_quizzes_title_id;
+DROP INDEX IF EXISTS idx_quizzes_created_at_id;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS attempt_count;
@@ -0,0 +1,7 @@
+-- Quiz listings page by cursor over these sort keys. attempt_count counts the
+-- attempts learners started, as the study service reports them.
+ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS attempt_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_quizzes_created_at_id ON quizzes(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_quizzes_title_id ON quizzes(title, id);
+CREATE INDEX IF NOT EXISTS idx_quizzes_attempt_count_id ON quizzes(attempt_count DESC, id DESC);
@@ -0,0 +1 @@
+ALTER TABLE topics DROP COLUMN IF EXISTS created_by;
@@ -0,0 +1,3 @@
+-- Topics are shared by every author, so only their creator (or an admin) may
+-- change them. Existing topics have no recorded creator.
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS created_by UUID;
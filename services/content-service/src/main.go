@@ -5,7 +5,9 @@ import (
 
 	"QuizApp/services/content-service/src/pkg/database"
 	"QuizApp/services/content-service/src/pkg/handlers"
+	"QuizApp/services/content-service/src/pkg/middleware"
 	"QuizApp/services/content-service/src/pkg/repository"
+	"QuizApp/services/content-service/src/pkg/storage"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -22,9 +24,23 @@ func main() {
 
     // Initialize repository
     repo := repository.NewPostgresContentRepository(database.GetDB())
+    studySetRepo := repository.NewPostgresStudySetRepository(database.GetDB())
+    topicRepo := repository.NewPostgresTopicRepository(database.GetDB())
+    mediaRepo := repository.NewPostgresMediaRepository(database.GetDB())
+    poolRepo := repository.NewPostgresQuestionPoolRepository(database.GetDB())
+
+    // Initialize the blob store for uploaded media
+    store, err := storage.NewFromEnv()
+    if err != nil {
+        log.Fatalf("Failed to initialize media store: %v", err)
+    }
 
     // Initialize handlers
-    quizHandler := handlers.NewQuizHandler(repo)
+    quizHandler := handlers.NewQuizHandler(repo, mediaRepo, poolRepo, store)
+    studySetHandler := handlers.NewStudySetHandler(studySetRepo)
+    topicHandler := handlers.NewTopicHandler(topicRepo, repo)
+    mediaHandler := handlers.NewMediaHandler(mediaRepo, store)
+    poolHandler := handlers.NewQuestionPoolHandler(poolRepo, mediaRepo, store)
 
     // Initialize router
     r := gin.Default()
@@ -33,12 +49,15 @@ func main() {
     r.Use(cors.New(cors.Config{
         AllowOrigins:     []string{"http://localhost:3000"},
         AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-        AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-        ExposeHeaders:    []string{"Content-Length"},
+        AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-User-ID", "If-Match"},
+        ExposeHeaders:    []string{"Content-Length", "ETag"},
         AllowCredentials: true,
         MaxAge:           12 * 60 * 60,
     }))
 
+    // Resolve the caller from gateway and internal service headers
+    r.Use(middleware.Identity())
+
     // Health check
     r.GET("/health", func(c *gin.Context) {
         c.JSON(200, gin.H{
@@ -51,11 +70,80 @@ func main() {
     quizzes := r.Group("/quizzes")
     {
         quizzes.GET("/", quizHandler.ListQuizzes)
+        quizzes.GET("/search", quizHandler.SearchQuizzes)
+        quizzes.GET("/:id/export", quizHandler.ExportQuiz)
         quizzes.GET("/:id", quizHandler.GetQuiz)
         quizzes.GET("/:id/questions", quizHandler.GetQuizQuestions)
+        quizzes.GET("/:id/draw", quizHandler.DrawQuestions)
+        quizzes.POST("/:id/questions", quizHandler.AddQuestion)
+        quizzes.PUT("/:id/questions/order", quizHandler.ReorderQuestions)
+        quizzes.GET("/:id/revisions", quizHandler.ListRevisions)
+        quizzes.GET("/:id/revisions/diff", quizHandler.DiffRevisions)
+        quizzes.GET("/:id/revisions/:revisionId", quizHandler.GetRevision)
+        quizzes.POST("/:id/revisions/:revisionId/restore", quizHandler.RestoreRevision)
+        quizzes.GET("/:id/shares", quizHandler.ListQuizShares)
+        quizzes.GET("/:id/access", quizHandler.CheckQuizAccess)
+        quizzes.PUT("/:id/shares/:userId", quizHandler.ShareQuiz)
+        quizzes.DELETE("/:id/shares/:userId", quizHandler.RevokeQuizShare)
         quizzes.POST("/", quizHandler.CreateQuiz)
+        quizzes.POST("/validate", quizHandler.ValidateQuiz)
+        quizzes.POST("/import", quizHandler.ImportQuiz)
+        quizzes.POST("/:id/import", quizHandler.ImportQuestions)
         quizzes.PATCH("/:id", quizHandler.UpdateQuiz)
         quizzes.DELETE("/:id", quizHandler.DeleteQuiz)
+        quizzes.POST("/:id/attempts", quizHandler.RecordAttempt)
+    }
+
+    users := r.Group("/users")
+    {
+        users.GET("/:id/quizzes", quizHandler.ListUserQuizzes)
+    }
+
+    // Study set routes - handle both /api/study-sets and /study-sets
+    studySets := r.Group("/study-sets")
+    {
+        studySets.GET("/", studySetHandler.ListStudySets)
+        studySets.GET("/:id", studySetHandler.GetStudySet)
+        studySets.POST("/", studySetHandler.CreateStudySet)
+        studySets.PATCH("/:id", studySetHandler.UpdateStudySet)
+        studySets.DELETE("/:id", studySetHandler.DeleteStudySet)
+        studySets.GET("/:id/items", studySetHandler.ListContentItems)
+        studySets.POST("/:id/items", studySetHandler.AddContentItem)
+        studySets.PATCH("/:id/items/:itemId", studySetHandler.UpdateContentItem)
+        studySets.DELETE("/:id/items/:itemId", studySetHandler.DeleteContentItem)
+        studySets.GET("/:id/shares", studySetHandler.ListSharedAccess)
+        studySets.PUT("/:id/shares/:userId", studySetHandler.ShareStudySet)
+        studySets.DELETE("/:id/shares/:userId", studySetHandler.RevokeAccess)
+    }
+
+    topics := r.Group("/topics")
+    {
+        topics.GET("/", topicHandler.ListTopics)
+        topics.GET("/:id", topicHandler.GetTopic)
+        topics.GET("/:id/quizzes", topicHandler.ListTopicQuizzes)
+        topics.POST("/", topicHandler.CreateTopic)
+        topics.PATCH("/:id", topicHandler.UpdateTopic)
+        topics.DELETE("/:id", topicHandler.DeleteTopic)
+    }
+
+    // Media uploads; signed links to files in the local store are served here too
+    media := r.Group("/media")
+    {
+        media.POST("/", mediaHandler.UploadMedia)
+        media.POST("/urls", mediaHandler.SignMedia)
+        media.GET("/files/*key", mediaHandler.ServeFile)
+    }
+
+    pools := r.Group("/pools")
+    {
+        pools.GET("/", poolHandler.ListPools)
+        pools.POST("/", poolHandler.CreatePool)
+        pools.GET("/:id", poolHandler.GetPool)
+        pools.PATCH("/:id", poolHandler.UpdatePool)
+        pools.DELETE("/:id", poolHandler.DeletePool)
+        pools.POST("/:id/questions", poolHandler.AddPoolQuestion)
+        pools.PUT("/:id/questions/:questionId", poolHandler.UpdatePoolQuestion)
+        pools.DELETE("/:id/questions/:questionId", poolHandler.DeletePoolQuestion)
     }
 
     api := r.Group("/api")
@@ -63,12 +151,78 @@ func main() {
         apiQuizzes := api.Group("/quizzes")
         {
             apiQuizzes.GET("/", quizHandler.ListQuizzes)
+            apiQuizzes.GET("/search", quizHandler.SearchQuizzes)
+            apiQuizzes.GET("/:id/export", quizHandler.ExportQuiz)
             apiQuizzes.GET("/:id", quizHandler.GetQuiz)
             apiQuizzes.GET("/:id/questions", quizHandler.GetQuizQuestions)
+            apiQuizzes.GET("/:id/draw", quizHandler.DrawQuestions)
+            apiQuizzes.POST("/:id/questions", quizHandler.AddQuestion)
+            apiQuizzes.PUT("/:id/questions/order", quizHandler.ReorderQuestions)
+            apiQuizzes.GET("/:id/revisions", quizHandler.ListRevisions)
+            apiQuizzes.GET("/:id/revisions/diff", quizHandler.DiffRevisions)
+            apiQuizzes.GET("/:id/revisions/:revisionId", quizHandler.GetRevision)
+            apiQuizzes.POST("/:id/revisions/:revisionId/restore", quizHandler.RestoreRevision)
+            apiQuizzes.GET("/:id/shares", quizHandler.ListQuizShares)
+            apiQuizzes.GET("/:id/access", quizHandler.CheckQuizAccess)
+            apiQuizzes.PUT("/:id/shares/:userId", quizHandler.ShareQuiz)
+            apiQuizzes.DELETE("/:id/shares/:userId", quizHandler.RevokeQuizShare)
             apiQuizzes.POST("/", quizHandler.CreateQuiz)
+            apiQuizzes.POST("/validate", quizHandler.ValidateQuiz)
+            apiQuizzes.POST("/import", quizHandler.ImportQuiz)
+            apiQuizzes.POST("/:id/import", quizHandler.ImportQuestions)
             apiQuizzes.PATCH("/:id", quizHandler.UpdateQuiz)
             apiQuizzes.DELETE("/:id", quizHandler.DeleteQuiz)
         }
+
+        apiUsers := api.Group("/users")
+        {
+            apiUsers.GET("/:id/quizzes", quizHandler.ListUserQuizzes)
+        }
+
+        apiStudySets := api.Group("/study-sets")
+        {
+            apiStudySets.GET("/", studySetHandler.ListStudySets)
+            apiStudySets.GET("/:id", studySetHandler.GetStudySet)
+            apiStudySets.POST("/", studySetHandler.CreateStudySet)
+            apiStudySets.PATCH("/:id", studySetHandler.UpdateStudySet)
+            apiStudySets.DELETE("/:id", studySetHandler.DeleteStudySet)
+            apiStudySets.GET("/:id/items", studySetHandler.ListContentItems)
+            apiStudySets.POST("/:id/items", studySetHandler.AddContentItem)
+            apiStudySets.PATCH("/:id/items/:itemId", studySetHandler.UpdateContentItem)
+            apiStudySets.DELETE("/:id/items/:itemId", studySetHandler.DeleteContentItem)
+            apiStudySets.GET("/:id/shares", studySetHandler.ListSharedAccess)
+            apiStudySets.PUT("/:id/shares/:userId", studySetHandler.ShareStudySet)
+            apiStudySets.DELETE("/:id/shares/:userId", studySetHandler.RevokeAccess)
+        }
+
+        apiTopics := api.Group("/topics")
+        {
+            apiTopics.GET("/", topicHandler.ListTopics)
+            apiTopics.GET("/:id", topicHandler.GetTopic)
+            apiTopics.GET("/:id/quizzes", topicHandler.ListTopicQuizzes)
+            apiTopics.POST("/", topicHandler.CreateTopic)
+            apiTopics.PATCH("/:id", topicHandler.UpdateTopic)
+            apiTopics.DELETE("/:id", topicHandler.DeleteTopic)
+        }
+
+        apiMedia := api.Group("/media")
+        {
+            apiMedia.POST("/", mediaHandler.UploadMedia)
+            apiMedia.POST("/urls", mediaHandler.SignMedia)
+            apiMedia.GET("/files/*key", mediaHandler.ServeFile)
+        }
+
+        apiPools := api.Group("/pools")
+        {
+            apiPools.GET("/", poolHandler.ListPools)
+            apiPools.POST("/", poolHandler.CreatePool)
+            apiPools.GET("/:id", poolHandler.GetPool)
+            apiPools.PATCH("/:id", poolHandler.UpdatePool)
+            apiPools.DELETE("/:id", poolHandler.DeletePool)
+            apiPools.POST("/:id/questions", poolHandler.AddPoolQuestion)
+            apiPools.PUT("/:id/questions/:questionId", poolHandler.UpdatePoolQuestion)
+            apiPools.DELETE("/:id/questions/:questionId", poolHandler.DeletePoolQuestion)
+        }
     }
 
     r.Run(":8081")
@@ -44,5 +44,366 @@ func InitSchema() error {
 		return fmt.Errorf("error creating questions table: %v", err)
 	}
 
+	// Version quizzes for optimistic concurrency control
+	_, err = db.Exec(`
+		ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding quiz version column: %v", err)
+	}
+
+	// Persist explicit question ordering, backfilling from creation time
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_name = 'questions' AND column_name = 'position'
+			) THEN
+				ALTER TABLE questions ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+				UPDATE questions q
+				SET position = ordered.position
+				FROM (
+					SELECT id, ROW_NUMBER() OVER (PARTITION BY quiz_id ORDER BY created_at, id) AS position
+					FROM questions
+				) ordered
+				WHERE q.id = ordered.id;
+			END IF;
+		END
+		$$;
+
+		CREATE INDEX IF NOT EXISTS idx_questions_quiz_id_position ON questions(quiz_id, position);
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding question position column: %v", err)
+	}
+
+	// Keep an immutable snapshot of every quiz revision
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS quiz_revisions (
+			id UUID PRIMARY KEY,
+			quiz_id UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+			version INTEGER NOT NULL,
+			snapshot JSONB NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			UNIQUE (quiz_id, version)
+		);
+
+		ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS revision_id UUID;
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating quiz revisions table: %v", err)
+	}
+
+	// Create study sets with their items and share grants
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS study_sets (
+			id UUID PRIMARY KEY,
+			title VARCHAR(255) NOT NULL,
+			description TEXT,
+			owner_id UUID NOT NULL,
+			visibility VARCHAR(20) NOT NULL DEFAULT 'private' CHECK (visibility IN ('private', 'public', 'shared')),
+			tags TEXT[] NOT NULL DEFAULT '{}',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_study_sets_owner_id ON study_sets(owner_id);
+		CREATE INDEX IF NOT EXISTS idx_study_sets_tags ON study_sets USING GIN (tags);
+
+		CREATE TABLE IF NOT EXISTS content_items (
+			id UUID PRIMARY KEY,
+			study_set_id UUID NOT NULL REFERENCES study_sets(id) ON DELETE CASCADE,
+			content_type VARCHAR(20) NOT NULL CHECK (content_type IN ('flashcard', 'quiz', 'note')),
+			question TEXT NOT NULL,
+			answer TEXT NOT NULL DEFAULT '',
+			hints TEXT[] NOT NULL DEFAULT '{}',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_content_items_study_set_id ON content_items(study_set_id);
+
+		CREATE TABLE IF NOT EXISTS shared_access (
+			study_set_id UUID NOT NULL REFERENCES study_sets(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL,
+			access_type VARCHAR(20) NOT NULL CHECK (access_type IN ('viewer', 'editor')),
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (study_set_id, user_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_shared_access_user_id ON shared_access(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating study set tables: %v", err)
+	}
+
+	// Quiz visibility and share grants; existing quizzes stay public
+	_, err = db.Exec(`
+		ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+			CHECK (visibility IN ('private', 'public', 'shared'));
+
+		CREATE TABLE IF NOT EXISTS quiz_shares (
+			quiz_id UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL,
+			access_type VARCHAR(20) NOT NULL CHECK (access_type IN ('viewer', 'editor')),
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (quiz_id, user_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_quiz_shares_user_id ON quiz_shares(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding quiz visibility: %v", err)
+	}
+
+	// Full-text search over quiz title, description and question text
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_name = 'quizzes' AND column_name = 'search_vector'
+			) THEN
+				ALTER TABLE quizzes ADD COLUMN search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+
+				UPDATE quizzes SET search_vector =
+					setweight(to_tsvector('english', title), 'A') ||
+					setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
+					setweight(to_tsvector('english', COALESCE(
+						(SELECT string_agg(text, ' ' ORDER BY position) FROM questions WHERE quiz_id = quizzes.id), '')), 'C');
+			END IF;
+		END
+		$$;
+
+		CREATE INDEX IF NOT EXISTS idx_quizzes_search_vector ON quizzes USING GIN (search_vector);
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding quiz search index: %v", err)
+	}
+
+	// Topic taxonomy; quiz topics must reference an existing topic
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS topics (
+			id UUID PRIMARY KEY,
+			parent_id UUID REFERENCES topics(id) ON DELETE RESTRICT,
+			slug VARCHAR(100) NOT NULL UNIQUE,
+			name VARCHAR(255) NOT NULL,
+			description TEXT,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_topics_parent_id ON topics(parent_id);
+
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM information_schema.table_constraints
+				WHERE table_name = 'quizzes' AND constraint_name = 'fk_quizzes_topic_id'
+			) THEN
+				ALTER TABLE quizzes ALTER COLUMN topic_id DROP NOT NULL;
+				UPDATE quizzes SET topic_id = NULL
+				WHERE topic_id IS NOT NULL AND topic_id NOT IN (SELECT id FROM topics);
+				ALTER TABLE quizzes ADD CONSTRAINT fk_quizzes_topic_id
+					FOREIGN KEY (topic_id) REFERENCES topics(id) ON DELETE SET NULL;
+			END IF;
+		END
+		$$;
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating topics table: %v", err)
+	}
+
+	// Multiple select questions; databases created by the migrations use
+	// the question_type enum, which needs the new value
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM pg_type WHERE typname = 'question_type') THEN
+				ALTER TYPE question_type ADD VALUE IF NOT EXISTS 'multiple_select';
+			END IF;
+		END
+		$$;
+
+		ALTER TABLE questions ADD COLUMN IF NOT EXISTS correct_answers TEXT[] NOT NULL DEFAULT '{}';
+		ALTER TABLE questions ADD COLUMN IF NOT EXISTS scoring VARCHAR(20)
+			CHECK (scoring IN ('all_or_nothing', 'partial_credit'));
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding multiple select columns: %v", err)
+	}
+
+	// Numeric questions keep their answer key in answer_spec
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM pg_type WHERE typname = 'question_type') THEN
+				ALTER TYPE question_type ADD VALUE IF NOT EXISTS 'numeric';
+			END IF;
+		END
+		$$;
+
+		ALTER TABLE questions ADD COLUMN IF NOT EXISTS answer_spec JSONB;
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding numeric question columns: %v", err)
+	}
+
+	// Matching and ordering questions also keep their answer keys in answer_spec
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM pg_type WHERE typname = 'question_type') THEN
+				ALTER TYPE question_type ADD VALUE IF NOT EXISTS 'matching';
+				ALTER TYPE question_type ADD VALUE IF NOT EXISTS 'ordering';
+			END IF;
+		END
+		$$;
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding matching and ordering question types: %v", err)
+	}
+
+	// Cloze questions keep their blanks in answer_spec
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM pg_type WHERE typname = 'question_type') THEN
+				ALTER TYPE question_type ADD VALUE IF NOT EXISTS 'cloze';
+			END IF;
+		END
+		$$;
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding cloze question type: %v", err)
+	}
+
+	// Open-ended answers can be left for the quiz creator to grade
+	_, err = db.Exec(`
+		ALTER TABLE questions ADD COLUMN IF NOT EXISTS grading VARCHAR(20)
+			CONSTRAINT questions_grading_check CHECK (grading IN ('auto', 'manual'));
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding question grading column: %v", err)
+	}
+
+	// Open-ended answers can be graded by an AI model
+	_, err = db.Exec(`
+		ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_grading_check;
+		ALTER TABLE questions ADD CONSTRAINT questions_grading_check CHECK (grading IN ('auto', 'manual', 'ai'));
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding AI question grading: %v", err)
+	}
+
+	// Uploaded images and audio attached to questions and options
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS media (
+			id UUID PRIMARY KEY,
+			owner_id UUID NOT NULL,
+			kind VARCHAR(10) NOT NULL CHECK (kind IN ('image', 'audio')),
+			content_type VARCHAR(100) NOT NULL,
+			size BIGINT NOT NULL,
+			width INTEGER,
+			height INTEGER,
+			has_thumbnail BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_media_owner_id ON media(owner_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating media table: %v", err)
+	}
+
+	// Question pools and the per-quiz rules that draw from them
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS question_pools (
+			id UUID PRIMARY KEY,
+			owner_id UUID NOT NULL,
+			title VARCHAR(255) NOT NULL,
+			description TEXT,
+			visibility VARCHAR(20) NOT NULL DEFAULT 'private' CHECK (visibility IN ('private', 'public')),
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_question_pools_owner_id ON question_pools(owner_id);
+
+		CREATE TABLE IF NOT EXISTS pool_questions (
+			id UUID PRIMARY KEY,
+			pool_id UUID NOT NULL REFERENCES question_pools(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			text TEXT NOT NULL,
+			type TEXT NOT NULL,
+			difficulty VARCHAR(10) CHECK (difficulty IN ('easy', 'medium', 'hard')),
+			options TEXT[] NOT NULL,
+			correct_answer TEXT NOT NULL,
+			correct_answers TEXT[] NOT NULL DEFAULT '{}',
+			scoring VARCHAR(20) CHECK (scoring IN ('all_or_nothing', 'partial_credit')),
+			grading VARCHAR(20) CHECK (grading IN ('auto', 'manual', 'ai')),
+			answer_spec JSONB,
+			explanation TEXT,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_pool_questions_pool_id ON pool_questions(pool_id, position);
+
+		ALTER TABLE questions ADD COLUMN IF NOT EXISTS difficulty VARCHAR(10) CHECK (difficulty IN ('easy', 'medium', 'hard'));
+
+		ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS draw_rules JSONB;
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating question pool tables: %v", err)
+	}
+
+	// Pool questions are drawn into quizzes, so where questions use the
+	// question_type enum pool questions use it too
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM pg_type WHERE typname = 'question_type') AND NOT EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_name = 'pool_questions' AND column_name = 'type' AND udt_name = 'question_type'
+			) THEN
+				ALTER TABLE pool_questions ALTER COLUMN type TYPE question_type USING type::question_type;
+			END IF;
+		END
+		$$;
+	`)
+	if err != nil {
+		return fmt.Errorf("error typing pool questions: %v", err)
+	}
+
+	// Delivery settings of quizzes
+	_, err = db.Exec(`
+		ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding quiz settings: %v", err)
+	}
+
+	// Attempt counts and sort keys of quiz listings
+	_, err = db.Exec(`
+		ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS attempt_count INTEGER NOT NULL DEFAULT 0;
+		CREATE INDEX IF NOT EXISTS idx_quizzes_created_at_id ON quizzes(created_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_quizzes_title_id ON quizzes(title, id);
+		CREATE INDEX IF NOT EXISTS idx_quizzes_attempt_count_id ON quizzes(attempt_count DESC, id DESC);
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding quiz listing keys: %v", err)
+	}
+
+	// Creators of topics, who may edit and delete them
+	_, err = db.Exec(`
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS created_by UUID;
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding topic creators: %v", err)
+	}
+
 	return nil
 } 
\ No newline at end of file
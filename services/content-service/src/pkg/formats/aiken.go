@@ -0,0 +1,107 @@
+package formats
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+var (
+	aikenOption = regexp.MustCompile(`^([A-Z])[.)]\s+(.*)$`)
+	aikenAnswer = regexp.MustCompile(`^ANSWER:\s*(\S*)\s*$`)
+)
+
+// aikenQuestion is the question being read
+type aikenQuestion struct {
+	line    int
+	text    []string
+	options []string
+	failed  bool // a problem was reported; skip to the ANSWER line
+}
+
+// ParseAiken parses multiple choice questions in the Aiken format: question
+// text, options labelled "A." or "A)" and an "ANSWER: <letter>" line.
+// The returned error is only set when r cannot be read.
+func ParseAiken(r io.Reader) (*Result, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	var current *aikenQuestion
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		number := i + 1
+		if line == "" {
+			continue
+		}
+
+		if m := aikenAnswer.FindStringSubmatch(line); m != nil {
+			if current == nil || len(current.options) == 0 {
+				result.errorf(number, "ANSWER line without question options")
+			} else if !current.failed {
+				addAikenQuestion(result, current, m[1], number)
+			}
+			current = nil
+			continue
+		}
+
+		m := aikenOption.FindStringSubmatch(line)
+		switch {
+		case m != nil && current != nil && len(current.text) > 0:
+			if current.failed {
+				continue
+			}
+			want := string(rune('A' + len(current.options)))
+			if m[1] != want {
+				result.errorf(number, "expected option %s, got %s", want, m[1])
+				current.failed = true
+				continue
+			}
+			current.options = append(current.options, strings.TrimSpace(m[2]))
+		case current != nil && len(current.options) > 0:
+			// Text after the options means the ANSWER line is missing
+			if !current.failed {
+				result.errorf(current.line, "question has no ANSWER line")
+			}
+			current = &aikenQuestion{line: number, text: []string{line}}
+		case current != nil:
+			current.text = append(current.text, line)
+		default:
+			current = &aikenQuestion{line: number, text: []string{line}}
+		}
+	}
+
+	if current != nil && !current.failed {
+		result.errorf(current.line, "question has no ANSWER line")
+	}
+	return result, nil
+}
+
+// addAikenQuestion checks the answer letter and adds the question
+func addAikenQuestion(result *Result, q *aikenQuestion, letter string, line int) {
+	if len(q.options) < 2 {
+		result.errorf(q.line, "question needs at least two options")
+		return
+	}
+	if len(letter) != 1 || letter[0] < 'A' || int(letter[0]-'A') >= len(q.options) {
+		result.errorf(line, "answer %q does not name one of the options", letter)
+		return
+	}
+
+	seen := make(map[string]bool, len(q.options))
+	for _, option := range q.options {
+		if seen[option] {
+			result.errorf(q.line, "duplicate option %q", option)
+			return
+		}
+		seen[option] = true
+	}
+
+	text := strings.Join(q.text, "\n")
+	result.add(text, models.QuestionTypeMultipleChoice, q.options, q.options[letter[0]-'A'], "")
+}
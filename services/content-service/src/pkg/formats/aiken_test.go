@@ -0,0 +1,49 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+func TestParseAiken(t *testing.T) {
+	source := `Which planet is largest?
+A. Mars
+B. Jupiter
+C) Venus
+ANSWER: B
+
+Which is a mammal?
+A. Shark
+C. Whale
+ANSWER: C
+
+What colour is the sky?
+A. Blue
+B. Green
+
+Is this missing an answer?
+A. Yes
+B. No
+ANSWER: E
+`
+	result, err := ParseAiken(strings.NewReader(source))
+	require.NoError(t, err)
+	require.Len(t, result.Questions, 1)
+
+	q := result.Questions[0]
+	assert.Equal(t, "Which planet is largest?", q.Text)
+	assert.Equal(t, models.QuestionTypeMultipleChoice, q.Type)
+	assert.Equal(t, []string{"Mars", "Jupiter", "Venus"}, q.Options)
+	assert.Equal(t, "Jupiter", q.CorrectAnswer)
+
+	assert.Equal(t, []Problem{
+		{Line: 9, Message: "expected option B, got C"},
+		{Line: 12, Message: "question has no ANSWER line"},
+		{Line: 19, Message: `answer "E" does not name one of the options`},
+	}, result.Errors)
+}
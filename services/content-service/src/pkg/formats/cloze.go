@@ -0,0 +1,160 @@
+package formats
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// Cloze questions are exchanged in Moodle's embedded answer syntax, where
+// each blank is written in place as {weight:TYPE:answers}:
+//
+//	The capital of France is {1:SHORTANSWER:=Paris}.
+//	Water boils at {1:MULTICHOICE:90 °C~=100 °C~120 °C}.
+//
+// Answers are separated by "~" and the accepted ones marked with "=" or
+// %100%; "#" starts an answer's feedback, which is dropped. SHORTANSWER
+// (SA, MW) blanks ignore case, SHORTANSWER_C (SAC, MWC) blanks do not, and
+// the MULTICHOICE variants become dropdown blanks. Answers worth part of
+// the credit are not accepted, and ignoring accents cannot be expressed.
+
+// embeddedHeader matches the start of an embedded answer
+var embeddedHeader = regexp.MustCompile(`^\{(\d*(?:\.\d+)?):([A-Za-z_]+):`)
+
+// parseEmbeddedCloze turns text with embedded answers into question text
+// with {{n}} placeholders and the blanks they stand for
+func parseEmbeddedCloze(source string) (string, []models.ClozeBlank, error) {
+	var text strings.Builder
+	var blanks []models.ClozeBlank
+	for i := 0; i < len(source); {
+		header := embeddedHeader.FindStringSubmatch(source[i:])
+		if source[i] != '{' || header == nil {
+			text.WriteByte(source[i])
+			i++
+			continue
+		}
+
+		start := i + len(header[0])
+		end := indexUnescaped(source[start:], "}")
+		if end < 0 {
+			return "", nil, fmt.Errorf("blank %d is not closed with }", len(blanks)+1)
+		}
+		blank, err := parseEmbeddedAnswers(header[2], source[start:start+end])
+		if err != nil {
+			return "", nil, fmt.Errorf("blank %d: %v", len(blanks)+1, err)
+		}
+		blanks = append(blanks, blank)
+		fmt.Fprintf(&text, "{{%d}}", len(blanks))
+		i = start + end + 1
+	}
+	return text.String(), blanks, nil
+}
+
+// parseEmbeddedAnswers reads the answers of one embedded blank
+func parseEmbeddedAnswers(kind, body string) (models.ClozeBlank, error) {
+	var blank models.ClozeBlank
+	switch strings.ToUpper(kind) {
+	case "SHORTANSWER", "SA", "MW":
+		blank.Kind, blank.IgnoreCase = models.BlankText, true
+	case "SHORTANSWER_C", "SAC", "MWC":
+		blank.Kind = models.BlankText
+	case "MULTICHOICE", "MC", "MULTICHOICE_S", "MCS", "MULTICHOICE_V", "MCV",
+		"MULTICHOICE_VS", "MCVS", "MULTICHOICE_H", "MCH", "MULTICHOICE_HS", "MCHS":
+		blank.Kind = models.BlankDropdown
+	default:
+		return blank, fmt.Errorf("%s blanks are not supported", kind)
+	}
+
+	for _, entry := range splitUnescaped(body, '~') {
+		entry = strings.TrimSpace(entry)
+		accepted := false
+		switch {
+		case strings.HasPrefix(entry, "="):
+			entry, accepted = entry[1:], true
+		case strings.HasPrefix(entry, "%"):
+			if end := strings.Index(entry[1:], "%"); end >= 0 {
+				weight, err := strconv.ParseFloat(entry[1:end+1], 64)
+				accepted = err == nil && weight >= 100
+				entry = entry[end+2:]
+			}
+		}
+		answer := unescapeEmbedded(splitUnescaped(entry, '#')[0])
+		if answer == "" {
+			continue
+		}
+		if blank.Kind == models.BlankDropdown {
+			blank.Options = append(blank.Options, answer)
+		}
+		if accepted {
+			blank.Answers = append(blank.Answers, answer)
+		}
+	}
+	if len(blank.Answers) == 0 {
+		return blank, fmt.Errorf("no answer is marked correct")
+	}
+	return blank, nil
+}
+
+// embeddedCloze writes a cloze question's text with its blanks embedded
+func embeddedCloze(question *models.Question) (string, error) {
+	var err error
+	text := models.ClozeBlankMarkup.ReplaceAllStringFunc(question.Text, func(placeholder string) string {
+		n, _ := strconv.Atoi(models.ClozeBlankMarkup.FindStringSubmatch(placeholder)[1])
+		if n < 1 || n > len(question.Blanks) {
+			err = fmt.Errorf("%w: blank %s is not defined", ErrUnsupportedQuestion, placeholder)
+			return placeholder
+		}
+		return embeddedAnswers(question.Blanks[n-1])
+	})
+	return text, err
+}
+
+// embeddedAnswers writes one blank in embedded answer syntax
+func embeddedAnswers(blank models.ClozeBlank) string {
+	kind := "SHORTANSWER_C"
+	if blank.IgnoreCase {
+		kind = "SHORTANSWER"
+	}
+	var entries []string
+	if blank.Kind == models.BlankDropdown {
+		kind = "MULTICHOICE"
+		for _, option := range blank.Options {
+			entry := escapeEmbedded(option)
+			for _, answer := range blank.Answers {
+				if sameAnswer(option, answer) {
+					entry = "=" + entry
+					break
+				}
+			}
+			entries = append(entries, entry)
+		}
+	} else {
+		for _, answer := range blank.Answers {
+			entries = append(entries, "="+escapeEmbedded(answer))
+		}
+	}
+	return "{1:" + kind + ":" + strings.Join(entries, "~") + "}"
+}
+
+// embeddedEscapes escapes the characters with a meaning in embedded answers
+var embeddedEscapes = strings.NewReplacer(`\`, `\\`, `}`, `\}`, `~`, `\~`, `#`, `\#`, `=`, `\=`, `%`, `\%`)
+
+// escapeEmbedded escapes an answer for embedded answer syntax
+func escapeEmbedded(s string) string {
+	return embeddedEscapes.Replace(s)
+}
+
+// unescapeEmbedded resolves the escapes of an embedded answer
+func unescapeEmbedded(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return strings.TrimSpace(b.String())
+}
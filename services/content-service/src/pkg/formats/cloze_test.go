@@ -0,0 +1,34 @@
+package formats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+func TestParseEmbeddedCloze(t *testing.T) {
+	text, blanks, err := parseEmbeddedCloze(`Il {1:SHORTANSWER:=a été~%50%était#close~=a ete} à {2:MC:Rome~=Paris#Yes~Lyon} {not a blank} {1:SAC:=x\}y\~z}.`)
+	require.NoError(t, err)
+	assert.Equal(t, "Il {{1}} à {{2}} {not a blank} {{3}}.", text)
+	assert.Equal(t, []models.ClozeBlank{
+		{Kind: models.BlankText, Answers: []string{"a été", "a ete"}, IgnoreCase: true},
+		{Kind: models.BlankDropdown, Options: []string{"Rome", "Paris", "Lyon"}, Answers: []string{"Paris"}},
+		{Kind: models.BlankText, Answers: []string{"x}y~z"}},
+	}, blanks)
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"Two is {1:NUMERICAL:=2}", "blank 1: NUMERICAL blanks are not supported"},
+		{"Say {1:SA:=hi}, then {1:SA:~bye}", "blank 2: no answer is marked correct"},
+		{"Open {1:SA:=ended", "blank 1 is not closed with }"},
+	}
+	for _, tt := range tests {
+		_, _, err := parseEmbeddedCloze(tt.source)
+		assert.EqualError(t, err, tt.want)
+	}
+}
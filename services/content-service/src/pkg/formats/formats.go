@@ -0,0 +1,194 @@
+// Package formats converts quiz questions to and from the text and package
+// formats used by other learning platforms.
+package formats
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// ErrUnsupportedQuestion is returned when a question cannot be written in a format
+var ErrUnsupportedQuestion = errors.New("question cannot be exported in this format")
+
+// Problem reports a construct in the source that could not be imported.
+// File is set for multi-file packages, Line when the position is known and
+// Field when the problem is tied to a column of a spreadsheet row.
+type Problem struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// String formats the problem as "file:line: message"
+func (p Problem) String() string {
+	if p.Field != "" {
+		return fmt.Sprintf("line %d: %s: %s", p.Line, p.Field, p.Message)
+	}
+	switch {
+	case p.File != "" && p.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+	case p.File != "":
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	case p.Line == 0:
+		return p.Message
+	default:
+		return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+	}
+}
+
+// Result holds the questions parsed from a source and the problems found on
+// the way. Questions that had problems are left out; Errors make the source
+// unfit for import while Warnings only report questions that were skipped.
+// Title and Description are set when the format carries them.
+type Result struct {
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Questions   []*models.Question `json:"questions"`
+	Errors      []Problem          `json:"errors,omitempty"`
+	Warnings    []Problem          `json:"warnings,omitempty"`
+}
+
+// OK reports whether the whole source was imported
+func (r *Result) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// errorf records a problem at line
+func (r *Result) errorf(line int, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, Problem{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// warnf records a skipped question at line
+func (r *Result) warnf(line int, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, Problem{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// fileErrorf records a problem in a file of a package
+func (r *Result) fileErrorf(file string, line int, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, Problem{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// add appends a question. Its ID is left nil, like its quiz ID, so that it
+// is saved as a new question.
+func (r *Result) add(text string, questionType models.QuestionType, options []string, correctAnswer, explanation string) *models.Question {
+	if options == nil {
+		options = []string{}
+	}
+	question := models.NewQuestion(uuid.Nil, text, questionType, options, correctAnswer, explanation)
+	question.ID = uuid.Nil
+	r.Questions = append(r.Questions, question)
+	return question
+}
+
+// sameAnswer compares answers the way study-service grades them: ignoring
+// case and surrounding or repeated whitespace
+func sameAnswer(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+// answerKey folds an answer for lookups the way sameAnswer compares it
+func answerKey(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// shortAnswers lists the answers an open-ended question accepts, for
+// formats that only know plain short answers, and whether they are compared
+// case-sensitively. Regular expression and Levenshtein rules, and a mix of
+// case-sensitive and case-insensitive rules, cannot be written this way.
+func shortAnswers(question *models.Question) ([]string, bool, error) {
+	if len(question.AcceptedAnswers) == 0 {
+		return []string{question.CorrectAnswer}, false, nil
+	}
+	answers := make([]string, 0, len(question.AcceptedAnswers))
+	caseSensitive := question.AcceptedAnswers[0].Match != models.MatchCaseInsensitive
+	for _, accepted := range question.AcceptedAnswers {
+		switch accepted.Match {
+		case models.MatchRegex, models.MatchLevenshtein:
+			return nil, false, fmt.Errorf("%w: %s answers cannot be written", ErrUnsupportedQuestion, accepted.Match)
+		}
+		if (accepted.Match != models.MatchCaseInsensitive) != caseSensitive {
+			return nil, false, fmt.Errorf("%w: answers mix case-sensitive and case-insensitive matches", ErrUnsupportedQuestion)
+		}
+		answers = append(answers, accepted.Answer)
+	}
+	return answers, caseSensitive, nil
+}
+
+// acceptAnswers sets the answers an imported open-ended question accepts.
+// A single case-insensitive answer needs no match rule.
+func acceptAnswers(question *models.Question, answers []string, caseSensitive bool) {
+	if len(answers) == 1 && !caseSensitive {
+		return
+	}
+	match := models.MatchCaseInsensitive
+	if caseSensitive {
+		match = models.MatchExact
+	}
+	for _, answer := range answers {
+		question.AcceptedAnswers = append(question.AcceptedAnswers, models.AcceptedAnswer{Answer: answer, Match: match})
+	}
+}
+
+// formatNumber writes a number in the shortest form that reads back the same
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// shortTitle shortens question text into a one-line name
+func shortTitle(text string) string {
+	title := strings.Join(strings.Fields(text), " ")
+	if runes := []rune(title); len(runes) > 60 {
+		title = string(runes[:57]) + "..."
+	}
+	return title
+}
+
+// writeXML writes doc as an indented XML document
+func writeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// newXMLDecoder returns a decoder that tolerates the HTML entities and
+// unclosed tags other tools leave in question content
+func newXMLDecoder(r io.Reader) *xml.Decoder {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	dec.AutoClose = xml.HTMLAutoClose
+	return dec
+}
+
+// readLines reads the source into lines without their line endings
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(lines) == 0 {
+			// Files saved by Windows editors often start with a byte order mark
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
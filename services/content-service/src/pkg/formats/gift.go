@@ -0,0 +1,509 @@
+package formats
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// giftFormatMarker matches the text format prefix GIFT allows before question text
+var giftFormatMarker = regexp.MustCompile(`^\[(html|moodle|plain|markdown)\]`)
+
+// giftBlock is one question of a GIFT file: a run of non-blank lines
+type giftBlock struct {
+	line int // line number of the first line
+	text string
+}
+
+// giftAnswer is one "=" or "~" entry of a GIFT answer block
+type giftAnswer struct {
+	correct  bool
+	partial  bool // worth part of the credit, as in multiple answer questions
+	text     string
+	feedback string
+	line     int
+}
+
+// ParseGIFT parses questions in Moodle's GIFT format.
+//
+// Multiple choice questions become multiple_choice, multiple answer
+// questions (answers weighted with %n%) multiple_select with partial credit,
+// {T}/{F} questions true_false, short answer questions open_ended (with
+// case-insensitive accepted answers when there are several), numeric
+// questions numeric and matching questions matching with partial credit.
+// Essay questions ({}) become open_ended graded manually. The returned error
+// is only set when r cannot be read.
+func ParseGIFT(r io.Reader) (*Result, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, block := range giftBlocks(lines) {
+		parseGIFTQuestion(result, block)
+	}
+	return result, nil
+}
+
+// giftBlocks splits the source into questions separated by blank lines.
+// Comment lines are blanked out so that offsets still map to line numbers.
+func giftBlocks(lines []string) []giftBlock {
+	var blocks []giftBlock
+	var current []string
+	start := 0
+
+	flush := func() {
+		if len(current) > 0 {
+			blocks = append(blocks, giftBlock{line: start, text: strings.Join(current, "\n")})
+		}
+		current = nil
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "//"):
+			if len(current) > 0 {
+				current = append(current, "")
+			}
+		default:
+			if len(current) == 0 {
+				start = i + 1
+			}
+			current = append(current, line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// parseGIFTQuestion parses one block and adds the question or its problems to result
+func parseGIFTQuestion(result *Result, block giftBlock) {
+	text := block.text
+	lineAt := func(offset int) int {
+		return block.line + strings.Count(text[:offset], "\n")
+	}
+
+	offset := 0
+	// Categories have no counterpart in a single quiz
+	if strings.HasPrefix(strings.TrimSpace(text), "$CATEGORY:") {
+		end := strings.Index(text, "\n")
+		if end < 0 {
+			return
+		}
+		offset = end + 1
+	}
+
+	// Skip the optional ::title::
+	if rest := strings.TrimLeft(text[offset:], " \t\n"); strings.HasPrefix(rest, "::") {
+		titleStart := len(text) - len(rest) + 2
+		end := indexUnescaped(text[titleStart:], "::")
+		if end < 0 {
+			result.errorf(lineAt(titleStart), "unterminated question title")
+			return
+		}
+		offset = titleStart + end + 2
+	}
+
+	open := indexUnescaped(text[offset:], "{")
+	if open < 0 {
+		result.errorf(lineAt(offset), "question has no answer block; descriptions are not supported")
+		return
+	}
+	open += offset
+	end := indexUnescaped(text[open+1:], "}")
+	if end < 0 {
+		result.errorf(lineAt(open), "unterminated answer block")
+		return
+	}
+	end += open + 1
+	if indexUnescaped(text[end+1:], "{") >= 0 {
+		result.errorf(lineAt(end+1), "questions with several answer blocks are not supported")
+		return
+	}
+
+	// Text after the answer block makes a missing-word question
+	stem := giftText(text[offset:open])
+	if tail := giftText(text[end+1:]); tail != "" {
+		stem = strings.TrimSpace(stem + " _____ " + tail)
+	}
+	if stem == "" {
+		result.errorf(lineAt(offset), "question text is empty")
+		return
+	}
+
+	body := text[open+1 : end]
+	bodyLine := func(i int) int { return lineAt(open + 1 + i) }
+
+	// "####" introduces general feedback, which we keep as the explanation
+	var general string
+	if i := indexUnescaped(body, "####"); i >= 0 {
+		general = giftText(body[i+4:])
+		body = body[:i]
+	}
+
+	trimmed := strings.TrimSpace(body)
+	switch {
+	case trimmed == "":
+		question := result.add(stem, models.QuestionTypeOpenEnded, nil, "", general)
+		question.Grading = models.GradingManual
+	case strings.HasPrefix(trimmed, "#"):
+		parseGIFTNumeric(result, stem, trimmed[1:], general, lineAt(open))
+	default:
+		if parseGIFTTrueFalse(result, stem, trimmed, general) {
+			return
+		}
+		parseGIFTChoices(result, stem, body, general, bodyLine)
+	}
+}
+
+// parseGIFTTrueFalse adds a {T}/{F} question, reporting false if body is not one
+func parseGIFTTrueFalse(result *Result, stem, body, general string) bool {
+	parts := splitUnescaped(body, '#')
+	var answer string
+	switch strings.ToUpper(strings.TrimSpace(parts[0])) {
+	case "T", "TRUE":
+		answer = "true"
+	case "F", "FALSE":
+		answer = "false"
+	default:
+		return false
+	}
+
+	// {T#feedback for a wrong answer#feedback for a right answer}
+	explanation := general
+	if explanation == "" && len(parts) > 2 {
+		explanation = giftText(parts[2])
+	}
+	result.add(stem, models.QuestionTypeTrueFalse, []string{"true", "false"}, answer, explanation)
+	return true
+}
+
+// parseGIFTChoices adds a multiple choice or short answer question
+func parseGIFTChoices(result *Result, stem, body, general string, lineAt func(int) int) {
+	answers, ok := splitGIFTAnswers(result, body, lineAt)
+	if !ok {
+		return
+	}
+
+	if answers[0].correct && strings.Contains(answers[0].text, "->") {
+		parseGIFTMatching(result, stem, answers, general)
+		return
+	}
+
+	var correct []giftAnswer
+	var credited []string
+	options := make([]string, 0, len(answers))
+	seen := make(map[string]bool, len(answers))
+	for _, answer := range answers {
+		if answer.text == "" {
+			result.errorf(answer.line, "answer text is empty")
+			return
+		}
+		if seen[answer.text] {
+			result.errorf(answer.line, "duplicate answer %q", answer.text)
+			return
+		}
+		seen[answer.text] = true
+		options = append(options, answer.text)
+		if answer.correct {
+			correct = append(correct, answer)
+		}
+		if answer.correct || answer.partial {
+			credited = append(credited, answer.text)
+		}
+	}
+
+	// Answers worth part of the credit make a multiple answer question
+	if len(credited) > len(correct) {
+		question := result.add(stem, models.QuestionTypeMultipleSelect, options, "", general)
+		question.CorrectAnswers = credited
+		question.Scoring = models.ScoringPartialCredit
+		return
+	}
+
+	if len(correct) == 0 {
+		result.errorf(answers[0].line, "question has no correct answer")
+		return
+	}
+	if len(correct) > 1 && len(correct) < len(answers) {
+		result.errorf(correct[1].line, "multiple choice questions with several correct answers are not supported")
+		return
+	}
+
+	explanation := general
+	if explanation == "" {
+		explanation = correct[0].feedback
+	}
+
+	// Only "=" answers means a short answer question, which ignores case
+	if len(correct) == len(answers) {
+		question := result.add(stem, models.QuestionTypeOpenEnded, nil, correct[0].text, explanation)
+		accepted := make([]string, len(correct))
+		for i, answer := range correct {
+			accepted[i] = answer.text
+		}
+		acceptAnswers(question, accepted, false)
+		return
+	}
+	result.add(stem, models.QuestionTypeMultipleChoice, options, correct[0].text, explanation)
+}
+
+// splitGIFTAnswers splits an answer block into its "=" and "~" entries
+func splitGIFTAnswers(result *Result, body string, lineAt func(int) int) ([]giftAnswer, bool) {
+	var answers []giftAnswer
+	start := -1
+
+	finish := func(end int) bool {
+		if start < 0 {
+			if strings.TrimSpace(body[:end]) != "" {
+				result.errorf(lineAt(0), "expected = or ~ before answer text")
+				return false
+			}
+			return true
+		}
+
+		answer := giftAnswer{correct: body[start] == '=', line: lineAt(start)}
+		raw := body[start+1 : end]
+
+		// %weight% prefixes give partial credit; negative weights are penalties
+		if w := strings.TrimSpace(raw); strings.HasPrefix(w, "%") {
+			end := strings.Index(w[1:], "%")
+			if end < 0 {
+				result.errorf(answer.line, "unterminated answer weight")
+				return false
+			}
+			weight, err := strconv.ParseFloat(w[1:end+1], 64)
+			switch {
+			case err != nil:
+				result.errorf(answer.line, "invalid answer weight %q", w[1:end+1])
+				return false
+			case weight == 100:
+				answer.correct = true
+			case weight <= 0:
+				answer.correct = false
+			case weight < 100:
+				answer.partial = true
+			default:
+				result.errorf(answer.line, "answer weight %q is above 100%%", w[1:end+1])
+				return false
+			}
+			raw = w[end+2:]
+		}
+
+		parts := splitUnescaped(raw, '#')
+		answer.text = giftText(parts[0])
+		if len(parts) > 1 {
+			answer.feedback = giftText(strings.Join(parts[1:], "#"))
+		}
+		answers = append(answers, answer)
+		return true
+	}
+
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			if !finish(i) {
+				return nil, false
+			}
+			start = i
+		}
+	}
+	if !finish(len(body)) {
+		return nil, false
+	}
+	if len(answers) == 0 {
+		result.errorf(lineAt(0), "answer block has no answers")
+		return nil, false
+	}
+	return answers, true
+}
+
+// parseGIFTMatching adds a matching question from "=prompt -> option"
+// answers. An answer with no prompt adds an option that matches nothing.
+// Like Moodle, each pair earns its share of the credit.
+func parseGIFTMatching(result *Result, stem string, answers []giftAnswer, general string) {
+	var prompts, matches, options []string
+	seenPrompt := make(map[string]bool, len(answers))
+	seenOption := make(map[string]bool, len(answers))
+	for _, answer := range answers {
+		arrow := strings.Index(answer.text, "->")
+		if !answer.correct || arrow < 0 {
+			result.errorf(answer.line, "matching answers must be written =prompt -> option")
+			return
+		}
+		prompt := strings.TrimSpace(answer.text[:arrow])
+		option := strings.TrimSpace(answer.text[arrow+2:])
+		if option == "" {
+			result.errorf(answer.line, "matching answer has no option")
+			return
+		}
+		if !seenOption[option] {
+			seenOption[option] = true
+			options = append(options, option)
+		}
+		if prompt == "" {
+			continue
+		}
+		if seenPrompt[prompt] {
+			result.errorf(answer.line, "duplicate prompt %q", prompt)
+			return
+		}
+		seenPrompt[prompt] = true
+		prompts = append(prompts, prompt)
+		matches = append(matches, option)
+	}
+	if len(prompts) < 2 {
+		result.errorf(answers[0].line, "matching questions need at least two prompts")
+		return
+	}
+
+	question := result.add(stem, models.QuestionTypeMatching, options, "", general)
+	question.Prompts = prompts
+	question.Matches = matches
+	question.Scoring = models.ScoringPartialCredit
+}
+
+// parseGIFTNumeric adds a {#...} question. Values may carry a tolerance
+// ({#3.14:0.01}) or be given as a range ({#3.1..3.2}), which becomes its
+// midpoint with half the range as the tolerance.
+func parseGIFTNumeric(result *Result, stem, body, general string, line int) {
+	entries := splitUnescaped(body, '=')
+	var specs []string
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) != "" {
+			specs = append(specs, entry)
+		}
+	}
+	if len(specs) == 0 {
+		result.errorf(line, "numeric question has no answer")
+		return
+	}
+	if len(specs) > 1 {
+		result.errorf(line, "numeric questions with several answers are not supported")
+		return
+	}
+
+	parts := splitUnescaped(specs[0], '#')
+	spec := strings.TrimSpace(parts[0])
+	spec = strings.TrimSpace(strings.TrimPrefix(spec, "%100%"))
+
+	explanation := general
+	if explanation == "" && len(parts) > 1 {
+		explanation = giftText(strings.Join(parts[1:], "#"))
+	}
+
+	number := func(s string) (float64, bool) {
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			result.errorf(line, "invalid number %q", strings.TrimSpace(s))
+		}
+		return n, err == nil
+	}
+
+	answer := &models.NumericAnswer{}
+	switch {
+	case strings.Contains(spec, ".."):
+		bounds := strings.SplitN(spec, "..", 2)
+		low, ok := number(bounds[0])
+		if !ok {
+			return
+		}
+		high, ok := number(bounds[1])
+		if !ok {
+			return
+		}
+		if low > high {
+			result.errorf(line, "numeric range %s is empty", spec)
+			return
+		}
+		answer.Value, answer.Tolerance = (low+high)/2, (high-low)/2
+	case strings.Contains(spec, ":"):
+		pair := strings.SplitN(spec, ":", 2)
+		value, ok := number(pair[0])
+		if !ok {
+			return
+		}
+		tolerance, err := strconv.ParseFloat(strings.TrimSpace(pair[1]), 64)
+		if err != nil || tolerance < 0 {
+			result.errorf(line, "invalid tolerance %q", strings.TrimSpace(pair[1]))
+			return
+		}
+		answer.Value, answer.Tolerance = value, tolerance
+	default:
+		value, ok := number(spec)
+		if !ok {
+			return
+		}
+		answer.Value = value
+	}
+
+	question := result.add(stem, models.QuestionTypeNumeric, nil, "", explanation)
+	question.Numeric = answer
+}
+
+// giftText turns raw GIFT text into plain text: the format marker is
+// dropped, whitespace collapsed and escapes resolved
+func giftText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = giftFormatMarker.ReplaceAllString(s, "")
+	return strings.TrimSpace(unescapeGIFT(s))
+}
+
+// unescapeGIFT resolves backslash escapes; \n stands for a line break
+func unescapeGIFT(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// indexUnescaped returns the index of the first sub in s that is not
+// preceded by a backslash escape, or -1
+func indexUnescaped(s, sub string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitUnescaped splits s around every sep that is not escaped
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
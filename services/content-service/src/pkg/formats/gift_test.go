@@ -0,0 +1,126 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+func TestParseGIFT(t *testing.T) {
+	source := `// Geography
+$CATEGORY: geography
+
+::Capital:: What is the capital of France? {
+	~London#No
+	=Paris#Right, Paris.
+	~Berlin
+}
+
+The sun rises in the east.{T}
+
+Two plus two equals {=four =4} in English.
+
+What is 7 times 6? {#42}
+
+Pi to two places. {#3.14:0.005}
+
+Pick a number from one to five. {#1..5}
+
+::Escapes:: Which is a GIFT control character? {=\~ ~a ~b####Escape it with a backslash.}
+
+Pick the primes. {~%50%2 ~%50%3 ~%-100%4}
+
+Match the sounds. {
+	=cat -> meow
+	=dog -> woof
+	=kitten -> meow
+	= -> moo
+}
+`
+	result, err := ParseGIFT(strings.NewReader(source))
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	require.Len(t, result.Questions, 9)
+
+	mc := result.Questions[0]
+	assert.Equal(t, "What is the capital of France?", mc.Text)
+	assert.Equal(t, models.QuestionTypeMultipleChoice, mc.Type)
+	assert.Equal(t, []string{"London", "Paris", "Berlin"}, mc.Options)
+	assert.Equal(t, "Paris", mc.CorrectAnswer)
+	assert.Equal(t, "Right, Paris.", mc.Explanation)
+
+	tf := result.Questions[1]
+	assert.Equal(t, models.QuestionTypeTrueFalse, tf.Type)
+	assert.Equal(t, "true", tf.CorrectAnswer)
+
+	short := result.Questions[2]
+	assert.Equal(t, "Two plus two equals _____ in English.", short.Text)
+	assert.Equal(t, models.QuestionTypeOpenEnded, short.Type)
+	assert.Equal(t, "four", short.CorrectAnswer)
+	assert.Equal(t, []models.AcceptedAnswer{
+		{Answer: "four", Match: models.MatchCaseInsensitive},
+		{Answer: "4", Match: models.MatchCaseInsensitive},
+	}, short.AcceptedAnswers)
+
+	numeric := result.Questions[3]
+	assert.Equal(t, models.QuestionTypeNumeric, numeric.Type)
+	assert.Empty(t, numeric.CorrectAnswer)
+	assert.Equal(t, &models.NumericAnswer{Value: 42}, numeric.Numeric)
+	assert.Equal(t, &models.NumericAnswer{Value: 3.14, Tolerance: 0.005}, result.Questions[4].Numeric)
+	assert.Equal(t, &models.NumericAnswer{Value: 3, Tolerance: 2}, result.Questions[5].Numeric)
+
+	escaped := result.Questions[6]
+	assert.Equal(t, []string{"~", "a", "b"}, escaped.Options)
+	assert.Equal(t, "~", escaped.CorrectAnswer)
+	assert.Equal(t, "Escape it with a backslash.", escaped.Explanation)
+
+	multi := result.Questions[7]
+	assert.Equal(t, models.QuestionTypeMultipleSelect, multi.Type)
+	assert.Equal(t, []string{"2", "3", "4"}, multi.Options)
+	assert.Equal(t, []string{"2", "3"}, multi.CorrectAnswers)
+	assert.Equal(t, models.ScoringPartialCredit, multi.Scoring)
+
+	matching := result.Questions[8]
+	assert.Equal(t, models.QuestionTypeMatching, matching.Type)
+	assert.Equal(t, []string{"meow", "woof", "moo"}, matching.Options)
+	assert.Equal(t, []string{"cat", "dog", "kitten"}, matching.Prompts)
+	assert.Equal(t, []string{"meow", "woof", "meow"}, matching.Matches)
+	assert.Empty(t, matching.Validate())
+}
+
+func TestParseGIFTErrors(t *testing.T) {
+	source := `Write an essay. {}
+
+Match these. {
+	=cat -> meow
+	~dog -> woof
+}
+
+Pi to two places. {#3.14:close}
+
+Too much. {~%150%a ~b}
+
+Fine question. {=yes ~no}
+
+Just a description.
+`
+	result, err := ParseGIFT(strings.NewReader(source))
+	require.NoError(t, err)
+	require.Len(t, result.Questions, 2)
+	essay := result.Questions[0]
+	assert.Equal(t, models.QuestionTypeOpenEnded, essay.Type)
+	assert.Equal(t, models.GradingManual, essay.Grading)
+	assert.Empty(t, essay.Validate())
+	assert.Equal(t, "Fine question.", result.Questions[1].Text)
+
+	assert.Equal(t, []Problem{
+		{Line: 5, Message: "matching answers must be written =prompt -> option"},
+		{Line: 8, Message: `invalid tolerance "close"`},
+		{Line: 10, Message: `answer weight "150" is above 100%`},
+		{Line: 14, Message: "question has no answer block; descriptions are not supported"},
+	}, result.Errors)
+}
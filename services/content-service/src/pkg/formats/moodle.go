@@ -0,0 +1,629 @@
+package formats
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// moodleCategoryRoot prefixes category paths in Moodle exports
+const moodleCategoryRoot = "$course$/top/"
+
+// Grading types of Moodle's ordering question type that map to our scoring modes
+const (
+	moodleOrderingAllOrNothing = "ALL_OR_NOTHING"
+	moodleOrderingRelative     = "RELATIVE_ALL_PREVIOUS_AND_NEXT"
+)
+
+var (
+	moodleLineBreak = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>`)
+	moodleTag       = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Moodle XML question bank, written by WriteMoodleXML and read back by
+// ParseMoodleXML. Only the elements that map to our question types are modelled.
+
+type moodleQuiz struct {
+	XMLName   xml.Name         `xml:"quiz"`
+	Questions []moodleQuestion `xml:"question"`
+}
+
+type moodleText struct {
+	Format string `xml:"format,attr,omitempty"`
+	Text   string `xml:"text"`
+}
+
+type moodleQuestion struct {
+	Type            string              `xml:"type,attr"`
+	Category        *moodleText         `xml:"category,omitempty"`
+	Info            *moodleText         `xml:"info,omitempty"`
+	Name            *moodleText         `xml:"name,omitempty"`
+	QuestionText    *moodleText         `xml:"questiontext,omitempty"`
+	GeneralFeedback *moodleText         `xml:"generalfeedback,omitempty"`
+	DefaultGrade    string              `xml:"defaultgrade,omitempty"`
+	Single          string              `xml:"single,omitempty"`
+	ShuffleAnswers  string              `xml:"shuffleanswers,omitempty"`
+	AnswerNumbering string              `xml:"answernumbering,omitempty"`
+	UseCase         string              `xml:"usecase,omitempty"`
+	GraderInfo      *moodleText         `xml:"graderinfo,omitempty"`
+	Answers         []moodleAnswer      `xml:"answer"`
+	SubQuestions    []moodleSubQuestion `xml:"subquestion"`
+	Units           *moodleUnits        `xml:"units,omitempty"`
+	UnitGradingType string              `xml:"unitgradingtype,omitempty"`
+	UnitPenalty     string              `xml:"unitpenalty,omitempty"`
+	LayoutType      string              `xml:"layouttype,omitempty"`
+	SelectType      string              `xml:"selecttype,omitempty"`
+	GradingType     string              `xml:"gradingtype,omitempty"`
+}
+
+type moodleAnswer struct {
+	Fraction  string      `xml:"fraction,attr"`
+	Format    string      `xml:"format,attr,omitempty"`
+	Text      string      `xml:"text"`
+	Feedback  *moodleText `xml:"feedback,omitempty"`
+	Tolerance string      `xml:"tolerance,omitempty"`
+}
+
+// moodleSubQuestion is one prompt of a matching question with the answer
+// it matches. A subquestion without text adds an answer that matches nothing.
+type moodleSubQuestion struct {
+	Format string     `xml:"format,attr,omitempty"`
+	Text   string     `xml:"text"`
+	Answer moodleText `xml:"answer"`
+}
+
+// moodleUnits lists the units of a numerical question. The unit with
+// multiplier 1 is the base unit; a value in the base unit times another
+// unit's multiplier is the value in that unit, so with base unit m, cm has
+// multiplier 100. That is the inverse of models.NumericUnit.Multiplier.
+type moodleUnits struct {
+	Units []moodleUnit `xml:"unit"`
+}
+
+type moodleUnit struct {
+	Multiplier string `xml:"multiplier"`
+	Name       string `xml:"unit_name"`
+}
+
+// WriteMoodleXML writes a quiz as a Moodle XML question bank. The quiz title
+// and description become the category the questions are filed under.
+// Questions whose type has no Moodle counterpart yet cause an error wrapping
+// ErrUnsupportedQuestion.
+func WriteMoodleXML(w io.Writer, quiz *models.Quiz) error {
+	bank := moodleQuiz{}
+	category := moodleQuestion{
+		Type:     "category",
+		Category: &moodleText{Text: moodleCategoryRoot + strings.ReplaceAll(quiz.Title, "/", "//")},
+	}
+	if quiz.Description != "" {
+		category.Info = &moodleText{Format: "plain_text", Text: quiz.Description}
+	}
+	bank.Questions = append(bank.Questions, category)
+
+	for i, question := range quiz.Questions {
+		q, err := moodleQuestionFor(question)
+		if err != nil {
+			return fmt.Errorf("question %d: %w", i+1, err)
+		}
+		bank.Questions = append(bank.Questions, *q)
+	}
+	return writeXML(w, bank)
+}
+
+// moodleQuestionFor maps a question to its Moodle XML element
+func moodleQuestionFor(question *models.Question) (*moodleQuestion, error) {
+	q := &moodleQuestion{
+		Name:         &moodleText{Text: shortTitle(question.Text)},
+		QuestionText: &moodleText{Format: "plain_text", Text: question.Text},
+		DefaultGrade: "1",
+	}
+	if question.Explanation != "" {
+		q.GeneralFeedback = &moodleText{Format: "plain_text", Text: question.Explanation}
+	}
+
+	switch question.Type {
+	case models.QuestionTypeMultipleChoice:
+		q.Type = "multichoice"
+		q.Single = "true"
+		q.ShuffleAnswers = "true"
+		q.AnswerNumbering = "abc"
+		found := false
+		for _, option := range question.Options {
+			fraction := "0"
+			if !found && sameAnswer(option, question.CorrectAnswer) {
+				fraction, found = "100", true
+			}
+			q.Answers = append(q.Answers, moodleAnswer{Fraction: fraction, Format: "plain_text", Text: option})
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: correct answer is not one of the options", ErrUnsupportedQuestion)
+		}
+
+	case models.QuestionTypeMultipleSelect:
+		// Moodle adds up the fractions of the selected answers, so spreading
+		// the credit over the correct options and taking the same share off
+		// for each wrong one grades like partial credit
+		q.Type = "multichoice"
+		q.Single = "false"
+		q.ShuffleAnswers = "true"
+		q.AnswerNumbering = "abc"
+		if len(question.CorrectAnswers) == 0 {
+			return nil, fmt.Errorf("%w: multiple select question has no correct options", ErrUnsupportedQuestion)
+		}
+		share := strconv.FormatFloat(100/float64(len(question.CorrectAnswers)), 'f', 5, 64)
+		found := 0
+		for _, option := range question.Options {
+			fraction := "-" + share
+			for _, correct := range question.CorrectAnswers {
+				if sameAnswer(option, correct) {
+					fraction = share
+					found++
+					break
+				}
+			}
+			q.Answers = append(q.Answers, moodleAnswer{Fraction: fraction, Format: "plain_text", Text: option})
+		}
+		if found != len(question.CorrectAnswers) {
+			return nil, fmt.Errorf("%w: correct answers are not all options", ErrUnsupportedQuestion)
+		}
+
+	case models.QuestionTypeTrueFalse:
+		q.Type = "truefalse"
+		answer, ok := parseTrueFalse(question.CorrectAnswer)
+		if !ok {
+			return nil, fmt.Errorf("%w: true/false answer must be true or false", ErrUnsupportedQuestion)
+		}
+		fractions := map[bool]string{true: "0", false: "0"}
+		fractions[answer] = "100"
+		q.Answers = []moodleAnswer{
+			{Fraction: fractions[true], Format: "moodle_auto_format", Text: "true"},
+			{Fraction: fractions[false], Format: "moodle_auto_format", Text: "false"},
+		}
+
+	case models.QuestionTypeOpenEnded:
+		// Moodle's essay questions are graded by hand, with notes for graders;
+		// AI-graded questions become essays graded by hand against the rubric
+		if question.Grading == models.GradingManual || question.Grading == models.GradingAI {
+			if len(question.AcceptedAnswers) > 0 {
+				return nil, fmt.Errorf("%w: essay questions have no accepted answers", ErrUnsupportedQuestion)
+			}
+			q.Type = "essay"
+			if notes := strings.TrimSpace(question.CorrectAnswer + "\n\n" + question.Rubric); notes != "" {
+				q.GraderInfo = &moodleText{Format: "plain_text", Text: notes}
+			}
+			break
+		}
+		answers, caseSensitive, err := shortAnswers(question)
+		if err != nil {
+			return nil, err
+		}
+		q.Type = "shortanswer"
+		q.UseCase = "0"
+		if caseSensitive {
+			q.UseCase = "1"
+		}
+		for _, answer := range answers {
+			q.Answers = append(q.Answers, moodleAnswer{Fraction: "100", Format: "plain_text", Text: answer})
+		}
+
+	case models.QuestionTypeNumeric:
+		n := question.Numeric
+		if n == nil {
+			return nil, fmt.Errorf("%w: numeric question has no answer", ErrUnsupportedQuestion)
+		}
+		// Moodle tolerances are absolute
+		tolerance := n.Tolerance
+		if n.ToleranceType == models.ToleranceRelative {
+			tolerance = math.Abs(n.Tolerance * n.Value)
+		}
+		q.Type = "numerical"
+		q.Answers = []moodleAnswer{{
+			Fraction:  "100",
+			Format:    "plain_text",
+			Text:      formatNumber(n.Value),
+			Tolerance: formatNumber(tolerance),
+		}}
+		if n.Unit != "" {
+			q.Units = &moodleUnits{Units: []moodleUnit{{Multiplier: "1", Name: n.Unit}}}
+			for _, unit := range n.Units {
+				q.Units.Units = append(q.Units.Units, moodleUnit{Multiplier: formatNumber(1 / unit.Multiplier), Name: unit.Unit})
+			}
+			q.UnitGradingType, q.UnitPenalty = "0", "0"
+			if n.UnitRequired {
+				q.UnitGradingType, q.UnitPenalty = "1", "1"
+			}
+		}
+
+	case models.QuestionTypeMatching:
+		// Moodle grades each prompt separately, like partial credit
+		q.Type = "matching"
+		q.ShuffleAnswers = "true"
+		matched := make(map[string]bool, len(question.Matches))
+		for i, prompt := range question.Prompts {
+			if i >= len(question.Matches) {
+				return nil, fmt.Errorf("%w: prompt %q has no match", ErrUnsupportedQuestion, prompt)
+			}
+			q.SubQuestions = append(q.SubQuestions, moodleSubQuestion{
+				Format: "plain_text",
+				Text:   prompt,
+				Answer: moodleText{Text: question.Matches[i]},
+			})
+			matched[answerKey(question.Matches[i])] = true
+		}
+		for _, option := range question.Options {
+			if !matched[answerKey(option)] {
+				q.SubQuestions = append(q.SubQuestions, moodleSubQuestion{Format: "plain_text", Answer: moodleText{Text: option}})
+			}
+		}
+
+	case models.QuestionTypeCloze:
+		// Moodle's embedded answers question grades each blank separately
+		text, err := embeddedCloze(question)
+		if err != nil {
+			return nil, err
+		}
+		q.Type = "cloze"
+		q.QuestionText.Text = text
+
+	case models.QuestionTypeOrdering:
+		// Moodle's ordering question type lists the items in the right order
+		q.Type = "ordering"
+		q.LayoutType, q.SelectType = "VERTICAL", "ALL"
+		q.GradingType = moodleOrderingAllOrNothing
+		if question.Scoring == models.ScoringPartialCredit {
+			q.GradingType = moodleOrderingRelative
+		}
+		for i, item := range question.CorrectOrder {
+			q.Answers = append(q.Answers, moodleAnswer{Fraction: strconv.Itoa(i + 1), Format: "plain_text", Text: item})
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: type %s has no Moodle counterpart", ErrUnsupportedQuestion, question.Type)
+	}
+	return q, nil
+}
+
+// ParseMoodleXML reads a Moodle XML question bank.
+//
+// multichoice questions with a single answer become multiple_choice, those
+// with several answers multiple_select with partial credit for the answers
+// worth a positive fraction, truefalse true_false, shortanswer open_ended
+// with its answers accepted exactly or ignoring case, as usecase says, essay
+// open_ended graded manually, with the grader information as answer key,
+// numerical numeric, with the units Moodle lists, matching and ordering
+// questions matching and ordering, and embedded answers (cloze) questions
+// cloze. Other question types are skipped with a warning. The name of
+// the first category becomes the quiz title. The returned error is only set
+// when r is not well-formed XML.
+func ParseMoodleXML(r io.Reader) (*Result, error) {
+	dec := newXMLDecoder(r)
+	result := &Result{}
+	sawQuiz := false
+
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "quiz":
+			sawQuiz = true
+		case "question":
+			line, _ := dec.InputPos()
+			var q moodleQuestion
+			if err := dec.DecodeElement(&q, &start); err != nil {
+				return nil, err
+			}
+			parseMoodleQuestion(result, &q, line)
+		default:
+			if !sawQuiz {
+				result.errorf(1, "document is not a Moodle XML question bank")
+				return result, nil
+			}
+		}
+	}
+	return result, nil
+}
+
+// parseMoodleQuestion maps one question element
+func parseMoodleQuestion(result *Result, q *moodleQuestion, line int) {
+	if q.Type == "category" {
+		if result.Title == "" && q.Category != nil {
+			result.Title = moodleCategoryName(q.Category.Text)
+			if q.Info != nil {
+				result.Description = moodlePlain(q.Info)
+			}
+		}
+		return
+	}
+
+	text := moodlePlain(q.QuestionText)
+	explanation := moodlePlain(q.GeneralFeedback)
+	var correct, credited, options []string
+	for _, answer := range q.Answers {
+		value := moodleAnswerText(answer)
+		options = append(options, value)
+		fraction, err := strconv.ParseFloat(strings.TrimSpace(answer.Fraction), 64)
+		if err == nil && fraction >= 100 {
+			correct = append(correct, value)
+		}
+		if err == nil && fraction > 0 {
+			credited = append(credited, value)
+		}
+	}
+
+	switch q.Type {
+	case "multichoice":
+		if q.Single == "false" || q.Single == "0" {
+			if text == "" || len(credited) == 0 {
+				result.errorf(line, "multichoice question needs text and at least one correct answer")
+				return
+			}
+			question := result.add(text, models.QuestionTypeMultipleSelect, options, "", explanation)
+			question.CorrectAnswers = credited
+			question.Scoring = models.ScoringPartialCredit
+			return
+		}
+		if len(correct) != 1 {
+			result.errorf(line, "multichoice question needs exactly one correct answer")
+			return
+		}
+	case "truefalse":
+		answer, ok := false, len(correct) == 1
+		if ok {
+			answer, ok = parseTrueFalse(correct[0])
+		}
+		if !ok {
+			result.errorf(line, "truefalse question needs a true or false answer")
+			return
+		}
+		options, correct = []string{"true", "false"}, []string{strconv.FormatBool(answer)}
+	case "shortanswer":
+		if text == "" || len(correct) == 0 {
+			result.errorf(line, "shortanswer question needs text and at least one correct answer")
+			return
+		}
+		question := result.add(text, models.QuestionTypeOpenEnded, nil, correct[0], explanation)
+		acceptAnswers(question, correct, q.UseCase == "1")
+		return
+	case "essay":
+		if text == "" {
+			result.errorf(line, "%s question has no text", q.Type)
+			return
+		}
+		question := result.add(text, models.QuestionTypeOpenEnded, nil, moodlePlain(q.GraderInfo), explanation)
+		question.Grading = models.GradingManual
+		return
+	case "matching":
+		parseMoodleMatching(result, q, text, explanation, line)
+		return
+	case "ordering":
+		parseMoodleOrdering(result, q, text, explanation, line)
+		return
+	case "cloze":
+		parseMoodleCloze(result, text, explanation, line)
+		return
+	case "numerical":
+		if len(correct) != 1 || len(q.Answers) != 1 {
+			result.warnf(line, "numerical questions with several answers are not supported; skipped")
+			return
+		}
+		if text == "" {
+			result.errorf(line, "%s question has no text", q.Type)
+			return
+		}
+		if answer, ok := moodleNumeric(result, q, line); ok {
+			question := result.add(text, models.QuestionTypeNumeric, nil, "", explanation)
+			question.Numeric = answer
+		}
+		return
+	default:
+		result.warnf(line, "question type %q is not supported; skipped", q.Type)
+		return
+	}
+
+	if text == "" {
+		result.errorf(line, "%s question has no text", q.Type)
+		return
+	}
+
+	questionType := map[string]models.QuestionType{
+		"multichoice": models.QuestionTypeMultipleChoice,
+		"truefalse":   models.QuestionTypeTrueFalse,
+	}[q.Type]
+	result.add(text, questionType, options, correct[0], explanation)
+}
+
+// parseMoodleMatching adds a matching question. Subquestions without text
+// add options that match no prompt.
+func parseMoodleMatching(result *Result, q *moodleQuestion, text, explanation string, line int) {
+	var prompts, matches, options []string
+	seen := make(map[string]bool, len(q.SubQuestions))
+	for _, sub := range q.SubQuestions {
+		prompt := moodlePlain(&moodleText{Format: sub.Format, Text: sub.Text})
+		option := strings.TrimSpace(sub.Answer.Text)
+		if option == "" {
+			continue
+		}
+		if !seen[answerKey(option)] {
+			seen[answerKey(option)] = true
+			options = append(options, option)
+		}
+		if prompt != "" {
+			prompts = append(prompts, prompt)
+			matches = append(matches, option)
+		}
+	}
+	if text == "" || len(prompts) < 2 {
+		result.errorf(line, "matching question needs text and at least two prompts")
+		return
+	}
+	question := result.add(text, models.QuestionTypeMatching, options, "", explanation)
+	question.Prompts = prompts
+	question.Matches = matches
+	question.Scoring = models.ScoringPartialCredit
+}
+
+// parseMoodleCloze adds an embedded answers question as a cloze question
+// with partial credit per blank
+func parseMoodleCloze(result *Result, text, explanation string, line int) {
+	clozeText, blanks, err := parseEmbeddedCloze(text)
+	if err != nil {
+		result.errorf(line, "cloze question: %v", err)
+		return
+	}
+	if len(blanks) == 0 {
+		result.errorf(line, "cloze question has no blanks")
+		return
+	}
+	question := result.add(clozeText, models.QuestionTypeCloze, nil, "", explanation)
+	question.Blanks = blanks
+	question.Scoring = models.ScoringPartialCredit
+}
+
+// parseMoodleOrdering adds an ordering question. The answers' fractions
+// give their position in the right order.
+func parseMoodleOrdering(result *Result, q *moodleQuestion, text, explanation string, line int) {
+	type item struct {
+		text     string
+		position float64
+	}
+	items := make([]item, 0, len(q.Answers))
+	for i, answer := range q.Answers {
+		position, err := strconv.ParseFloat(strings.TrimSpace(answer.Fraction), 64)
+		if err != nil {
+			position = float64(i + 1)
+		}
+		items = append(items, item{text: moodleAnswerText(answer), position: position})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].position < items[j].position })
+
+	if text == "" || len(items) < 2 {
+		result.errorf(line, "ordering question needs text and at least two items")
+		return
+	}
+	order := make([]string, len(items))
+	for i, it := range items {
+		order[i] = it.text
+	}
+	question := result.add(text, models.QuestionTypeOrdering, order, "", explanation)
+	question.CorrectOrder = append([]string(nil), order...)
+	if strings.TrimSpace(q.GradingType) != moodleOrderingAllOrNothing {
+		question.Scoring = models.ScoringPartialCredit
+	}
+}
+
+// moodleNumeric reads the answer key of a numerical question
+func moodleNumeric(result *Result, q *moodleQuestion, line int) (*models.NumericAnswer, bool) {
+	answer := &models.NumericAnswer{}
+	value, err := strconv.ParseFloat(moodleAnswerText(q.Answers[0]), 64)
+	if err != nil {
+		result.errorf(line, "invalid number %q", moodleAnswerText(q.Answers[0]))
+		return nil, false
+	}
+	answer.Value = value
+	if tolerance := strings.TrimSpace(q.Answers[0].Tolerance); tolerance != "" {
+		t, err := strconv.ParseFloat(tolerance, 64)
+		if err != nil || t < 0 {
+			result.errorf(line, "invalid tolerance %q", tolerance)
+			return nil, false
+		}
+		answer.Tolerance = t
+	}
+	if q.Units == nil {
+		return answer, true
+	}
+
+	for _, unit := range q.Units.Units {
+		name := strings.TrimSpace(unit.Name)
+		multiplier, err := strconv.ParseFloat(strings.TrimSpace(unit.Multiplier), 64)
+		if err != nil || multiplier <= 0 {
+			result.errorf(line, "unit %q has an invalid multiplier %q", name, strings.TrimSpace(unit.Multiplier))
+			return nil, false
+		}
+		if multiplier == 1 && answer.Unit == "" {
+			answer.Unit = name
+			continue
+		}
+		answer.Units = append(answer.Units, models.NumericUnit{Unit: name, Multiplier: 1 / multiplier})
+	}
+	if answer.Unit == "" && len(answer.Units) > 0 {
+		result.errorf(line, "numerical question has units but none with multiplier 1")
+		return nil, false
+	}
+	grading := strings.TrimSpace(q.UnitGradingType)
+	answer.UnitRequired = answer.Unit != "" && grading != "" && grading != "0"
+	return answer, true
+}
+
+// moodlePlain returns the plain text of a text element. HTML, the format
+// Moodle itself exports, is reduced to text; other formats are kept verbatim.
+func moodlePlain(t *moodleText) string {
+	if t == nil {
+		return ""
+	}
+	if t.Format == "html" {
+		return htmlToText(t.Text)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// moodleAnswerText returns the plain text of an answer
+func moodleAnswerText(a moodleAnswer) string {
+	return moodlePlain(&moodleText{Format: a.Format, Text: a.Text})
+}
+
+// moodleCategoryName returns the last segment of a category path.
+// Moodle escapes "/" inside a segment as "//".
+func moodleCategoryName(path string) string {
+	path = strings.ReplaceAll(strings.TrimSpace(path), "//", "\x00")
+	segments := strings.Split(path, "/")
+	name := segments[len(segments)-1]
+	if strings.HasPrefix(name, "$") || name == "top" {
+		return ""
+	}
+	return strings.ReplaceAll(name, "\x00", "/")
+}
+
+// htmlToText strips tags and entities, keeping paragraph breaks as new lines
+func htmlToText(s string) string {
+	s = moodleLineBreak.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(moodleTag.ReplaceAllString(s, ""))
+
+	lines := strings.Split(s, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// parseTrueFalse parses the spellings formats use for true/false answers
+func parseTrueFalse(s string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "t":
+		return true, true
+	case "false", "f":
+		return false, true
+	default:
+		return false, false
+	}
+}
@@ -0,0 +1,112 @@
+package formats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+func TestMoodleXMLRoundTrip(t *testing.T) {
+	quiz := models.NewQuiz("Chemistry / Basics", "Atoms & <molecules>", uuid.New(), nil)
+	quiz.Questions = []*models.Question{
+		models.NewQuestion(quiz.ID, "Which is a noble gas?", models.QuestionTypeMultipleChoice, []string{"Neon", "Oxygen", "Sodium"}, "Neon", "Group 18 <full shell>."),
+		models.NewQuestion(quiz.ID, "Water is H2O.", models.QuestionTypeTrueFalse, []string{"true", "false"}, "true", ""),
+		models.NewQuestion(quiz.ID, "Symbol for gold?", models.QuestionTypeOpenEnded, []string{}, "Au", "From the Latin aurum."),
+		models.NewQuestion(quiz.ID, "Which are metals?", models.QuestionTypeMultipleSelect, []string{"Iron", "Neon", "Zinc"}, "", ""),
+		models.NewQuestion(quiz.ID, "Length of the rod?", models.QuestionTypeNumeric, []string{}, "", ""),
+		models.NewQuestion(quiz.ID, "Match the symbols", models.QuestionTypeMatching, []string{"Fe", "Zn", "Au"}, "", ""),
+		// Moodle lists ordering items in the right order
+		models.NewQuestion(quiz.ID, "Order by atomic number", models.QuestionTypeOrdering, []string{"H", "He", "Li"}, "", ""),
+		models.NewQuestion(quiz.ID, "Salt is {{1}}, made of {{2}} and chlorine.", models.QuestionTypeCloze, []string{}, "", ""),
+		models.NewQuestion(quiz.ID, "Why are noble gases inert?", models.QuestionTypeOpenEnded, []string{}, "Full outer shell", ""),
+	}
+	quiz.Questions[2].AcceptedAnswers = []models.AcceptedAnswer{
+		{Answer: "Au", Match: models.MatchExact},
+		{Answer: "Aurum", Match: models.MatchExact},
+	}
+	quiz.Questions[3].CorrectAnswers = []string{"Iron", "Zinc"}
+	quiz.Questions[3].Scoring = models.ScoringPartialCredit
+	quiz.Questions[4].Numeric = &models.NumericAnswer{
+		Value:        1.5,
+		Tolerance:    0.01,
+		Unit:         "m",
+		Units:        []models.NumericUnit{{Unit: "cm", Multiplier: 0.01}, {Unit: "km", Multiplier: 1000}},
+		UnitRequired: true,
+	}
+	quiz.Questions[5].Prompts = []string{"Iron", "Zinc"}
+	quiz.Questions[5].Matches = []string{"Fe", "Zn"}
+	quiz.Questions[5].Scoring = models.ScoringPartialCredit
+	quiz.Questions[6].CorrectOrder = []string{"H", "He", "Li"}
+	quiz.Questions[7].Blanks = []models.ClozeBlank{
+		{Kind: models.BlankText, Answers: []string{"NaCl", "sodium chloride"}, IgnoreCase: true},
+		{Kind: models.BlankDropdown, Options: []string{"sodium", "potassium"}, Answers: []string{"sodium"}},
+	}
+	quiz.Questions[7].Scoring = models.ScoringPartialCredit
+	quiz.Questions[8].Grading = models.GradingManual
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMoodleXML(&buf, quiz))
+
+	result, err := ParseMoodleXML(&buf)
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	require.Empty(t, result.Warnings)
+	assert.Equal(t, quiz.Title, result.Title)
+	assert.Equal(t, quiz.Description, result.Description)
+	require.Len(t, result.Questions, len(quiz.Questions))
+
+	for i, want := range quiz.Questions {
+		got := result.Questions[i]
+		assert.Equal(t, want.Text, got.Text)
+		assert.Equal(t, want.Type, got.Type)
+		assert.Equal(t, want.Options, got.Options)
+		assert.Equal(t, want.CorrectAnswer, got.CorrectAnswer)
+		assert.Equal(t, want.CorrectAnswers, got.CorrectAnswers)
+		assert.Equal(t, want.Prompts, got.Prompts)
+		assert.Equal(t, want.Matches, got.Matches)
+		assert.Equal(t, want.CorrectOrder, got.CorrectOrder)
+		assert.Equal(t, want.Blanks, got.Blanks)
+		assert.Equal(t, want.AcceptedAnswers, got.AcceptedAnswers)
+		assert.Equal(t, want.Scoring, got.Scoring)
+		assert.Equal(t, want.Grading, got.Grading)
+		assert.Equal(t, want.Numeric, got.Numeric)
+		assert.Equal(t, want.Explanation, got.Explanation)
+	}
+}
+
+func TestParseMoodleXMLSkipsUnknownTypes(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<quiz>
+  <question type="calculated">
+    <questiontext format="html"><text>Compute {a} + {b}.</text></questiontext>
+  </question>
+  <question type="multichoice">
+    <questiontext format="html"><text><![CDATA[<p>Pick the <b>largest</b>&nbsp;number</p>]]></text></questiontext>
+    <single>true</single>
+    <answer fraction="0" format="html"><text>1</text></answer>
+    <answer fraction="100.0000000" format="html"><text>3</text></answer>
+  </question>
+  <question type="ddwtos">
+    <questiontext format="html"><text>Drag.</text></questiontext>
+  </question>
+</quiz>`
+
+	result, err := ParseMoodleXML(strings.NewReader(source))
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	require.Len(t, result.Questions, 1)
+	assert.Equal(t, "Pick the largest number", result.Questions[0].Text)
+	assert.Equal(t, []string{"1", "3"}, result.Questions[0].Options)
+	assert.Equal(t, "3", result.Questions[0].CorrectAnswer)
+
+	assert.Equal(t, []Problem{
+		{Line: 3, Message: `question type "calculated" is not supported; skipped`},
+		{Line: 12, Message: `question type "ddwtos" is not supported; skipped`},
+	}, result.Warnings)
+}
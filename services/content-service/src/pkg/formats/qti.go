@@ -0,0 +1,698 @@
+package formats
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+const (
+	qtiNamespace      = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiSchemaLocation = "http://www.imsglobal.org/xsd/imsqti_v2p1 http://www.imsglobal.org/xsd/qti/qtiv2p1/imsqti_v2p1.xsd"
+	qtiMatchCorrect   = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	cpNamespace       = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	xsiNamespace      = "http://www.w3.org/2001/XMLSchema-instance"
+
+	qtiManifestFile = "imsmanifest.xml"
+	qtiTestFile     = "assessment.xml"
+	qtiItemType     = "imsqti_item_xmlv2p1"
+	qtiTestType     = "imsqti_test_xmlv2p1"
+
+	// qtiMaxFileSize bounds each file read from a package
+	qtiMaxFileSize = 1 << 20
+)
+
+// QTI package structure, written by WriteQTI and read back by ParseQTI.
+// Only the parts of IMS Content Packaging 1.1 and QTI 2.1 that map to our
+// question types are modelled.
+
+type cpManifest struct {
+	XMLName       xml.Name     `xml:"manifest"`
+	Xmlns         string       `xml:"xmlns,attr,omitempty"`
+	Identifier    string       `xml:"identifier,attr,omitempty"`
+	Schema        string       `xml:"metadata>schema,omitempty"`
+	SchemaVersion string       `xml:"metadata>schemaversion,omitempty"`
+	Organizations struct{}     `xml:"organizations"`
+	Resources     []cpResource `xml:"resources>resource"`
+}
+
+type cpResource struct {
+	Identifier   string         `xml:"identifier,attr"`
+	Type         string         `xml:"type,attr"`
+	Href         string         `xml:"href,attr,omitempty"`
+	Files        []cpFile       `xml:"file"`
+	Dependencies []cpDependency `xml:"dependency"`
+}
+
+type cpFile struct {
+	Href string `xml:"href,attr"`
+}
+
+type cpDependency struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+type qtiAssessmentTest struct {
+	XMLName        xml.Name `xml:"assessmentTest"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	XmlnsXsi       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Identifier     string   `xml:"identifier,attr"`
+	Title          string   `xml:"title,attr"`
+	TestPart       struct {
+		Identifier     string               `xml:"identifier,attr"`
+		NavigationMode string               `xml:"navigationMode,attr"`
+		SubmissionMode string               `xml:"submissionMode,attr"`
+		Section        qtiAssessmentSection `xml:"assessmentSection"`
+	} `xml:"testPart"`
+}
+
+type qtiAssessmentSection struct {
+	Identifier string          `xml:"identifier,attr"`
+	Title      string          `xml:"title,attr"`
+	Visible    bool            `xml:"visible,attr"`
+	Rubric     *qtiRubricBlock `xml:"rubricBlock,omitempty"`
+	ItemRefs   []qtiItemRef    `xml:"assessmentItemRef"`
+}
+
+type qtiRubricBlock struct {
+	View string `xml:"view,attr"`
+	Text string `xml:"p"`
+}
+
+type qtiItemRef struct {
+	Identifier string `xml:"identifier,attr"`
+	Href       string `xml:"href,attr"`
+}
+
+type qtiAssessmentItem struct {
+	XMLName             xml.Name                `xml:"assessmentItem"`
+	Xmlns               string                  `xml:"xmlns,attr"`
+	XmlnsXsi            string                  `xml:"xmlns:xsi,attr"`
+	SchemaLocation      string                  `xml:"xsi:schemaLocation,attr"`
+	Identifier          string                  `xml:"identifier,attr"`
+	Title               string                  `xml:"title,attr"`
+	Adaptive            bool                    `xml:"adaptive,attr"`
+	TimeDependent       bool                    `xml:"timeDependent,attr"`
+	ResponseDeclaration qtiResponseDeclaration  `xml:"responseDeclaration"`
+	OutcomeDeclarations []qtiOutcomeDeclaration `xml:"outcomeDeclaration"`
+	ItemBody            qtiItemBody             `xml:"itemBody"`
+	ResponseProcessing  qtiResponseProcessing   `xml:"responseProcessing"`
+	ModalFeedback       *qtiModalFeedback       `xml:"modalFeedback,omitempty"`
+}
+
+type qtiResponseDeclaration struct {
+	Identifier  string   `xml:"identifier,attr"`
+	Cardinality string   `xml:"cardinality,attr"`
+	BaseType    string   `xml:"baseType,attr"`
+	Correct     []string `xml:"correctResponse>value"`
+}
+
+type qtiOutcomeDeclaration struct {
+	Identifier  string `xml:"identifier,attr"`
+	Cardinality string `xml:"cardinality,attr"`
+	BaseType    string `xml:"baseType,attr"`
+}
+
+type qtiItemBody struct {
+	Choice       *qtiChoiceInteraction       `xml:"choiceInteraction,omitempty"`
+	ExtendedText *qtiExtendedTextInteraction `xml:"extendedTextInteraction,omitempty"`
+}
+
+type qtiChoiceInteraction struct {
+	ResponseIdentifier string            `xml:"responseIdentifier,attr"`
+	Shuffle            bool              `xml:"shuffle,attr"`
+	MaxChoices         int               `xml:"maxChoices,attr"`
+	Prompt             string            `xml:"prompt"`
+	Choices            []qtiSimpleChoice `xml:"simpleChoice"`
+}
+
+type qtiSimpleChoice struct {
+	Identifier string `xml:"identifier,attr"`
+	Text       string `xml:",chardata"`
+}
+
+type qtiExtendedTextInteraction struct {
+	ResponseIdentifier string `xml:"responseIdentifier,attr"`
+	ExpectedLines      int    `xml:"expectedLines,attr"`
+	Prompt             string `xml:"prompt"`
+}
+
+type qtiResponseProcessing struct {
+	Template string `xml:"template,attr,omitempty"`
+	Rules    string `xml:",innerxml"`
+}
+
+type qtiModalFeedback struct {
+	OutcomeIdentifier string `xml:"outcomeIdentifier,attr"`
+	Identifier        string `xml:"identifier,attr"`
+	ShowHide          string `xml:"showHide,attr"`
+	Text              string `xml:",chardata"`
+}
+
+// WriteQTI writes a quiz and its questions as a QTI 2.1 content package
+// (zip). Questions whose type has no QTI interaction yet cause an error
+// wrapping ErrUnsupportedQuestion.
+func WriteQTI(w io.Writer, quiz *models.Quiz) error {
+	test := qtiAssessmentTest{
+		Xmlns:          qtiNamespace,
+		XmlnsXsi:       xsiNamespace,
+		SchemaLocation: qtiSchemaLocation,
+		Identifier:     "test-" + quiz.ID.String(),
+		Title:          quiz.Title,
+	}
+	test.TestPart.Identifier = "part-1"
+	test.TestPart.NavigationMode = "linear"
+	test.TestPart.SubmissionMode = "individual"
+	test.TestPart.Section = qtiAssessmentSection{Identifier: "section-1", Title: quiz.Title, Visible: true}
+	if quiz.Description != "" {
+		test.TestPart.Section.Rubric = &qtiRubricBlock{View: "candidate", Text: quiz.Description}
+	}
+
+	testResource := cpResource{
+		Identifier: test.Identifier,
+		Type:       qtiTestType,
+		Href:       qtiTestFile,
+		Files:      []cpFile{{Href: qtiTestFile}},
+	}
+	manifest := cpManifest{
+		Xmlns:         cpNamespace,
+		Identifier:    "manifest-" + quiz.ID.String(),
+		Schema:        "QTIv2.1 Package",
+		SchemaVersion: "1.0.0",
+	}
+
+	files := make(map[string]interface{}, len(quiz.Questions)+2)
+	var itemResources []cpResource
+	for i, question := range quiz.Questions {
+		item, err := qtiItemFor(question)
+		if err != nil {
+			return fmt.Errorf("question %d: %w", i+1, err)
+		}
+		href := "items/" + item.Identifier + ".xml"
+		files[href] = item
+
+		test.TestPart.Section.ItemRefs = append(test.TestPart.Section.ItemRefs, qtiItemRef{Identifier: item.Identifier, Href: href})
+		testResource.Dependencies = append(testResource.Dependencies, cpDependency{IdentifierRef: item.Identifier})
+		itemResources = append(itemResources, cpResource{
+			Identifier: item.Identifier,
+			Type:       qtiItemType,
+			Href:       href,
+			Files:      []cpFile{{Href: href}},
+		})
+	}
+	manifest.Resources = append([]cpResource{testResource}, itemResources...)
+	files[qtiTestFile] = test
+
+	archive := zip.NewWriter(w)
+	// The manifest goes first, followed by the test and the items in order
+	if err := writeXMLFile(archive, qtiManifestFile, manifest); err != nil {
+		return err
+	}
+	for _, resource := range manifest.Resources {
+		if err := writeXMLFile(archive, resource.Href, files[resource.Href]); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// qtiItemFor maps a question to an assessment item
+func qtiItemFor(question *models.Question) (*qtiAssessmentItem, error) {
+	item := &qtiAssessmentItem{
+		Xmlns:          qtiNamespace,
+		XmlnsXsi:       xsiNamespace,
+		SchemaLocation: qtiSchemaLocation,
+		Identifier:     "item-" + question.ID.String(),
+		Title:          shortTitle(question.Text),
+		OutcomeDeclarations: []qtiOutcomeDeclaration{
+			{Identifier: "SCORE", Cardinality: "single", BaseType: "float"},
+		},
+		ResponseProcessing: qtiResponseProcessing{Template: qtiMatchCorrect},
+	}
+
+	switch question.Type {
+	case models.QuestionTypeMultipleChoice:
+		choice := &qtiChoiceInteraction{ResponseIdentifier: "RESPONSE", MaxChoices: 1, Prompt: question.Text}
+		correct := ""
+		for i, option := range question.Options {
+			id := fmt.Sprintf("choice-%d", i+1)
+			choice.Choices = append(choice.Choices, qtiSimpleChoice{Identifier: id, Text: option})
+			if correct == "" && sameAnswer(option, question.CorrectAnswer) {
+				correct = id
+			}
+		}
+		if correct == "" {
+			return nil, fmt.Errorf("%w: correct answer is not one of the options", ErrUnsupportedQuestion)
+		}
+		item.ItemBody.Choice = choice
+		item.ResponseDeclaration = qtiResponseDeclaration{Identifier: "RESPONSE", Cardinality: "single", BaseType: "identifier", Correct: []string{correct}}
+
+	case models.QuestionTypeTrueFalse:
+		value, ok := parseTrueFalse(question.CorrectAnswer)
+		if !ok {
+			return nil, fmt.Errorf("%w: true/false answer must be true or false", ErrUnsupportedQuestion)
+		}
+		answer := strconv.FormatBool(value)
+		item.ItemBody.Choice = &qtiChoiceInteraction{
+			ResponseIdentifier: "RESPONSE",
+			MaxChoices:         1,
+			Prompt:             question.Text,
+			Choices:            []qtiSimpleChoice{{Identifier: "true", Text: "True"}, {Identifier: "false", Text: "False"}},
+		}
+		item.ResponseDeclaration = qtiResponseDeclaration{Identifier: "RESPONSE", Cardinality: "single", BaseType: "identifier", Correct: []string{answer}}
+
+	case models.QuestionTypeOpenEnded:
+		answers, _, err := shortAnswers(question)
+		if err != nil {
+			return nil, err
+		}
+		if len(answers) > 1 {
+			return nil, fmt.Errorf("%w: text interactions have a single correct response", ErrUnsupportedQuestion)
+		}
+		// Manually graded questions may have no answer key to declare
+		if answers[0] == "" {
+			answers = nil
+		}
+		item.ItemBody.ExtendedText = &qtiExtendedTextInteraction{ResponseIdentifier: "RESPONSE", ExpectedLines: 1, Prompt: question.Text}
+		item.ResponseDeclaration = qtiResponseDeclaration{Identifier: "RESPONSE", Cardinality: "single", BaseType: "string", Correct: answers}
+
+	default:
+		return nil, fmt.Errorf("%w: type %s has no QTI interaction", ErrUnsupportedQuestion, question.Type)
+	}
+
+	// match_correct cannot set FEEDBACK, so items with an explanation spell
+	// out the same scoring and always show the explanation afterwards
+	if question.Explanation != "" {
+		item.ResponseProcessing = qtiResponseProcessing{Rules: qtiScoreAndExplain}
+		item.OutcomeDeclarations = append(item.OutcomeDeclarations, qtiOutcomeDeclaration{Identifier: "FEEDBACK", Cardinality: "single", BaseType: "identifier"})
+		item.ModalFeedback = &qtiModalFeedback{OutcomeIdentifier: "FEEDBACK", Identifier: "explanation", ShowHide: "show", Text: question.Explanation}
+	}
+	return item, nil
+}
+
+// qtiScoreAndExplain is match_correct followed by revealing the explanation
+const qtiScoreAndExplain = `
+    <responseCondition>
+      <responseIf>
+        <match>
+          <variable identifier="RESPONSE"/>
+          <correct identifier="RESPONSE"/>
+        </match>
+        <setOutcomeValue identifier="SCORE">
+          <baseValue baseType="float">1</baseValue>
+        </setOutcomeValue>
+      </responseIf>
+      <responseElse>
+        <setOutcomeValue identifier="SCORE">
+          <baseValue baseType="float">0</baseValue>
+        </setOutcomeValue>
+      </responseElse>
+    </responseCondition>
+    <setOutcomeValue identifier="FEEDBACK">
+      <baseValue baseType="identifier">explanation</baseValue>
+    </setOutcomeValue>
+  `
+
+// writeXMLFile adds an indented XML document to the archive
+func writeXMLFile(archive *zip.Writer, name string, doc interface{}) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	return writeXML(f, doc)
+}
+
+// ParseQTI reads a QTI 2.1 content package (zip).
+//
+// The manifest is validated first: every item and file it lists must be in
+// the package, and an assessment test may only refer to listed items. Items
+// are imported in test order, or manifest order without a test. Choice
+// interactions become multiple_choice or true_false and text interactions
+// open_ended, graded manually when they declare no correct response; other
+// interactions are reported as problems. The returned
+// error is only set when r is not a readable zip archive.
+func ParseQTI(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[path.Clean(f.Name)] = f
+	}
+
+	result := &Result{}
+	manifestFile, ok := files[qtiManifestFile]
+	if !ok {
+		result.fileErrorf(qtiManifestFile, 0, "package has no manifest")
+		return result, nil
+	}
+	var manifest cpManifest
+	if err := decodeXMLFile(manifestFile, &manifest); err != nil {
+		result.fileErrorf(qtiManifestFile, 0, "invalid manifest: %v", err)
+		return result, nil
+	}
+
+	// Validate the manifest before reading any item
+	var items, tests []string
+	listed := make(map[string]bool)
+	itemResources := 0
+	for _, resource := range manifest.Resources {
+		missing := make(map[string]bool)
+		for _, file := range resource.Files {
+			href := path.Clean(file.Href)
+			if _, ok := files[href]; !ok && !missing[href] {
+				result.fileErrorf(qtiManifestFile, 0, "resource %s lists missing file %s", resource.Identifier, file.Href)
+				missing[href] = true
+			}
+		}
+		if resource.Type != qtiItemType && resource.Type != qtiTestType {
+			continue
+		}
+		if resource.Type == qtiItemType {
+			itemResources++
+		}
+		href := path.Clean(resource.Href)
+		if resource.Href == "" {
+			result.fileErrorf(qtiManifestFile, 0, "resource %s has no href", resource.Identifier)
+			continue
+		}
+		if _, ok := files[href]; !ok {
+			if !missing[href] {
+				result.fileErrorf(qtiManifestFile, 0, "resource %s lists missing file %s", resource.Identifier, resource.Href)
+			}
+			continue
+		}
+		if resource.Type == qtiTestType {
+			tests = append(tests, href)
+		} else {
+			items = append(items, href)
+			listed[href] = true
+		}
+	}
+	if itemResources == 0 {
+		result.fileErrorf(qtiManifestFile, 0, "manifest lists no assessment items")
+	}
+	if len(tests) > 1 {
+		result.fileErrorf(qtiManifestFile, 0, "packages with several assessment tests are not supported")
+	}
+	if !result.OK() {
+		return result, nil
+	}
+
+	// A test decides the item order and supplies the title
+	if len(tests) == 1 {
+		order, ok := parseQTITest(result, files[tests[0]], tests[0], listed)
+		if !ok {
+			return result, nil
+		}
+		items = order
+	}
+
+	for _, href := range items {
+		parseQTIItem(result, files[href], href)
+	}
+	return result, nil
+}
+
+// parseQTITest reads the title and item order of an assessment test
+func parseQTITest(result *Result, file *zip.File, name string, listed map[string]bool) ([]string, bool) {
+	var test qtiAssessmentTest
+	if err := decodeXMLFile(file, &test); err != nil {
+		result.fileErrorf(name, 0, "invalid assessment test: %v", err)
+		return nil, false
+	}
+	result.Title = strings.TrimSpace(test.Title)
+
+	section := test.TestPart.Section
+	if section.Rubric != nil {
+		result.Description = strings.TrimSpace(section.Rubric.Text)
+	}
+
+	var order []string
+	for _, ref := range section.ItemRefs {
+		href := path.Join(path.Dir(name), ref.Href)
+		if !listed[href] {
+			result.fileErrorf(name, 0, "item %s is not listed in the manifest", ref.Href)
+			continue
+		}
+		order = append(order, href)
+	}
+	return order, result.OK()
+}
+
+// parseQTIItem maps one assessment item to a question
+func parseQTIItem(result *Result, file *zip.File, name string) {
+	f, err := file.Open()
+	if err != nil {
+		result.fileErrorf(name, 0, "cannot read item: %v", err)
+		return
+	}
+	defer f.Close()
+
+	item, err := decodeQTIItem(io.LimitReader(f, qtiMaxFileSize))
+	if err != nil {
+		result.fileErrorf(name, 0, "invalid assessment item: %v", err)
+		return
+	}
+
+	if len(item.interactions) != 1 {
+		result.fileErrorf(name, 0, "items with %d interactions are not supported", len(item.interactions))
+		return
+	}
+	interaction := item.interactions[0]
+	correct := item.correct[interaction.responseIdentifier]
+	text := item.text
+	if text == "" {
+		result.fileErrorf(name, interaction.line, "item has no question text")
+		return
+	}
+
+	switch interaction.kind {
+	case "choiceInteraction":
+		if interaction.maxChoices != 1 || len(correct) != 1 {
+			result.fileErrorf(name, interaction.line, "choice interactions with several correct answers are not supported")
+			return
+		}
+		var options []string
+		answer := ""
+		for _, choice := range interaction.choices {
+			options = append(options, choice.text)
+			if choice.identifier == correct[0] {
+				answer = choice.text
+			}
+		}
+		if answer == "" {
+			result.fileErrorf(name, interaction.line, "correct response %s is not one of the choices", correct[0])
+			return
+		}
+		if isTrueFalse(options) {
+			result.add(text, models.QuestionTypeTrueFalse, []string{"true", "false"}, strings.ToLower(answer), item.feedback)
+			return
+		}
+		result.add(text, models.QuestionTypeMultipleChoice, options, answer, item.feedback)
+
+	case "extendedTextInteraction", "textEntryInteraction":
+		// Without a correct response the answers can only be graded by hand
+		if len(correct) == 0 {
+			question := result.add(text, models.QuestionTypeOpenEnded, nil, "", item.feedback)
+			question.Grading = models.GradingManual
+			return
+		}
+		if len(correct) > 1 {
+			result.fileErrorf(name, interaction.line, "text interactions with several accepted answers are not supported")
+			return
+		}
+		result.add(text, models.QuestionTypeOpenEnded, nil, correct[0], item.feedback)
+
+	default:
+		result.fileErrorf(name, interaction.line, "%s is not supported", interaction.kind)
+	}
+}
+
+// isTrueFalse reports whether the options are exactly "true" and "false"
+func isTrueFalse(options []string) bool {
+	if len(options) != 2 {
+		return false
+	}
+	a, b := strings.ToLower(options[0]), strings.ToLower(options[1])
+	return (a == "true" && b == "false") || (a == "false" && b == "true")
+}
+
+// decodeXMLFile decodes an XML file from the archive into v
+func decodeXMLFile(file *zip.File, v interface{}) error {
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return newXMLDecoder(io.LimitReader(f, qtiMaxFileSize)).Decode(v)
+}
+
+// qtiItem is what ParseQTI reads from an assessment item. Items written by
+// other tools nest XHTML freely around interactions, so it is read token by
+// token rather than into the structs used for writing.
+type qtiItem struct {
+	text         string
+	feedback     string
+	correct      map[string][]string
+	interactions []*qtiInteraction
+}
+
+// qtiInteraction is one interaction found in an item body
+type qtiInteraction struct {
+	kind               string
+	line               int
+	responseIdentifier string
+	maxChoices         int
+	choices            []qtiChoice
+}
+
+// qtiChoice is a simpleChoice of a choice interaction
+type qtiChoice struct {
+	identifier string
+	text       string
+}
+
+// qtiBlockElements separate words in item text
+var qtiBlockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "prompt": true, "blockquote": true,
+}
+
+// decodeQTIItem walks an assessment item. Question text is the item body
+// text outside interactions plus interaction prompts; inline text entry
+// interactions are shown as a blank.
+func decodeQTIItem(r io.Reader) (*qtiItem, error) {
+	dec := newXMLDecoder(r)
+	item := &qtiItem{correct: make(map[string][]string)}
+
+	var (
+		text, capture strings.Builder
+		stack         []string // open element names
+		response      string   // identifier of the open responseDeclaration
+		current       *qtiInteraction
+		choice        *qtiChoice
+		capturing     string // element whose text goes to capture
+		inBody        bool
+		inFeedback    bool
+		feedback      strings.Builder
+	)
+	inside := func(name string) bool {
+		for _, open := range stack {
+			if open == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			stack = append(stack, name)
+			if inBody && qtiBlockElements[name] {
+				text.WriteByte(' ')
+			}
+			switch {
+			case name == "responseDeclaration":
+				response = xmlAttr(t, "identifier")
+			case name == "value" && response != "" && inside("correctResponse"):
+				capturing = name
+				capture.Reset()
+			case name == "itemBody":
+				inBody = true
+			case name == "modalFeedback" || (inBody && (name == "feedbackBlock" || name == "feedbackInline")):
+				inFeedback = true
+			case inBody && strings.HasSuffix(name, "Interaction"):
+				line, _ := dec.InputPos()
+				current = &qtiInteraction{kind: name, line: line, responseIdentifier: xmlAttr(t, "responseIdentifier"), maxChoices: 1}
+				if maxChoices := xmlAttr(t, "maxChoices"); maxChoices != "" {
+					fmt.Sscanf(maxChoices, "%d", &current.maxChoices)
+				}
+				item.interactions = append(item.interactions, current)
+				if name == "textEntryInteraction" || name == "inlineChoiceInteraction" {
+					text.WriteString(" _____ ")
+				}
+			case current != nil && name == "simpleChoice":
+				choice = &qtiChoice{identifier: xmlAttr(t, "identifier")}
+				capturing = name
+				capture.Reset()
+			}
+
+		case xml.EndElement:
+			name := t.Name.Local
+			if inBody && qtiBlockElements[name] {
+				text.WriteByte(' ')
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			switch {
+			case name == capturing && name == "value":
+				item.correct[response] = append(item.correct[response], strings.TrimSpace(capture.String()))
+				capturing = ""
+			case name == capturing && name == "simpleChoice":
+				choice.text = strings.Join(strings.Fields(capture.String()), " ")
+				current.choices = append(current.choices, *choice)
+				choice, capturing = nil, ""
+			case name == "responseDeclaration":
+				response = ""
+			case name == "itemBody":
+				inBody = false
+			case name == "modalFeedback" || name == "feedbackBlock" || name == "feedbackInline":
+				inFeedback = false
+				if item.feedback == "" {
+					item.feedback = strings.Join(strings.Fields(feedback.String()), " ")
+				}
+				feedback.Reset()
+			case current != nil && name == current.kind:
+				current = nil
+			}
+
+		case xml.CharData:
+			switch {
+			case capturing != "":
+				capture.Write(t)
+			case inFeedback:
+				feedback.Write(t)
+			case inBody && (current == nil || inside("prompt")):
+				text.Write(t)
+			}
+		}
+	}
+
+	item.text = strings.Join(strings.Fields(text.String()), " ")
+	return item, nil
+}
+
+// xmlAttr returns the value of the named attribute, or ""
+func xmlAttr(t xml.StartElement, name string) string {
+	for _, attr := range t.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package formats
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+func TestQTIRoundTrip(t *testing.T) {
+	quiz := models.NewQuiz("Science & Nature", "Basic facts", uuid.New(), nil)
+	quiz.Questions = []*models.Question{
+		models.NewQuestion(quiz.ID, "Which gas do plants absorb?", models.QuestionTypeMultipleChoice, []string{"Oxygen", "Carbon dioxide", "Nitrogen"}, "Carbon dioxide", "Photosynthesis uses CO<sub>2</sub>."),
+		models.NewQuestion(quiz.ID, "Water boils at 100 °C at sea level.", models.QuestionTypeTrueFalse, []string{"True", "False"}, "true", ""),
+		models.NewQuestion(quiz.ID, "Name the closest star to Earth.", models.QuestionTypeOpenEnded, nil, "The Sun", ""),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteQTI(&buf, quiz))
+
+	result, err := ParseQTI(&buf)
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	assert.Equal(t, quiz.Title, result.Title)
+	assert.Equal(t, quiz.Description, result.Description)
+	require.Len(t, result.Questions, 3)
+
+	for i, want := range quiz.Questions {
+		got := result.Questions[i]
+		assert.Equal(t, want.Text, got.Text)
+		assert.Equal(t, want.Type, got.Type)
+		assert.Equal(t, want.CorrectAnswer, got.CorrectAnswer)
+		assert.Equal(t, want.Explanation, got.Explanation)
+	}
+	assert.Equal(t, quiz.Questions[0].Options, result.Questions[0].Options)
+}
+
+func TestParseQTIValidatesManifest(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	f, err := archive.Create("imsmanifest.xml")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`<?xml version="1.0"?>
+<manifest xmlns="http://www.imsglobal.org/xsd/imscp_v1p1" identifier="m">
+  <resources>
+    <resource identifier="item-1" type="imsqti_item_xmlv2p1" href="items/missing.xml">
+      <file href="items/missing.xml"/>
+    </resource>
+  </resources>
+</manifest>`))
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+
+	result, err := ParseQTI(&buf)
+	require.NoError(t, err)
+	assert.Empty(t, result.Questions)
+	assert.Equal(t, []Problem{
+		{File: "imsmanifest.xml", Message: "resource item-1 lists missing file items/missing.xml"},
+	}, result.Errors)
+}
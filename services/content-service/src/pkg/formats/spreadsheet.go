@@ -0,0 +1,564 @@
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// Question spreadsheets (CSV or XLSX) have a header row naming the columns
+// followed by one question per row:
+//
+//	text            the question text (required). Cloze questions embed
+//	                their blanks in Moodle's syntax, e.g. "The capital is
+//	                {1:SHORTANSWER:=Paris}."
+//	type            multiple_choice, multiple_select, true_false,
+//	                open_ended, numeric, matching, ordering or cloze
+//	                (required)
+//	options         the options, separated by "|"; write "\|" for a literal
+//	                bar and "\\" for a literal backslash before one. May be
+//	                left empty for true_false questions. For numeric
+//	                questions, the other accepted units with the factor
+//	                converting them to the answer's unit ("cm=0.01|km=1000"),
+//	                plus "required" if answers must state a unit. For
+//	                open_ended questions, the accepted answers, each
+//	                prefixed with its match rule unless it ignores case
+//	                ("Au|exact: AU|regex: gold( \(Au\))?|levenshtein 2:
+//	                aurum"); empty to grade against correct_answer. May be
+//	                left empty for ordering questions, and for matching
+//	                questions whose every option matches a prompt.
+//	correct_answer  the answer key; one of the options for multiple_choice,
+//	                the correct options separated like the options for
+//	                multiple_select, and for numeric the value with an
+//	                optional tolerance and unit ("9.81 ± 0.05 m/s²",
+//	                "1500 +/- 2%"); for matching, each prompt with its
+//	                option, separated like the options ("Cat -> meow|Dog ->
+//	                woof"); for ordering, the options in the right order
+//	explanation     shown after answering (optional)
+//	scoring         all_or_nothing or partial_credit for multiple_select,
+//	                matching, ordering and cloze (optional)
+//	grading         auto or manual for open_ended; manually graded
+//	                questions may leave correct_answer empty (optional)
+//	id              the question's ID (optional)
+//
+// Columns may come in any order and header names ignore case, spaces and
+// underscores. Exports fill in the id column so that a re-imported sheet
+// updates the existing questions instead of replacing them with new ones.
+const (
+	ColumnText          = "text"
+	ColumnType          = "type"
+	ColumnOptions       = "options"
+	ColumnCorrectAnswer = "correct_answer"
+	ColumnExplanation   = "explanation"
+	ColumnScoring       = "scoring"
+	ColumnGrading       = "grading"
+	ColumnID            = "id"
+)
+
+// SpreadsheetColumns is the column order written by WriteCSV and WriteXLSX
+var SpreadsheetColumns = []string{
+	ColumnText, ColumnType, ColumnOptions, ColumnCorrectAnswer, ColumnExplanation, ColumnScoring, ColumnGrading, ColumnID,
+}
+
+// requiredColumns must appear in the header of an imported sheet
+var requiredColumns = []string{ColumnText, ColumnType, ColumnCorrectAnswer}
+
+// optionSeparator separates the options within a cell
+const optionSeparator = '|'
+
+// ParseCSV reads questions from a CSV file in the spreadsheet layout. Every
+// row is checked against the question rules; problems are reported per row,
+// with the column at fault as the Field, and the row's question left out.
+// The returned error is only set when r cannot be read.
+func ParseCSV(r io.Reader) (*Result, error) {
+	reader := bufio.NewReader(r)
+	if bom, err := reader.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		reader.Discard(3)
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	var rows []sheetRow
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result := &Result{}
+			result.errorf(parseErr.Line, "invalid CSV: %v", parseErr.Err)
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := csvReader.FieldPos(0)
+		rows = append(rows, sheetRow{number: line, cells: record})
+	}
+	return parseSheet(rows), nil
+}
+
+// ParseXLSX reads questions from the first worksheet of an XLSX workbook in
+// the spreadsheet layout, reporting problems like ParseCSV. Line numbers are
+// worksheet row numbers. The returned error is only set when r is not a
+// readable zip archive.
+func ParseXLSX(r io.Reader) (*Result, error) {
+	files, err := openXLSX(r)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readWorksheet(files)
+	if err != nil {
+		result := &Result{}
+		result.errorf(0, "%v", err)
+		return result, nil
+	}
+	return parseSheet(rows), nil
+}
+
+// parseSheet maps the header and question rows of a sheet
+func parseSheet(rows []sheetRow) *Result {
+	result := &Result{}
+
+	// Skip leading blank rows to the header
+	for len(rows) > 0 && blankRow(rows[0].cells) {
+		rows = rows[1:]
+	}
+	if len(rows) == 0 {
+		result.errorf(1, "sheet is empty; expected a header row")
+		return result
+	}
+	header := rows[0]
+	columns, ok := sheetColumns(result, header)
+	if !ok {
+		return result
+	}
+
+	seen := make(map[uuid.UUID]int)
+	for _, row := range rows[1:] {
+		if blankRow(row.cells) {
+			continue
+		}
+		cell := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(row.cells) {
+				return strings.TrimSpace(row.cells[i])
+			}
+			return ""
+		}
+		parseSheetRow(result, row.number, cell, seen)
+	}
+	return result
+}
+
+// sheetColumns maps the known column names to their index in the header
+func sheetColumns(result *Result, header sheetRow) (map[string]int, bool) {
+	columns := make(map[string]int)
+	for i, name := range header.cells {
+		key := columnKey(name)
+		if key == "" {
+			continue
+		}
+		known := false
+		for _, column := range SpreadsheetColumns {
+			if key == columnKey(column) {
+				if _, dup := columns[column]; dup {
+					result.errorf(header.number, "column %q appears more than once", column)
+				}
+				columns[column] = i
+				known = true
+			}
+		}
+		if !known {
+			result.warnf(header.number, "unknown column %q ignored", strings.TrimSpace(name))
+		}
+	}
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			result.errorf(header.number, "header has no %q column", column)
+		}
+	}
+	return columns, result.OK()
+}
+
+// parseSheetRow validates one question row and adds it
+func parseSheetRow(result *Result, line int, cell func(string) string, seen map[uuid.UUID]int) {
+	failed := false
+	fail := func(field, format string, args ...interface{}) {
+		result.Errors = append(result.Errors, Problem{Line: line, Field: field, Message: fmt.Sprintf(format, args...)})
+		failed = true
+	}
+
+	id := uuid.Nil
+	if raw := cell(ColumnID); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		switch {
+		case err != nil:
+			fail(ColumnID, "Must be a question ID")
+		case seen[parsed] > 0:
+			fail(ColumnID, "Same question as line %d", seen[parsed])
+		default:
+			id = parsed
+			seen[id] = line
+		}
+	}
+
+	questionType := models.QuestionType(strings.ReplaceAll(strings.ToLower(cell(ColumnType)), " ", "_"))
+	options := splitOptions(cell(ColumnOptions))
+	if options == nil {
+		options = []string{}
+	}
+	text := cell(ColumnText)
+	correct := cell(ColumnCorrectAnswer)
+	var correctSet []string
+	var numeric *models.NumericAnswer
+	var prompts, matches, order []string
+	var blanks []models.ClozeBlank
+	var accepted []models.AcceptedAnswer
+	switch questionType {
+	case models.QuestionTypeTrueFalse:
+		if len(options) == 0 {
+			options = []string{"true", "false"}
+		}
+		if answer, ok := parseTrueFalse(correct); ok {
+			correct = fmt.Sprint(answer)
+		}
+	case models.QuestionTypeMultipleSelect:
+		correct, correctSet = "", splitOptions(correct)
+	case models.QuestionTypeMatching:
+		var ok bool
+		if prompts, matches, ok = parseSheetPairs(correct); !ok {
+			fail(ColumnCorrectAnswer, "Must pair each prompt with an option as prompt -> option")
+			return
+		}
+		correct = ""
+		if len(options) == 0 {
+			options = uniqueAnswers(matches)
+		}
+	case models.QuestionTypeOrdering:
+		correct, order = "", splitOptions(correct)
+		if len(options) == 0 {
+			options = append([]string{}, order...)
+		}
+	case models.QuestionTypeCloze:
+		var err error
+		if text, blanks, err = parseEmbeddedCloze(text); err != nil {
+			fail(ColumnText, "Invalid %v", err)
+			return
+		}
+	case models.QuestionTypeNumeric:
+		var ok bool
+		if numeric, ok = parseSheetNumeric(correct, options); !ok {
+			fail(ColumnCorrectAnswer, "Must be a number, optionally with a tolerance and unit")
+			return
+		}
+		correct, options = "", []string{}
+	case models.QuestionTypeOpenEnded:
+		accepted, options = parseSheetAccepted(options), []string{}
+	}
+
+	question := models.NewQuestion(uuid.Nil, text, questionType, options, correct, cell(ColumnExplanation))
+	question.ID = id
+	question.CorrectAnswers = correctSet
+	question.Prompts = prompts
+	question.Matches = matches
+	question.CorrectOrder = order
+	question.Numeric = numeric
+	question.Blanks = blanks
+	question.AcceptedAnswers = accepted
+	question.Scoring = models.ScoringMode(strings.ToLower(cell(ColumnScoring)))
+	question.Grading = models.GradingMode(strings.ToLower(cell(ColumnGrading)))
+	for _, problem := range question.Validate() {
+		fail(sheetField(problem.Field), "%s", problem.Error)
+	}
+	if failed {
+		return
+	}
+	result.Questions = append(result.Questions, question)
+}
+
+// sheetField names the column a question field is read from
+func sheetField(field string) string {
+	switch {
+	case strings.HasPrefix(field, "options["), strings.HasPrefix(field, "numeric.units"), strings.HasPrefix(field, "acceptedAnswers"):
+		return ColumnOptions
+	case strings.HasPrefix(field, "blanks"):
+		return ColumnText
+	case field == "correctAnswer", strings.HasPrefix(field, "correctAnswers"), strings.HasPrefix(field, "numeric"),
+		strings.HasPrefix(field, "prompts"), strings.HasPrefix(field, "matches"), strings.HasPrefix(field, "correctOrder"):
+		return ColumnCorrectAnswer
+	default:
+		return field
+	}
+}
+
+// pairSeparator separates a matching prompt from its option
+const pairSeparator = "->"
+
+// parseSheetPairs reads the prompts and matching options of a matching
+// question from its correct_answer cell
+func parseSheetPairs(cell string) ([]string, []string, bool) {
+	var prompts, matches []string
+	for _, pair := range splitOptions(cell) {
+		i := strings.Index(pair, pairSeparator)
+		if i < 0 {
+			return nil, nil, false
+		}
+		prompts = append(prompts, strings.TrimSpace(pair[:i]))
+		matches = append(matches, strings.TrimSpace(pair[i+len(pairSeparator):]))
+	}
+	return prompts, matches, len(prompts) > 0
+}
+
+// sheetPairs is the inverse of parseSheetPairs
+func sheetPairs(prompts, matches []string) string {
+	pairs := make([]string, len(prompts))
+	for i, prompt := range prompts {
+		match := ""
+		if i < len(matches) {
+			match = matches[i]
+		}
+		pairs[i] = prompt + " " + pairSeparator + " " + match
+	}
+	return joinOptions(pairs)
+}
+
+// uniqueAnswers drops repeated answers, keeping the first of each
+func uniqueAnswers(answers []string) []string {
+	seen := make(map[string]bool, len(answers))
+	var unique []string
+	for _, answer := range answers {
+		if !seen[answerKey(answer)] {
+			seen[answerKey(answer)] = true
+			unique = append(unique, answer)
+		}
+	}
+	return unique
+}
+
+// sheetNumber matches a numeric answer cell: a value, an optional tolerance
+// and an optional unit
+var sheetNumber = regexp.MustCompile(`^([-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?)\s*(?:(?:±|\+/-|\+-)\s*((?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?)\s*(%)?)?\s*(.*)$`)
+
+// unitRequired is the options entry marking a numeric answer's unit as required
+const unitRequired = "required"
+
+// parseSheetNumeric reads the answer key of a numeric question from its
+// correct_answer cell and the units listed in its options cell. Unit
+// problems are left to validation.
+func parseSheetNumeric(cell string, options []string) (*models.NumericAnswer, bool) {
+	match := sheetNumber.FindStringSubmatch(cell)
+	if match == nil {
+		return nil, false
+	}
+	answer := &models.NumericAnswer{Unit: strings.TrimSpace(match[4])}
+	answer.Value, _ = strconv.ParseFloat(match[1], 64)
+	if match[2] != "" {
+		answer.Tolerance, _ = strconv.ParseFloat(match[2], 64)
+		if match[3] != "" {
+			answer.Tolerance /= 100
+			answer.ToleranceType = models.ToleranceRelative
+		}
+	}
+
+	for _, option := range options {
+		if strings.EqualFold(option, unitRequired) {
+			answer.UnitRequired = true
+			continue
+		}
+		unit := models.NumericUnit{Unit: option}
+		if i := strings.LastIndex(option, "="); i >= 0 {
+			unit.Unit = strings.TrimSpace(option[:i])
+			unit.Multiplier, _ = strconv.ParseFloat(strings.TrimSpace(option[i+1:]), 64)
+		}
+		answer.Units = append(answer.Units, unit)
+	}
+	return answer, true
+}
+
+// sheetNumeric is the inverse of parseSheetNumeric, returning the
+// correct_answer and options cells
+func sheetNumeric(n *models.NumericAnswer) (string, string) {
+	if n == nil {
+		return "", ""
+	}
+	correct := formatNumber(n.Value)
+	switch {
+	case n.Tolerance == 0:
+	case n.ToleranceType == models.ToleranceRelative:
+		correct += " ± " + formatNumber(n.Tolerance*100) + "%"
+	default:
+		correct += " ± " + formatNumber(n.Tolerance)
+	}
+	if n.Unit != "" {
+		correct += " " + n.Unit
+	}
+
+	var units []string
+	for _, unit := range n.Units {
+		units = append(units, unit.Unit+"="+formatNumber(unit.Multiplier))
+	}
+	if n.UnitRequired {
+		units = append(units, unitRequired)
+	}
+	return correct, joinOptions(units)
+}
+
+// sheetAcceptedRule matches the match rule an accepted answer entry starts
+// with, such as "exact:" or "levenshtein 2:"
+var sheetAcceptedRule = regexp.MustCompile(`^(exact|case_insensitive|whitespace_normalized|regex|levenshtein\s+(\d+))\s*:\s*`)
+
+// parseSheetAccepted reads the accepted answers of an open-ended question
+// from its options cell. Entries without a match rule ignore case.
+func parseSheetAccepted(options []string) []models.AcceptedAnswer {
+	var accepted []models.AcceptedAnswer
+	for _, option := range options {
+		answer := models.AcceptedAnswer{Answer: option, Match: models.MatchCaseInsensitive}
+		if match := sheetAcceptedRule.FindStringSubmatch(option); match != nil {
+			answer.Answer = option[len(match[0]):]
+			answer.Match = models.MatchRule(match[1])
+			if match[2] != "" {
+				answer.Match = models.MatchLevenshtein
+				answer.MaxDistance, _ = strconv.Atoi(match[2])
+			}
+		}
+		accepted = append(accepted, answer)
+	}
+	return accepted
+}
+
+// sheetAccepted is the inverse of parseSheetAccepted
+func sheetAccepted(accepted []models.AcceptedAnswer) string {
+	entries := make([]string, len(accepted))
+	for i, answer := range accepted {
+		switch {
+		case answer.Match == models.MatchLevenshtein:
+			entries[i] = fmt.Sprintf("%s %d: %s", answer.Match, answer.MaxDistance, answer.Answer)
+		case answer.Match != models.MatchCaseInsensitive || sheetAcceptedRule.MatchString(answer.Answer):
+			entries[i] = string(answer.Match) + ": " + answer.Answer
+		default:
+			entries[i] = answer.Answer
+		}
+	}
+	return joinOptions(entries)
+}
+
+// splitOptions splits a cell at unescaped separators. A backslash that
+// does not escape a separator or another backslash is kept as written.
+func splitOptions(cell string) []string {
+	if cell == "" {
+		return nil
+	}
+	var options []string
+	var current strings.Builder
+	runes := []rune(cell)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\' && i+1 < len(runes) && (runes[i+1] == optionSeparator || runes[i+1] == '\\'):
+			current.WriteRune(runes[i+1])
+			i++
+		case r == optionSeparator:
+			options = append(options, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(options, strings.TrimSpace(current.String()))
+}
+
+// joinOptions is the inverse of splitOptions
+func joinOptions(options []string) string {
+	escaped := make([]string, len(options))
+	for i, option := range options {
+		option = strings.ReplaceAll(option, `\`, `\\`)
+		escaped[i] = strings.ReplaceAll(option, string(optionSeparator), `\`+string(optionSeparator))
+	}
+	return strings.Join(escaped, string(optionSeparator))
+}
+
+// columnKey folds a header name for matching
+func columnKey(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "", "_", "").Replace(name)
+}
+
+// blankRow reports whether every cell of a row is empty
+func blankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sheetRows lays a quiz out as a header row and one row per question
+func sheetRows(quiz *models.Quiz) ([][]string, error) {
+	rows := [][]string{SpreadsheetColumns}
+	for _, q := range quiz.Questions {
+		text := q.Text
+		options := joinOptions(q.Options)
+		if q.Type == models.QuestionTypeTrueFalse && trueFalsePair(q.Options) {
+			options = ""
+		}
+		correct := q.CorrectAnswer
+		switch q.Type {
+		case models.QuestionTypeMultipleSelect:
+			correct = joinOptions(q.CorrectAnswers)
+		case models.QuestionTypeNumeric:
+			correct, options = sheetNumeric(q.Numeric)
+		case models.QuestionTypeOpenEnded:
+			options = sheetAccepted(q.AcceptedAnswers)
+		case models.QuestionTypeMatching:
+			correct = sheetPairs(q.Prompts, q.Matches)
+		case models.QuestionTypeOrdering:
+			correct = joinOptions(q.CorrectOrder)
+		case models.QuestionTypeCloze:
+			var err error
+			if text, err = embeddedCloze(q); err != nil {
+				return nil, err
+			}
+		}
+		rows = append(rows, []string{text, string(q.Type), options, correct, q.Explanation, string(q.Scoring), string(q.Grading), q.ID.String()})
+	}
+	return rows, nil
+}
+
+// trueFalsePair reports whether options are the default true/false pair
+func trueFalsePair(options []string) bool {
+	return len(options) == 2 && sameAnswer(options[0], "true") && sameAnswer(options[1], "false")
+}
+
+// WriteCSV writes a quiz's questions as CSV in the spreadsheet layout
+func WriteCSV(w io.Writer, quiz *models.Quiz) error {
+	rows, err := sheetRows(quiz)
+	if err != nil {
+		return err
+	}
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.WriteAll(rows); err != nil {
+		return err
+	}
+	return csvWriter.Error()
+}
+
+// WriteXLSX writes a quiz's questions as an XLSX workbook in the spreadsheet layout
+func WriteXLSX(w io.Writer, quiz *models.Quiz) error {
+	rows, err := sheetRows(quiz)
+	if err != nil {
+		return err
+	}
+	return writeXLSX(w, rows)
+}
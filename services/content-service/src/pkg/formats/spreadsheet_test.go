@@ -0,0 +1,129 @@
+package formats
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+func TestSpreadsheetRoundTrip(t *testing.T) {
+	quiz := models.NewQuiz("Paths", "", uuid.New(), nil)
+	quiz.Questions = []*models.Question{
+		models.NewQuestion(quiz.ID, "Which separator, \"quoted\"?", models.QuestionTypeMultipleChoice, []string{"a|b", `C:\dir`, "=1+1"}, "a|b", "Line one\nline two"),
+		models.NewQuestion(quiz.ID, "Water is wet.", models.QuestionTypeTrueFalse, []string{"true", "false"}, "true", ""),
+		models.NewQuestion(quiz.ID, "Symbol for gold?", models.QuestionTypeOpenEnded, []string{}, "Au", ""),
+		models.NewQuestion(quiz.ID, "Which are primes?", models.QuestionTypeMultipleSelect, []string{"2", "3", "4"}, "", ""),
+		models.NewQuestion(quiz.ID, "Speed of light?", models.QuestionTypeNumeric, []string{}, "", ""),
+		models.NewQuestion(quiz.ID, "Match the capitals", models.QuestionTypeMatching, []string{"Paris", "Rome", "Oslo"}, "", ""),
+		models.NewQuestion(quiz.ID, "Order the planets", models.QuestionTypeOrdering, []string{"Mars", "Venus", "Earth"}, "", ""),
+		models.NewQuestion(quiz.ID, "{{1}} is the {{2}} planet, 100% sure #1.", models.QuestionTypeCloze, []string{}, "", ""),
+		models.NewQuestion(quiz.ID, "Describe a paper path.", models.QuestionTypeOpenEnded, []string{}, "", ""),
+	}
+	quiz.Questions[2].AcceptedAnswers = []models.AcceptedAnswer{
+		{Answer: "Au", Match: models.MatchCaseInsensitive},
+		{Answer: "exact: gold", Match: models.MatchCaseInsensitive},
+		{Answer: `g(o|u)ld \| Au`, Match: models.MatchRegex},
+		{Answer: "aurum", Match: models.MatchLevenshtein, MaxDistance: 2},
+	}
+	quiz.Questions[3].CorrectAnswers = []string{"2", "3"}
+	quiz.Questions[3].Scoring = models.ScoringPartialCredit
+	quiz.Questions[4].Numeric = &models.NumericAnswer{
+		Value:         2.998e8,
+		Tolerance:     0.01,
+		ToleranceType: models.ToleranceRelative,
+		Unit:          "m/s",
+		Units:         []models.NumericUnit{{Unit: "km/s", Multiplier: 1000}},
+		UnitRequired:  true,
+	}
+	quiz.Questions[5].Prompts = []string{"France", "Italy"}
+	quiz.Questions[5].Matches = []string{"Paris", "Rome"}
+	quiz.Questions[5].Scoring = models.ScoringPartialCredit
+	quiz.Questions[6].CorrectOrder = []string{"Venus", "Earth", "Mars"}
+	quiz.Questions[7].Blanks = []models.ClozeBlank{
+		{Kind: models.BlankDropdown, Options: []string{"Mercury", "Venus"}, Answers: []string{"Mercury"}},
+		{Kind: models.BlankText, Answers: []string{"first", "1st", "#1 ~ {nearest}"}},
+	}
+
+	quiz.Questions[8].Grading = models.GradingManual
+
+	tests := []struct {
+		name  string
+		write func(io.Writer, *models.Quiz) error
+		parse func(io.Reader) (*Result, error)
+	}{
+		{"csv", WriteCSV, ParseCSV},
+		{"xlsx", WriteXLSX, ParseXLSX},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, tt.write(&buf, quiz))
+
+			result, err := tt.parse(&buf)
+			require.NoError(t, err)
+			require.Empty(t, result.Errors)
+			require.Len(t, result.Questions, len(quiz.Questions))
+
+			for i, want := range quiz.Questions {
+				got := result.Questions[i]
+				assert.Equal(t, want.ID, got.ID)
+				assert.Equal(t, want.Text, got.Text)
+				assert.Equal(t, want.Type, got.Type)
+				assert.Equal(t, want.Options, got.Options)
+				assert.Equal(t, want.CorrectAnswer, got.CorrectAnswer)
+				assert.Equal(t, want.CorrectAnswers, got.CorrectAnswers)
+				assert.Equal(t, want.Prompts, got.Prompts)
+				assert.Equal(t, want.Matches, got.Matches)
+				assert.Equal(t, want.CorrectOrder, got.CorrectOrder)
+				assert.Equal(t, want.Blanks, got.Blanks)
+				assert.Equal(t, want.AcceptedAnswers, got.AcceptedAnswers)
+				assert.Equal(t, want.Scoring, got.Scoring)
+				assert.Equal(t, want.Grading, got.Grading)
+				assert.Equal(t, want.Numeric, got.Numeric)
+				assert.Equal(t, want.Explanation, got.Explanation)
+			}
+		})
+	}
+}
+
+func TestParseCSVReportsRowErrors(t *testing.T) {
+	source := "\ufeffText,Type,Options,Correct Answer,Explanation,Notes\n" +
+		"Pick one,multiple_choice,red|green,blue,,\n" +
+		"\n" +
+		"Is it?,True False,,yes,,\n" +
+		",open_ended,regex: (x,x,,\n" +
+		"2+2?,multiple_choice,3|4,4,Basic,checked\n"
+
+	result, err := ParseCSV(strings.NewReader(source))
+	require.NoError(t, err)
+
+	require.Len(t, result.Questions, 1)
+	assert.Equal(t, "2+2?", result.Questions[0].Text)
+	assert.Equal(t, uuid.Nil, result.Questions[0].ID)
+
+	assert.Equal(t, []Problem{
+		{Line: 2, Field: "correct_answer", Message: "Must be one of the options"},
+		{Line: 4, Field: "correct_answer", Message: "Must be true or false"},
+		{Line: 5, Field: "text", Message: "This field is required"},
+		{Line: 5, Field: "options", Message: "Invalid regular expression: error parsing regexp: missing closing ): `(x`"},
+	}, result.Errors)
+	assert.Equal(t, []Problem{{Line: 1, Message: `unknown column "Notes" ignored`}}, result.Warnings)
+}
+
+func TestParseCSVRequiresHeader(t *testing.T) {
+	result, err := ParseCSV(strings.NewReader("What?,open_ended\n"))
+	require.NoError(t, err)
+	assert.Empty(t, result.Questions)
+	assert.Equal(t, []Problem{
+		{Line: 1, Message: `header has no "text" column`},
+		{Line: 1, Message: `header has no "type" column`},
+		{Line: 1, Message: `header has no "correct_answer" column`},
+	}, result.Errors)
+}
@@ -0,0 +1,332 @@
+package formats
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Just enough of SpreadsheetML (Office Open XML) to read the first worksheet
+// of a workbook as text and to write a single worksheet of text cells.
+
+const (
+	xlsxMainNamespace  = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	xlsxDocRelNS       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	xlsxWorkbookPath   = "xl/workbook.xml"
+	xlsxSharedStrings  = "xl/sharedStrings.xml"
+	xlsxWorksheetPath  = "xl/worksheets/sheet1.xml"
+	xlsxWorksheetName  = "Questions"
+	xlsxMaxSheetCells  = 1 << 20
+	xlsxContentTypeXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+  <Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+  <Default Extension="xml" ContentType="application/xml"/>
+  <Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+  <Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>
+`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>
+`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>
+`
+)
+
+type xlsxWorkbook struct {
+	XMLName xml.Name `xml:"workbook"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	XmlnsR  string   `xml:"xmlns:r,attr"`
+	Sheets  []struct {
+		Name    string `xml:"name,attr"`
+		SheetID int    `xml:"sheetId,attr"`
+		RelID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStringTable struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText is a shared or inline string: plain text or formatted runs
+type xlsxRichText struct {
+	T    *xlsxText `xml:"t,omitempty"`
+	Runs []struct {
+		T xlsxText `xml:"t"`
+	} `xml:"r"`
+}
+
+type xlsxText struct {
+	Space string `xml:"xml:space,attr,omitempty"`
+	Text  string `xml:",chardata"`
+}
+
+type xlsxWorksheet struct {
+	XMLName xml.Name  `xml:"worksheet"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Rows    []xlsxRow `xml:"sheetData>row"`
+}
+
+type xlsxRow struct {
+	R     int        `xml:"r,attr,omitempty"`
+	Cells []xlsxCell `xml:"c"`
+}
+
+type xlsxCell struct {
+	R      string        `xml:"r,attr,omitempty"`
+	T      string        `xml:"t,attr,omitempty"`
+	V      string        `xml:"v,omitempty"`
+	Inline *xlsxRichText `xml:"is,omitempty"`
+}
+
+// sheetRow is a worksheet row as text, with its 1-based row number
+type sheetRow struct {
+	number int
+	cells  []string
+}
+
+// String returns the text of the string
+func (t xlsxRichText) String() string {
+	var b strings.Builder
+	if t.T != nil {
+		b.WriteString(t.T.Text)
+	}
+	for _, run := range t.Runs {
+		b.WriteString(run.T.Text)
+	}
+	return b.String()
+}
+
+// openXLSX opens a workbook, mapping its parts by name
+func openXLSX(r io.Reader) (map[string]*zip.File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	return files, nil
+}
+
+// readWorksheet reads the first worksheet of a workbook as text
+func readWorksheet(files map[string]*zip.File) ([]sheetRow, error) {
+	sheetPath, err := firstWorksheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files[xlsxSharedStrings]; ok {
+		var table xlsxSharedStringTable
+		if err := decodeXMLFile(f, &table); err != nil {
+			return nil, fmt.Errorf("invalid shared strings: %v", err)
+		}
+		for _, item := range table.Items {
+			shared = append(shared, item.String())
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodeXMLFile(files[sheetPath], &sheet); err != nil {
+		return nil, fmt.Errorf("invalid worksheet: %v", err)
+	}
+
+	rows := make([]sheetRow, 0, len(sheet.Rows))
+	cellCount := 0
+	for i, row := range sheet.Rows {
+		number := row.R
+		if number == 0 {
+			number = i + 1
+		}
+		var cells []string
+		for j, cell := range row.Cells {
+			column := j
+			if cell.R != "" {
+				if column, err = xlsxColumn(cell.R); err != nil {
+					return nil, err
+				}
+			}
+			if cellCount += column + 1; cellCount > xlsxMaxSheetCells {
+				return nil, fmt.Errorf("worksheet is too large")
+			}
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+			if cells[column], err = xlsxCellText(cell, shared); err != nil {
+				return nil, fmt.Errorf("cell %s: %v", cell.R, err)
+			}
+		}
+		rows = append(rows, sheetRow{number: number, cells: cells})
+	}
+	return rows, nil
+}
+
+// firstWorksheet resolves the path of the workbook's first sheet
+func firstWorksheet(files map[string]*zip.File) (string, error) {
+	workbookFile, ok := files[xlsxWorkbookPath]
+	if !ok {
+		return "", fmt.Errorf("not an XLSX workbook: missing %s", xlsxWorkbookPath)
+	}
+	var workbook xlsxWorkbook
+	if err := decodeXMLFile(workbookFile, &workbook); err != nil {
+		return "", fmt.Errorf("invalid workbook: %v", err)
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("workbook has no worksheets")
+	}
+
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return "", fmt.Errorf("workbook has no relationships")
+	}
+	var rels xlsxRelationships
+	if err := decodeXMLFile(relsFile, &rels); err != nil {
+		return "", fmt.Errorf("invalid workbook relationships: %v", err)
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		if _, ok := files[target]; !ok {
+			return "", fmt.Errorf("workbook refers to missing worksheet %s", target)
+		}
+		return target, nil
+	}
+	return "", fmt.Errorf("worksheet %q has no relationship", workbook.Sheets[0].Name)
+}
+
+// xlsxCellText returns the text of a cell
+func xlsxCellText(cell xlsxCell, shared []string) (string, error) {
+	switch cell.T {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(cell.V))
+		if err != nil || i < 0 || i >= len(shared) {
+			return "", fmt.Errorf("invalid shared string %q", cell.V)
+		}
+		return shared[i], nil
+	case "inlineStr":
+		if cell.Inline == nil {
+			return "", nil
+		}
+		return cell.Inline.String(), nil
+	case "b":
+		return strconv.FormatBool(strings.TrimSpace(cell.V) == "1"), nil
+	case "n", "":
+		// Print numbers the short way, without binary rounding noise
+		if f, err := strconv.ParseFloat(strings.TrimSpace(cell.V), 64); err == nil {
+			return formatNumber(f), nil
+		}
+		return cell.V, nil
+	default:
+		return cell.V, nil
+	}
+}
+
+// xlsxColumn returns the 0-based column of a cell reference such as "C12"
+func xlsxColumn(ref string) (int, error) {
+	column := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 || letters > 3 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return column - 1, nil
+}
+
+// xlsxColumnName returns the letters of a 0-based column, e.g. 27 -> "AB"
+func xlsxColumnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name
+}
+
+// writeXLSX writes rows of text as a workbook with a single worksheet
+func writeXLSX(w io.Writer, rows [][]string) error {
+	sheet := xlsxWorksheet{Xmlns: xlsxMainNamespace}
+	for i, row := range rows {
+		sheetRow := xlsxRow{R: i + 1}
+		for j, value := range row {
+			if value == "" {
+				continue
+			}
+			text := &xlsxText{Text: value}
+			if strings.TrimSpace(value) != value || strings.Contains(value, "\n") {
+				text.Space = "preserve"
+			}
+			sheetRow.Cells = append(sheetRow.Cells, xlsxCell{
+				R:      fmt.Sprintf("%s%d", xlsxColumnName(j), i+1),
+				T:      "inlineStr",
+				Inline: &xlsxRichText{T: text},
+			})
+		}
+		sheet.Rows = append(sheet.Rows, sheetRow)
+	}
+
+	workbook := xlsxWorkbook{Xmlns: xlsxMainNamespace, XmlnsR: xlsxDocRelNS}
+	workbook.Sheets = append(workbook.Sheets, struct {
+		Name    string `xml:"name,attr"`
+		SheetID int    `xml:"sheetId,attr"`
+		RelID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	}{Name: xlsxWorksheetName, SheetID: 1, RelID: "rId1"})
+
+	archive := zip.NewWriter(w)
+	for _, part := range []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypeXML},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	if err := writeXMLFile(archive, xlsxWorkbookPath, workbook); err != nil {
+		return err
+	}
+	if err := writeXMLFile(archive, xlsxWorksheetPath, sheet); err != nil {
+		return err
+	}
+	return archive.Close()
+}
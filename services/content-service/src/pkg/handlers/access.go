@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// accessLevel is what a caller may do with a quiz or study set, in increasing order
+type accessLevel int
+
+const (
+	accessNone accessLevel = iota
+	accessView
+	accessEdit
+	accessOwn
+)
+
+// baseAccess works out a caller's access to a resource from its owner and
+// visibility alone. needsGrant reports whether a share grant could raise it.
+func baseAccess(caller middleware.Caller, ownerID uuid.UUID, visibility models.VisibilityType) (level accessLevel, needsGrant bool) {
+	if caller.Trusted || (caller.Authenticated() && caller.UserID == ownerID) {
+		return accessOwn, false
+	}
+
+	if visibility == models.VisibilityPublic {
+		level = accessView
+	}
+	// Grants only apply while the resource is not private
+	return level, caller.Authenticated() && visibility != models.VisibilityPrivate
+}
+
+// grantedAccess maps a share grant's access type to an access level
+func grantedAccess(accessType models.AccessType) accessLevel {
+	if accessType == models.AccessEditor {
+		return accessEdit
+	}
+	return accessView
+}
+
+// denyAccess writes the response for a caller whose access is below what a
+// request needs. Resources the caller cannot see at all are reported as not
+// found so their existence is not leaked.
+func denyAccess(c *gin.Context, level accessLevel, notFound, forbidden string) {
+	switch {
+	case level == accessNone:
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case !middleware.CallerFrom(c).Authenticated():
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+	default:
+		c.JSON(http.StatusForbidden, gin.H{"error": forbidden})
+	}
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/media"
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+	"QuizApp/services/content-service/src/pkg/storage"
+)
+
+// signedURLTTL is how long media links in responses stay valid
+const signedURLTTL = time.Hour
+
+// MediaHandler handles uploads of images and audio for questions
+type MediaHandler struct {
+	repo  repository.MediaRepository
+	store storage.BlobStore
+}
+
+// NewMediaHandler creates a new MediaHandler instance
+func NewMediaHandler(repo repository.MediaRepository, store storage.BlobStore) *MediaHandler {
+	return &MediaHandler{repo: repo, store: store}
+}
+
+// UploadMedia handles POST /api/media. The multipart "file" field holds an
+// image or audio file; the response describes it, with its mediaId to
+// attach to questions and options.
+func (h *MediaHandler) UploadMedia(c *gin.Context) {
+	caller := middleware.CallerFrom(c)
+	if !caller.Authenticated() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	// Leave room for the rest of the multipart form
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, media.MaxUploadSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required"})
+		return
+	}
+
+	upload, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer upload.Close()
+	data, err := io.ReadAll(io.LimitReader(upload, media.MaxUploadSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+
+	file, err := media.Inspect(data, caller.UserID)
+	switch {
+	case errors.Is(err, media.ErrUnsupportedType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "Only PNG, JPEG, GIF and WebP images and MP3, WAV and Ogg audio can be attached",
+		})
+		return
+	case errors.Is(err, media.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": "Images may be at most 5 MB and 40 megapixels, and audio at most 20 MB",
+		})
+		return
+	case err != nil:
+		log.Printf("Failed to process upload: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process file"})
+		return
+	}
+	file.Media.CreatedAt = time.Now().UTC()
+
+	ctx := c.Request.Context()
+	id := file.Media.ID
+	if err := h.store.Put(ctx, media.Key(id), file.Media.ContentType, bytes.NewReader(data), file.Media.Size); err != nil {
+		log.Printf("Failed to store media %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return
+	}
+	if file.Thumbnail != nil {
+		err := h.store.Put(ctx, media.ThumbnailKey(id), "image/jpeg", bytes.NewReader(file.Thumbnail), int64(len(file.Thumbnail)))
+		if err != nil {
+			log.Printf("Failed to store thumbnail of media %s: %v", id, err)
+			h.discard(c, &file.Media)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+			return
+		}
+	}
+
+	if err := h.repo.CreateMedia(ctx, &file.Media); err != nil {
+		log.Printf("Failed to record media %s: %v", id, err)
+		h.discard(c, &file.Media)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return
+	}
+
+	attachment := file.Media.Attach("")
+	signAttachment(h.store, &attachment)
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    attachment,
+	})
+}
+
+// SignMedia handles POST /media/urls for trusted services, which keep copies
+// of questions whose signed links expire. The body lists mediaIds; the
+// response maps each known one to fresh links.
+func (h *MediaHandler) SignMedia(c *gin.Context) {
+	if !middleware.CallerFrom(c).Trusted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only trusted services may sign media links"})
+		return
+	}
+
+	var input struct {
+		MediaIDs []uuid.UUID `json:"mediaIds"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	found, err := h.repo.ListMedia(c.Request.Context(), input.MediaIDs)
+	if err != nil {
+		log.Printf("Failed to load media: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load media"})
+		return
+	}
+
+	signed := make(map[uuid.UUID]models.Attachment, len(found))
+	for id, uploaded := range found {
+		attachment := uploaded.Attach("")
+		signAttachment(h.store, &attachment)
+		signed[id] = attachment
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    signed,
+	})
+}
+
+// discard removes the blobs of an upload that could not be saved
+func (h *MediaHandler) discard(c *gin.Context, m *models.Media) {
+	if err := h.store.Delete(c.Request.Context(), media.Key(m.ID)); err != nil {
+		log.Printf("Failed to remove media %s: %v", m.ID, err)
+	}
+	if m.HasThumbnail {
+		if err := h.store.Delete(c.Request.Context(), media.ThumbnailKey(m.ID)); err != nil {
+			log.Printf("Failed to remove thumbnail of media %s: %v", m.ID, err)
+		}
+	}
+}
+
+// ServeFile handles GET /media/files/*key, serving blobs of the local store
+// to holders of a signed URL. Other stores serve their URLs themselves.
+func (h *MediaHandler) ServeFile(c *gin.Context) {
+	local, ok := h.store.(*storage.LocalStore)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !local.Verify(key, c.Query("expires"), c.Query("signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Link is invalid or has expired"})
+		return
+	}
+
+	file, err := local.Open(key)
+	if err == storage.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	} else if err != nil {
+		log.Printf("Failed to open media file %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		log.Printf("Failed to stat media file %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+
+	// Files are only ever sniffed as the image and audio types accepted on upload
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age=3600")
+	http.ServeContent(c.Writer, c.Request, "", info.ModTime(), file)
+}
+
+// attachMedia fills in the attachments of questions from their uploaded
+// media, writing the error response when some media does not exist. Media
+// IDs are unguessable, so anyone who has one may attach it; editors of
+// shared quizzes can then keep media the owner uploaded.
+func attachMedia(c *gin.Context, mediaRepo repository.MediaRepository, questions []*models.Question) bool {
+	var ids []uuid.UUID
+	for _, question := range questions {
+		for _, attachment := range question.Attachments() {
+			ids = append(ids, attachment.MediaID)
+		}
+	}
+	if len(ids) == 0 {
+		return true
+	}
+
+	found, err := mediaRepo.ListMedia(c.Request.Context(), ids)
+	if err != nil {
+		log.Printf("Failed to load media: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load media"})
+		return false
+	}
+
+	for _, question := range questions {
+		for _, attachment := range question.Attachments() {
+			uploaded, ok := found[attachment.MediaID]
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Media %s does not exist", attachment.MediaID)})
+				return false
+			}
+			*attachment = uploaded.Attach(attachment.AltText)
+		}
+	}
+	return true
+}
+
+// signAttachment fills in the signed URLs of an attachment
+func signAttachment(store storage.BlobStore, attachment *models.Attachment) {
+	url, err := store.SignedURL(media.Key(attachment.MediaID), signedURLTTL)
+	if err != nil {
+		log.Printf("Failed to sign URL for media %s: %v", attachment.MediaID, err)
+		return
+	}
+	attachment.URL = url
+
+	if attachment.HasThumbnail {
+		url, err := store.SignedURL(media.ThumbnailKey(attachment.MediaID), signedURLTTL)
+		if err != nil {
+			log.Printf("Failed to sign thumbnail URL for media %s: %v", attachment.MediaID, err)
+			return
+		}
+		attachment.ThumbnailURL = url
+	}
+}
+
+// signQuestions fills in the signed URLs of every attachment of the questions
+func signQuestions(store storage.BlobStore, questions []*models.Question) {
+	for _, question := range questions {
+		for _, attachment := range question.Attachments() {
+			signAttachment(store, attachment)
+		}
+	}
+}
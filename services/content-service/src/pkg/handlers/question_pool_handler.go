@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+	"QuizApp/services/content-service/src/pkg/storage"
+)
+
+// QuestionPoolHandler handles HTTP requests for question pools and their questions
+type QuestionPoolHandler struct {
+	repo      repository.QuestionPoolRepository
+	mediaRepo repository.MediaRepository
+	store     storage.BlobStore
+}
+
+// NewQuestionPoolHandler creates a new QuestionPoolHandler instance
+func NewQuestionPoolHandler(repo repository.QuestionPoolRepository, mediaRepo repository.MediaRepository, store storage.BlobStore) *QuestionPoolHandler {
+	return &QuestionPoolHandler{repo: repo, mediaRepo: mediaRepo, store: store}
+}
+
+// ListPools handles GET /api/pools, listing the caller's pools and public ones
+func (h *QuestionPoolHandler) ListPools(c *gin.Context) {
+	page := 1
+	pageSize := 10
+
+	// Parse pagination parameters if provided
+	if p := c.Query("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil && val > 0 {
+			page = val
+		}
+	}
+	if ps := c.Query("pageSize"); ps != "" {
+		if val, err := strconv.Atoi(ps); err == nil && val > 0 {
+			pageSize = val
+		}
+	}
+
+	pools, err := h.repo.ListPools(c.Request.Context(), viewerFrom(c), page, pageSize)
+	if err != nil {
+		log.Printf("Error listing question pools: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch question pools"})
+		return
+	}
+	if pools == nil {
+		pools = []*models.QuestionPool{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    pools,
+		"success": true,
+	})
+}
+
+// CreatePool handles POST /api/pools
+func (h *QuestionPoolHandler) CreatePool(c *gin.Context) {
+	caller := middleware.CallerFrom(c)
+	if !caller.Authenticated() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var input struct {
+		Title       string                `json:"title"`
+		Description string                `json:"description"`
+		Visibility  models.VisibilityType `json:"visibility"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if input.Visibility == "" {
+		input.Visibility = models.VisibilityPrivate
+	}
+
+	pool := models.NewQuestionPool(input.Title, input.Description, caller.UserID, input.Visibility)
+	if errs := pool.Validate(); len(errs) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid question pool", "errors": errs})
+		return
+	}
+
+	if err := h.repo.CreatePool(c.Request.Context(), pool); err != nil {
+		log.Printf("Error creating question pool: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create question pool"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    pool,
+		"success": true,
+	})
+}
+
+// GetPool handles GET /api/pools/:id. Owners get the pool's questions with
+// their answer keys; other callers get the taker view of public pools.
+func (h *QuestionPoolHandler) GetPool(c *gin.Context) {
+	view, ok := requestedView(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view"})
+		return
+	}
+
+	pool, access, ok := h.authorize(c, accessView)
+	if !ok {
+		return
+	}
+	if view == models.QuizViewAuthor && access < accessOwn {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to view answer keys for this question pool"})
+		return
+	}
+
+	questions, err := h.repo.ListPoolQuestions(c.Request.Context(), []uuid.UUID{pool.ID})
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch question pool questions")
+		return
+	}
+	pool.Questions = questions[pool.ID]
+	if view == models.QuizViewTaker {
+		for i, question := range pool.Questions {
+			pool.Questions[i] = question.ForTaker()
+		}
+	}
+	signQuestions(h.store, pool.Questions)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    pool,
+		"success": true,
+	})
+}
+
+// UpdatePool handles PATCH /api/pools/:id
+func (h *QuestionPoolHandler) UpdatePool(c *gin.Context) {
+	var input struct {
+		Title       *string                `json:"title"`
+		Description *string                `json:"description"`
+		Visibility  *models.VisibilityType `json:"visibility"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	pool, _, ok := h.authorize(c, accessOwn)
+	if !ok {
+		return
+	}
+
+	if input.Title != nil {
+		pool.Title = *input.Title
+	}
+	if input.Description != nil {
+		pool.Description = *input.Description
+	}
+	if input.Visibility != nil {
+		pool.Visibility = *input.Visibility
+	}
+	if errs := pool.Validate(); len(errs) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid question pool", "errors": errs})
+		return
+	}
+
+	if err := h.repo.UpdatePool(c.Request.Context(), pool); err != nil {
+		h.writeError(c, err, "Failed to update question pool")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    pool,
+		"success": true,
+	})
+}
+
+// DeletePool handles DELETE /api/pools/:id. Pools that quizzes draw from
+// cannot be deleted until the quizzes stop drawing from them.
+func (h *QuestionPoolHandler) DeletePool(c *gin.Context) {
+	pool, _, ok := h.authorize(c, accessOwn)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeletePool(c.Request.Context(), pool.ID); err != nil {
+		h.writeError(c, err, "Failed to delete question pool")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddPoolQuestion handles POST /api/pools/:id/questions, appending a question to the pool
+func (h *QuestionPoolHandler) AddPoolQuestion(c *gin.Context) {
+	pool, _, ok := h.authorize(c, accessOwn)
+	if !ok {
+		return
+	}
+
+	var question models.Question
+	if err := c.ShouldBindJSON(&question); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	question.ID = uuid.New()
+	question.QuizID = uuid.Nil
+	question.PoolID = &pool.ID
+	if !h.checkQuestion(c, &question) {
+		return
+	}
+
+	if err := h.repo.AddPoolQuestion(c.Request.Context(), &question); err != nil {
+		h.writeError(c, err, "Failed to add question")
+		return
+	}
+
+	signQuestions(h.store, []*models.Question{&question})
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    question,
+		"success": true,
+	})
+}
+
+// UpdatePoolQuestion handles PUT /api/pools/:id/questions/:questionId,
+// replacing the question's content while keeping its ID and position
+func (h *QuestionPoolHandler) UpdatePoolQuestion(c *gin.Context) {
+	pool, _, ok := h.authorize(c, accessOwn)
+	if !ok {
+		return
+	}
+	questionId, err := uuid.Parse(c.Param("questionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	var question models.Question
+	if err := c.ShouldBindJSON(&question); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	question.ID = questionId
+	question.QuizID = uuid.Nil
+	question.PoolID = &pool.ID
+	if !h.checkQuestion(c, &question) {
+		return
+	}
+
+	if err := h.repo.UpdatePoolQuestion(c.Request.Context(), &question); err != nil {
+		h.writeError(c, err, "Failed to update question")
+		return
+	}
+
+	signQuestions(h.store, []*models.Question{&question})
+	c.JSON(http.StatusOK, gin.H{
+		"data":    question,
+		"success": true,
+	})
+}
+
+// DeletePoolQuestion handles DELETE /api/pools/:id/questions/:questionId
+func (h *QuestionPoolHandler) DeletePoolQuestion(c *gin.Context) {
+	pool, _, ok := h.authorize(c, accessOwn)
+	if !ok {
+		return
+	}
+	questionId, err := uuid.Parse(c.Param("questionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	if err := h.repo.DeletePoolQuestion(c.Request.Context(), pool.ID, questionId); err != nil {
+		h.writeError(c, err, "Failed to delete question")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// checkQuestion validates a pool question and resolves its media, writing
+// the error response when either fails. Pool questions are only ever seen
+// through quizzes drawing them, so like quiz questions they must be valid.
+func (h *QuestionPoolHandler) checkQuestion(c *gin.Context, question *models.Question) bool {
+	if errs := question.Validate(); len(errs) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid question", "errors": errs})
+		return false
+	}
+	return attachMedia(c, h.mediaRepo, []*models.Question{question})
+}
+
+// authorize loads the pool named by the :id parameter and checks that the
+// caller has at least the required access, writing the error response when
+// they do not. Pools are not shared: their owner may change them, and
+// anyone may read and draw from public ones.
+func (h *QuestionPoolHandler) authorize(c *gin.Context, required accessLevel) (*models.QuestionPool, accessLevel, bool) {
+	poolId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question pool ID"})
+		return nil, accessNone, false
+	}
+
+	pool, err := h.repo.GetPool(c.Request.Context(), poolId)
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch question pool")
+		return nil, accessNone, false
+	}
+
+	access, _ := baseAccess(middleware.CallerFrom(c), pool.OwnerID, pool.Visibility)
+	if access < required {
+		denyAccess(c, access, "Question pool not found", "Not allowed to modify this question pool")
+		return nil, access, false
+	}
+	return pool, access, true
+}
+
+// writeError maps repository errors to HTTP responses
+func (h *QuestionPoolHandler) writeError(c *gin.Context, err error, message string) {
+	switch err {
+	case repository.ErrPoolNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question pool not found"})
+	case repository.ErrQuestionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+	case repository.ErrPoolInUse:
+		c.JSON(http.StatusConflict, gin.H{"error": "Quizzes still draw questions from this pool"})
+	default:
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+)
+
+// fakeLibraryRepo holds several quizzes and their share grants. Listings are
+// filtered by the rule quizVisibleTo applies in SQL.
+type fakeLibraryRepo struct {
+	repository.ContentRepository
+	quizzes []*models.Quiz
+	grants  []*models.QuizAccess
+	saved   bool
+}
+
+func (r *fakeLibraryRepo) GetQuiz(ctx context.Context, id uuid.UUID) (*models.Quiz, error) {
+	for _, quiz := range r.quizzes {
+		if quiz.ID == id {
+			copied := *quiz
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrQuizNotFound
+}
+
+func (r *fakeLibraryRepo) GetQuizAccess(ctx context.Context, quizID, userID uuid.UUID) (*models.QuizAccess, error) {
+	for _, grant := range r.grants {
+		if grant.QuizID == quizID && grant.UserID == userID {
+			return grant, nil
+		}
+	}
+	return nil, repository.ErrSharedAccessNotFound
+}
+
+func (r *fakeLibraryRepo) UpdateQuiz(ctx context.Context, quiz *models.Quiz) error {
+	r.saved = true
+	quiz.Version++
+	return nil
+}
+
+func (r *fakeLibraryRepo) visible(viewer repository.Viewer) []*models.Quiz {
+	var quizzes []*models.Quiz
+	for _, quiz := range r.quizzes {
+		_, err := r.GetQuizAccess(context.Background(), quiz.ID, viewer.UserID)
+		shared := quiz.Visibility == models.VisibilityShared && err == nil
+		if viewer.Trusted || quiz.Visibility == models.VisibilityPublic || quiz.CreatorID == viewer.UserID || shared {
+			copied := *quiz
+			quizzes = append(quizzes, &copied)
+		}
+	}
+	return quizzes
+}
+
+func (r *fakeLibraryRepo) ListQuizzes(ctx context.Context, viewer repository.Viewer, page repository.QuizPage) ([]*models.Quiz, int, *repository.QuizCursor, error) {
+	quizzes := r.visible(viewer)
+	return quizzes, len(quizzes), nil, nil
+}
+
+func (r *fakeLibraryRepo) ListUserQuizzes(ctx context.Context, viewer repository.Viewer, userID uuid.UUID, page repository.QuizPage) ([]*models.Quiz, int, *repository.QuizCursor, error) {
+	var quizzes []*models.Quiz
+	for _, quiz := range r.visible(viewer) {
+		if quiz.CreatorID == userID {
+			quizzes = append(quizzes, quiz)
+		}
+	}
+	return quizzes, len(quizzes), nil, nil
+}
+
+func (r *fakeLibraryRepo) SearchQuizzes(ctx context.Context, viewer repository.Viewer, search repository.QuizSearch, page repository.QuizPage) ([]*models.QuizSearchHit, int, *repository.QuizCursor, error) {
+	var hits []*models.QuizSearchHit
+	for _, quiz := range r.visible(viewer) {
+		hits = append(hits, &models.QuizSearchHit{Quiz: quiz})
+	}
+	return hits, len(hits), nil, nil
+}
+
+// library is a private, a shared and a public quiz by owner. viewer and
+// editor hold grants of their kind on the private and the shared quiz.
+type library struct {
+	repo                    *fakeLibraryRepo
+	owner, viewer, editor   uuid.UUID
+	private, shared, public *models.Quiz
+	router                  *gin.Engine
+}
+
+func newLibrary() *library {
+	l := &library{owner: uuid.New(), viewer: uuid.New(), editor: uuid.New()}
+	l.private, l.shared, l.public = testQuiz(l.owner), testQuiz(l.owner), testQuiz(l.owner)
+	l.private.Title, l.shared.Title, l.public.Title = "private", "shared", "public"
+	l.shared.Visibility, l.public.Visibility = models.VisibilityShared, models.VisibilityPublic
+
+	l.repo = &fakeLibraryRepo{quizzes: []*models.Quiz{l.private, l.shared, l.public}}
+	for _, quiz := range []*models.Quiz{l.private, l.shared} {
+		l.repo.grants = append(l.repo.grants,
+			&models.QuizAccess{QuizID: quiz.ID, UserID: l.viewer, AccessType: models.AccessViewer},
+			&models.QuizAccess{QuizID: quiz.ID, UserID: l.editor, AccessType: models.AccessEditor})
+	}
+
+	gin.SetMode(gin.TestMode)
+	h := NewQuizHandler(l.repo, nil, nil, nil)
+	l.router = gin.New()
+	l.router.Use(middleware.Identity())
+	l.router.GET("/quizzes", h.ListQuizzes)
+	l.router.GET("/search", h.SearchQuizzes)
+	l.router.GET("/users/:id/quizzes", h.ListUserQuizzes)
+	l.router.GET("/quizzes/:id", h.GetQuiz)
+	l.router.PATCH("/quizzes/:id", h.UpdateQuiz)
+	return l
+}
+
+// listedTitles returns the sorted titles of the quizzes in a listing response
+func listedTitles(response map[string]interface{}) []string {
+	titles := []string{}
+	items, _ := response["data"].([]interface{})
+	for _, item := range items {
+		quiz := item.(map[string]interface{})
+		if hit, ok := quiz["quiz"].(map[string]interface{}); ok {
+			quiz = hit
+		}
+		titles = append(titles, quiz["title"].(string))
+	}
+	sort.Strings(titles)
+	return titles
+}
+
+func TestQuizReadsHideQuizzesFromOtherUsers(t *testing.T) {
+	l := newLibrary()
+
+	tests := []struct {
+		name    string
+		user    uuid.UUID
+		visible []string
+	}{
+		{name: "owner", user: l.owner, visible: []string{"private", "public", "shared"}},
+		// Grants open shared quizzes only; private ones stay with the owner
+		{name: "shared viewer", user: l.viewer, visible: []string{"public", "shared"}},
+		{name: "shared editor", user: l.editor, visible: []string{"public", "shared"}},
+		{name: "other user", user: uuid.New(), visible: []string{"public"}},
+		{name: "anonymous", user: uuid.Nil, visible: []string{"public"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, path := range []string{"/quizzes", "/search?q=capitals", "/users/" + l.owner.String() + "/quizzes"} {
+				code, response := send(t, l.router, tt.user, http.MethodGet, path, "", nil)
+				require.Equal(t, http.StatusOK, code, path)
+				assert.Equal(t, tt.visible, listedTitles(response), path)
+			}
+
+			for _, quiz := range l.repo.quizzes {
+				want := http.StatusNotFound
+				for _, title := range tt.visible {
+					if title == quiz.Title {
+						want = http.StatusOK
+					}
+				}
+				code, _ := send(t, l.router, tt.user, http.MethodGet, "/quizzes/"+quiz.ID.String(), "", nil)
+				assert.Equal(t, want, code, quiz.Title)
+			}
+		})
+	}
+}
+
+func TestQuizWritesNeedEditAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       func(l *library) uuid.UUID
+		title      int
+		visibility int
+	}{
+		{name: "owner", user: func(l *library) uuid.UUID { return l.owner }, title: http.StatusOK, visibility: http.StatusOK},
+		// Editors change content, but only the owner decides who can see it
+		{name: "shared editor", user: func(l *library) uuid.UUID { return l.editor }, title: http.StatusOK, visibility: http.StatusForbidden},
+		{name: "shared viewer", user: func(l *library) uuid.UUID { return l.viewer }, title: http.StatusForbidden, visibility: http.StatusForbidden},
+		{name: "other user", user: func(*library) uuid.UUID { return uuid.New() }, title: http.StatusNotFound, visibility: http.StatusNotFound},
+		{name: "anonymous", user: func(*library) uuid.UUID { return uuid.Nil }, title: http.StatusNotFound, visibility: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for body, want := range map[string]int{
+				`{"version": 3, "title": "Renamed"}`:     tt.title,
+				`{"version": 3, "visibility": "public"}`: tt.visibility,
+			} {
+				l := newLibrary()
+				code, _ := send(t, l.router, tt.user(l), http.MethodPatch, "/quizzes/"+l.shared.ID.String(), body, nil)
+				assert.Equal(t, want, code, body)
+				assert.Equal(t, want == http.StatusOK, l.repo.saved, body)
+			}
+		})
+	}
+}
+
+func TestAuthorViewNeedsEditAccess(t *testing.T) {
+	l := newLibrary()
+
+	tests := []struct {
+		name string
+		user uuid.UUID
+		want int
+	}{
+		{name: "owner", user: l.owner, want: http.StatusOK},
+		{name: "shared editor", user: l.editor, want: http.StatusOK},
+		{name: "shared viewer", user: l.viewer, want: http.StatusForbidden},
+		{name: "other user", user: uuid.New(), want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, response := send(t, l.router, tt.user, http.MethodGet, "/quizzes/"+l.shared.ID.String()+"?view=author", "", nil)
+			require.Equal(t, tt.want, code)
+			if code != http.StatusOK {
+				assert.NotContains(t, response, "data")
+				return
+			}
+			question := response["data"].(map[string]interface{})["questions"].([]interface{})[0].(map[string]interface{})
+			assert.Equal(t, "Paris", question["correctAnswer"])
+		})
+	}
+
+	// Viewers still get the quiz, just without its answer keys
+	code, response := send(t, l.router, l.viewer, http.MethodGet, "/quizzes/"+l.shared.ID.String(), "", nil)
+	require.Equal(t, http.StatusOK, code)
+	question := response["data"].(map[string]interface{})["questions"].([]interface{})[0].(map[string]interface{})
+	assert.NotContains(t, question, "correctAnswer")
+}
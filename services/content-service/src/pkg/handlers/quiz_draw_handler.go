@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+)
+
+// DrawQuestions handles GET /api/quizzes/:id/draw?seed=N, drawing questions
+// from the quiz's pools as its draw rules ask. The same seed draws the same
+// questions as long as the pools are unchanged, so the study service can
+// give every attempt its own selection. Drawn questions carry their answer
+// keys, so drawing is limited to authors and trusted services. Pools are
+// checked when a quiz's draw rules are saved, not again here.
+func (h *QuizHandler) DrawQuestions(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	seed, err := strconv.ParseInt(c.Query("seed"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seed"})
+		return
+	}
+
+	quiz, _, ok := h.authorizeQuiz(c, quizId, accessEdit)
+	if !ok {
+		return
+	}
+
+	questions, err := h.drawQuestions(c, quiz.Draws, seed)
+	if errors.Is(err, models.ErrNotEnoughQuestions) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		log.Printf("Failed to draw questions for quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to draw questions"})
+		return
+	}
+	if questions == nil {
+		questions = []*models.Question{}
+	}
+
+	signQuestions(h.store, questions)
+	c.JSON(http.StatusOK, gin.H{
+		"data":    questions,
+		"success": true,
+	})
+}
+
+// drawQuestions loads the pools that draws use and draws from them
+func (h *QuizHandler) drawQuestions(c *gin.Context, draws []models.PoolDraw, seed int64) ([]*models.Question, error) {
+	if len(draws) == 0 {
+		return nil, nil
+	}
+	poolIDs := make([]uuid.UUID, len(draws))
+	for i, draw := range draws {
+		poolIDs[i] = draw.PoolID
+	}
+
+	pools, err := h.poolRepo.ListPoolQuestions(c.Request.Context(), poolIDs)
+	if err != nil {
+		return nil, err
+	}
+	return models.DrawQuestions(draws, pools, seed)
+}
+
+// checkDraws validates a quiz's draw rules, writing the error response when
+// they are invalid. Every pool must exist and be visible to the caller, and
+// must currently hold enough questions to draw from.
+func (h *QuizHandler) checkDraws(c *gin.Context, draws []models.PoolDraw) bool {
+	if errs := models.ValidateDraws(draws); len(errs) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid draw rules", "errors": errs})
+		return false
+	}
+
+	caller := middleware.CallerFrom(c)
+	checked := make(map[uuid.UUID]bool, len(draws))
+	for _, draw := range draws {
+		if checked[draw.PoolID] {
+			continue
+		}
+		checked[draw.PoolID] = true
+
+		pool, err := h.poolRepo.GetPool(c.Request.Context(), draw.PoolID)
+		if err != nil && err != repository.ErrPoolNotFound {
+			log.Printf("Failed to fetch question pool %s: %v", draw.PoolID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch question pool"})
+			return false
+		}
+		if err == nil {
+			if access, _ := baseAccess(caller, pool.OwnerID, pool.Visibility); access >= accessView {
+				continue
+			}
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Question pool %s does not exist", draw.PoolID)})
+		return false
+	}
+
+	if _, err := h.drawQuestions(c, draws, 0); errors.Is(err, models.ErrNotEnoughQuestions) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return false
+	} else if err != nil {
+		log.Printf("Failed to check draw rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch question pool"})
+		return false
+	}
+	return true
+}
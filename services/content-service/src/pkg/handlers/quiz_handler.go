@@ -1,25 +1,34 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"QuizApp/services/content-service/src/pkg/middleware"
 	"QuizApp/services/content-service/src/pkg/models"
 	"QuizApp/services/content-service/src/pkg/repository"
+	"QuizApp/services/content-service/src/pkg/storage"
 )
 
 // QuizHandler handles HTTP requests for quiz operations
 type QuizHandler struct {
-	repo repository.ContentRepository
+	repo      repository.ContentRepository
+	mediaRepo repository.MediaRepository
+	poolRepo  repository.QuestionPoolRepository
+	store     storage.BlobStore
 }
 
 // NewQuizHandler creates a new QuizHandler instance
-func NewQuizHandler(repo repository.ContentRepository) *QuizHandler {
-	return &QuizHandler{repo: repo}
+func NewQuizHandler(repo repository.ContentRepository, mediaRepo repository.MediaRepository, poolRepo repository.QuestionPoolRepository, store storage.BlobStore) *QuizHandler {
+	return &QuizHandler{repo: repo, mediaRepo: mediaRepo, poolRepo: poolRepo, store: store}
 }
 
 // GetQuiz handles GET /api/quizzes/:id
@@ -30,7 +39,37 @@ func (h *QuizHandler) GetQuiz(c *gin.Context) {
 		return
 	}
 
+	view, ok := requestedView(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view"})
+		return
+	}
+
 	log.Printf("Fetching quiz with ID: %s", quizId)
+	quiz, access, ok := h.authorizeQuiz(c, quizId, accessView)
+	if !ok {
+		return
+	}
+
+	if view == models.QuizViewAuthor && access < accessEdit {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to view answer keys for this quiz"})
+		return
+	}
+
+	log.Printf("Returning quiz with %d questions (%s view)", len(quiz.Questions), view)
+	projected := project(quiz, view)
+	signQuestions(h.store, projected.Questions)
+	c.Header("ETag", quizETag(quiz.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data":    projected,
+		"success": true,
+	})
+}
+
+// respondWithEditedQuiz reloads a quiz after a successful edit and returns it
+// in the author view, with the new version as its ETag. Only callers with
+// edit access get here, so answer keys may be included.
+func (h *QuizHandler) respondWithEditedQuiz(c *gin.Context, quizId uuid.UUID) {
 	quiz, err := h.repo.GetQuiz(c.Request.Context(), quizId)
 	if err == repository.ErrQuizNotFound {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
@@ -41,37 +80,31 @@ func (h *QuizHandler) GetQuiz(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Fetching questions for quiz: %s", quizId)
-	questions, err := h.repo.ListQuizQuestions(c.Request.Context(), quizId)
-	if err != nil {
-		log.Printf("Error fetching questions: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz questions"})
-		return
-	}
-
-	// Include questions in the quiz object
-	quiz.Questions = questions
-	log.Printf("Returning quiz with %d questions", len(questions))
-
-	response := gin.H{
+	signQuestions(h.store, quiz.Questions)
+	c.Header("ETag", quizETag(quiz.Version))
+	c.JSON(http.StatusOK, gin.H{
 		"data":    quiz,
 		"success": true,
-	}
-	log.Printf("Response data: %+v", response)
-	c.JSON(http.StatusOK, response)
+	})
+}
+
+// quizInput is the body of CreateQuiz and ValidateQuiz
+type quizInput struct {
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	TopicID     *uuid.UUID            `json:"topicId,omitempty"`
+	Visibility  models.VisibilityType `json:"visibility"`
+	Questions   []models.Question     `json:"questions"`
+	Draws       []models.PoolDraw     `json:"draws"`
+	Settings    models.QuizSettings   `json:"settings"`
 }
 
-// CreateQuiz handles POST /api/quizzes
+// CreateQuiz handles POST /api/quizzes. The quiz and its questions must be
+// valid; see ValidateQuiz.
 func (h *QuizHandler) CreateQuiz(c *gin.Context) {
 	log.Printf("Received quiz creation request")
 	
-	var input struct {
-		Title       string           `json:"title"`
-		Description string           `json:"description"`
-		TopicID     *uuid.UUID      `json:"topicId,omitempty"`
-		Questions   []models.Question `json:"questions"`
-	}
-
+	var input quizInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		log.Printf("Failed to bind JSON: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
@@ -82,58 +115,124 @@ func (h *QuizHandler) CreateQuiz(c *gin.Context) {
 	if input.TopicID != nil {
 		log.Printf("TopicID: %s", *input.TopicID)
 	} else {
-		log.Printf("No topic ID provided, using default topic")
+		log.Printf("No topic ID provided, quiz is uncategorized")
 	}
 	log.Printf("Questions: %+v", input.Questions)
 
-	// Use a default user ID for now
-	defaultUserID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
-	defaultTopicID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	quiz, ok := newCallerQuiz(c, input.Title, input.Description, input.TopicID, input.Visibility)
+	if !ok {
+		return
+	}
 
-	quiz := &models.Quiz{
-		ID:          uuid.New(),
-		Title:       input.Title,
-		Description: input.Description,
-		CreatorID:   defaultUserID,
-		TopicID:     &defaultTopicID,
+	for i, q := range input.Questions {
+		question := q // Create a new variable to avoid using the loop variable address
+		question.ID = uuid.New()
+		question.QuizID = quiz.ID
+		log.Printf("Adding question %d with ID: %s", i+1, question.ID)
+		quiz.Questions = append(quiz.Questions, &question)
 	}
-	
-	if input.TopicID != nil {
-		quiz.TopicID = input.TopicID
+	quiz.Draws = input.Draws
+	quiz.Settings = input.Settings
+	if !checkQuiz(c, quiz) {
+		return
+	}
+	if !attachMedia(c, h.mediaRepo, quiz.Questions) {
+		return
+	}
+	if !h.checkDraws(c, input.Draws) {
+		return
 	}
 
+	// The quiz and its questions are written in one transaction
 	log.Printf("Saving quiz to database with ID: %s", quiz.ID)
 	if err := h.repo.CreateQuiz(c.Request.Context(), quiz); err != nil {
+		if err == repository.ErrTopicNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Topic does not exist"})
+			return
+		}
 		log.Printf("Failed to create quiz: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quiz"})
 		return
 	}
 
-	// Create questions
-	var questions []*models.Question
-	for i, q := range input.Questions {
-		question := q // Create a new variable to avoid using the loop variable address
-		question.ID = uuid.New()
-		question.QuizID = quiz.ID
-		log.Printf("Creating question %d with ID: %s", i+1, question.ID)
-		if err := h.repo.AddQuestion(c.Request.Context(), &question); err != nil {
-			log.Printf("Failed to create question: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quiz questions"})
-			return
-		}
-		questions = append(questions, &question)
+	signQuestions(h.store, quiz.Questions)
+	c.Header("ETag", quizETag(quiz.Version))
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data": quiz,
+	})
+}
+
+// ValidateQuiz handles POST /api/quizzes/validate, checking a quiz as
+// CreateQuiz would without saving it. Problems are listed field by field in
+// the response rather than failing the request. Media and question pools
+// are only looked up when the quiz is saved.
+func (h *QuizHandler) ValidateQuiz(c *gin.Context) {
+	var input quizInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
 	}
 
-	// Add questions to the quiz object for the response
-	quiz.Questions = questions
+	quiz := &models.Quiz{
+		Title:       input.Title,
+		Description: input.Description,
+		TopicID:     input.TopicID,
+		Visibility:  input.Visibility,
+		Draws:       input.Draws,
+		Settings:    input.Settings,
+	}
+	for i := range input.Questions {
+		quiz.Questions = append(quiz.Questions, &input.Questions[i])
+	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	errs := quiz.Validate()
+	if errs == nil {
+		errs = []models.ValidationError{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"valid":  len(errs) == 0,
+			"errors": errs,
+		},
 		"success": true,
-		"data": quiz,
 	})
 }
 
-// UpdateQuiz handles PATCH /api/quizzes/:id
+// newCallerQuiz builds an unsaved quiz owned by the caller, writing the error
+// response when the caller is anonymous or the requested visibility is not
+// allowed. Quizzes always have an owner to manage them, so creating one
+// needs a user.
+func newCallerQuiz(c *gin.Context, title, description string, topicID *uuid.UUID, requested models.VisibilityType) (*models.Quiz, bool) {
+	caller := middleware.CallerFrom(c)
+	if !caller.Authenticated() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return nil, false
+	}
+
+	visibility := models.VisibilityPrivate
+	if requested != "" {
+		if !requested.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid visibility"})
+			return nil, false
+		}
+		visibility = requested
+	}
+
+	return &models.Quiz{
+		ID:          uuid.New(),
+		Title:       title,
+		Description: description,
+		CreatorID:   caller.UserID,
+		Visibility:  visibility,
+		TopicID:     topicID,
+	}, true
+}
+
+// UpdateQuiz handles PATCH /api/quizzes/:id.
+// The client must send the version it last read, either as an If-Match
+// header carrying the quiz ETag or as "version" in the body. The updated
+// quiz must be valid; see ValidateQuiz.
 func (h *QuizHandler) UpdateQuiz(c *gin.Context) {
 	quizId, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -144,7 +243,12 @@ func (h *QuizHandler) UpdateQuiz(c *gin.Context) {
 	var input struct {
 		Title       *string           `json:"title"`
 		Description *string           `json:"description"`
+		TopicID     *string           `json:"topicId"` // "" clears the topic
+		Visibility  *models.VisibilityType `json:"visibility"`
+		Version     *int              `json:"version"`
 		Questions   []models.Question `json:"questions"`
+		Draws       *[]models.PoolDraw `json:"draws"` // [] stops drawing from pools
+		Settings    *models.QuizSettings `json:"settings"` // replaces all settings
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -152,14 +256,25 @@ func (h *QuizHandler) UpdateQuiz(c *gin.Context) {
 		return
 	}
 
-	quiz, err := h.repo.GetQuiz(c.Request.Context(), quizId)
-	if err == repository.ErrQuizNotFound {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+	// Editors may change content; only the owner decides who can see it
+	required := accessEdit
+	if input.Visibility != nil {
+		required = accessOwn
+	}
+	quiz, _, ok := h.authorizeQuiz(c, quizId, required)
+	if !ok {
+		return
+	}
+
+	version, err := expectedVersion(c, input.Version, quiz.Version)
+	if err == errVersionRequired {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header or version is required"})
 		return
 	} else if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header or version"})
 		return
 	}
+	quiz.Version = version
 
 	// Only update fields that were provided
 	if input.Title != nil {
@@ -168,40 +283,218 @@ func (h *QuizHandler) UpdateQuiz(c *gin.Context) {
 	if input.Description != nil {
 		quiz.Description = *input.Description
 	}
+	if input.TopicID != nil {
+		quiz.TopicID = nil
+		if *input.TopicID != "" {
+			topicId, err := uuid.Parse(*input.TopicID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+				return
+			}
+			quiz.TopicID = &topicId
+		}
+	}
+	if input.Visibility != nil {
+		if !input.Visibility.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid visibility"})
+			return
+		}
+		quiz.Visibility = *input.Visibility
+	}
+	if input.Draws != nil {
+		if !h.checkDraws(c, *input.Draws) {
+			return
+		}
+		quiz.Draws = *input.Draws
+	}
+	if input.Settings != nil {
+		quiz.Settings = *input.Settings
+	}
 
-	if err := h.repo.UpdateQuiz(c.Request.Context(), quiz); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quiz"})
+	// If questions were provided, replace the question list atomically.
+	// Questions keep their IDs; omit the ID to add a new question.
+	if input.Questions != nil {
+		quiz.Questions = make([]*models.Question, 0, len(input.Questions))
+		for _, q := range input.Questions {
+			question := q
+			quiz.Questions = append(quiz.Questions, &question)
+		}
+	}
+	if !checkQuiz(c, quiz) {
 		return
 	}
 
-	// If questions were provided, replace all existing questions
 	if input.Questions != nil {
-		// Delete existing questions one by one
-		questions, err := h.repo.ListQuizQuestions(c.Request.Context(), quizId)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch existing questions"})
+		if !attachMedia(c, h.mediaRepo, quiz.Questions) {
 			return
 		}
-		for _, q := range questions {
-			if err := h.repo.DeleteQuestion(c.Request.Context(), q.ID); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete existing questions"})
-				return
-			}
-		}
+		err = h.repo.UpdateQuizWithQuestions(c.Request.Context(), quiz)
+	} else {
+		err = h.repo.UpdateQuiz(c.Request.Context(), quiz)
+	}
 
-		// Add new questions
-		for _, q := range input.Questions {
-			q.ID = uuid.New()
-			q.QuizID = quiz.ID
-			if err := h.repo.AddQuestion(c.Request.Context(), &q); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quiz questions"})
-				return
-			}
-		}
+	switch err {
+	case nil:
+	case repository.ErrQuizNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	case repository.ErrInvalidInput:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Questions must be new or already belong to this quiz"})
+		return
+	case repository.ErrTopicNotFound:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Topic does not exist"})
+		return
+	case repository.ErrVersionConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "Quiz was modified by someone else; reload and try again"})
+		return
+	default:
+		log.Printf("Failed to update quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quiz"})
+		return
 	}
 
 	// Return the updated quiz
-	h.GetQuiz(c)
+	h.respondWithEditedQuiz(c, quizId)
+}
+
+// AddQuestion handles POST /api/quizzes/:id/questions.
+// An optional 1-based "position" inserts the question at that place. The
+// question and the quiz it leaves behind must be valid, and like UpdateQuiz
+// the request must name the quiz version it was based on.
+func (h *QuizHandler) AddQuestion(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	quiz, _, ok := h.authorizeQuiz(c, quizId, accessEdit)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		models.Question
+		Version *int `json:"version"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	version, err := expectedVersion(c, input.Version, quiz.Version)
+	if err == errVersionRequired {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header or version is required"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header or version"})
+		return
+	}
+
+	question := input.Question
+	question.ID = uuid.New()
+	question.QuizID = quizId
+	if errs := question.Validate(); len(errs) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid question", "errors": errs})
+		return
+	}
+	edited := *quiz
+	edited.Questions = withQuestionAt(quiz.Questions, &question)
+	if !checkQuiz(c, &edited) {
+		return
+	}
+	if !attachMedia(c, h.mediaRepo, []*models.Question{&question}) {
+		return
+	}
+
+	newVersion, err := h.repo.AddQuestion(c.Request.Context(), &question, version)
+	switch err {
+	case nil:
+	case repository.ErrQuizNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	case repository.ErrVersionConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "Quiz was modified by someone else; reload and try again"})
+		return
+	default:
+		log.Printf("Failed to add question to quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add question"})
+		return
+	}
+
+	signQuestions(h.store, []*models.Question{&question})
+	c.Header("ETag", quizETag(newVersion))
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data": question,
+	})
+}
+
+// withQuestionAt returns a copy of questions with question inserted where
+// AddQuestion will store it: at its position, or last when that is out of range
+func withQuestionAt(questions []*models.Question, question *models.Question) []*models.Question {
+	at := len(questions)
+	if question.Position >= 1 && question.Position <= len(questions) {
+		at = question.Position - 1
+	}
+	inserted := make([]*models.Question, 0, len(questions)+1)
+	inserted = append(inserted, questions[:at]...)
+	inserted = append(inserted, question)
+	return append(inserted, questions[at:]...)
+}
+
+// ReorderQuestions handles PUT /api/quizzes/:id/questions/order.
+// The body lists every question ID of the quiz in the desired order.
+func (h *QuizHandler) ReorderQuestions(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	quiz, _, ok := h.authorizeQuiz(c, quizId, accessEdit)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		QuestionIDs []uuid.UUID `json:"questionIds" binding:"required"`
+		Version     *int        `json:"version"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	version, err := expectedVersion(c, input.Version, quiz.Version)
+	if err == errVersionRequired {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header or version is required"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header or version"})
+		return
+	}
+
+	_, err = h.repo.ReorderQuestions(c.Request.Context(), quizId, input.QuestionIDs, version)
+	switch err {
+	case nil:
+	case repository.ErrQuizNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	case repository.ErrInvalidInput:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Question IDs must list every question of the quiz exactly once"})
+		return
+	case repository.ErrVersionConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "Quiz was modified by someone else; reload and try again"})
+		return
+	default:
+		log.Printf("Failed to reorder questions of quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder questions"})
+		return
+	}
+
+	// Return the reordered quiz
+	h.respondWithEditedQuiz(c, quizId)
 }
 
 // DeleteQuiz handles DELETE /api/quizzes/:id
@@ -212,6 +505,10 @@ func (h *QuizHandler) DeleteQuiz(c *gin.Context) {
 		return
 	}
 
+	if _, _, ok := h.authorizeQuiz(c, quizId, accessOwn); !ok {
+		return
+	}
+
 	if err := h.repo.DeleteQuiz(c.Request.Context(), quizId); err != nil {
 		if err == repository.ErrQuizNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
@@ -224,46 +521,45 @@ func (h *QuizHandler) DeleteQuiz(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// ListQuizzes handles GET /api/quizzes
+// ListQuizzes handles GET /api/quizzes. Pages are read with cursor and
+// pageSize and ordered by sort: newest (the default), title or
+// most_attempted. include=questions adds each quiz's questions.
 func (h *QuizHandler) ListQuizzes(c *gin.Context) {
-	page := 1
-	pageSize := 10
-
-	// Parse pagination parameters if provided
-	if p := c.Query("page"); p != "" {
-		if val, err := strconv.Atoi(p); err == nil && val > 0 {
-			page = val
-		}
+	view, ok := requestedView(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view"})
+		return
 	}
-	if ps := c.Query("pageSize"); ps != "" {
-		if val, err := strconv.Atoi(ps); err == nil && val > 0 {
-			pageSize = val
-		}
+	page, ok := quizPage(c, repository.QuizSortNewest)
+	if !ok {
+		return
 	}
 
-	quizzes, err := h.repo.ListQuizzes(c.Request.Context(), page, pageSize)
+	quizzes, total, next, err := h.repo.ListQuizzes(c.Request.Context(), viewerFrom(c), page)
 	if err != nil {
+		log.Printf("Failed to fetch quizzes: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quizzes"})
 		return
 	}
 
-	// Fetch questions for each quiz
-	for _, quiz := range quizzes {
-		questions, err := h.repo.ListQuizQuestions(c.Request.Context(), quiz.ID)
-		if err != nil {
+	if includesQuestions(c) {
+		if err := h.withQuestions(c, quizzes, view); err != nil {
+			log.Printf("Failed to fetch quiz questions: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz questions"})
 			return
 		}
-		quiz.Questions = questions
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": quizzes,
-		"success": true,
+		"data":       quizzes,
+		"totalCount": total,
+		"nextCursor": cursorToken(next),
+		"pageSize":   page.Limit,
+		"success":    true,
 	})
 }
 
-// ListUserQuizzes handles GET /api/users/:id/quizzes
+// ListUserQuizzes handles GET /api/users/:id/quizzes, paged and sorted as ListQuizzes
 func (h *QuizHandler) ListUserQuizzes(c *gin.Context) {
 	userId, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -271,66 +567,253 @@ func (h *QuizHandler) ListUserQuizzes(c *gin.Context) {
 		return
 	}
 
-	page := 1
-	pageSize := 10
-
-	// Parse pagination parameters if provided
-	if p := c.Query("page"); p != "" {
-		if val, err := strconv.Atoi(p); err == nil && val > 0 {
-			page = val
-		}
+	view, ok := requestedView(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view"})
+		return
 	}
-	if ps := c.Query("pageSize"); ps != "" {
-		if val, err := strconv.Atoi(ps); err == nil && val > 0 {
-			pageSize = val
-		}
+	page, ok := quizPage(c, repository.QuizSortNewest)
+	if !ok {
+		return
 	}
 
-	quizzes, err := h.repo.ListUserQuizzes(c.Request.Context(), userId, page, pageSize)
+	quizzes, total, next, err := h.repo.ListUserQuizzes(c.Request.Context(), viewerFrom(c), userId, page)
 	if err != nil {
+		log.Printf("Failed to fetch user quizzes: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user quizzes"})
 		return
 	}
 
+	if includesQuestions(c) {
+		if err := h.withQuestions(c, quizzes, view); err != nil {
+			log.Printf("Failed to fetch quiz questions: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz questions"})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"quizzes": quizzes,
+		"data":       quizzes,
+		"totalCount": total,
+		"nextCursor": cursorToken(next),
+		"pageSize":   page.Limit,
+		"success":    true,
 	})
 }
 
-// SearchQuizzes handles GET /api/quizzes/search
+// SearchQuizzes handles GET /api/quizzes/search.
+// Optional filters: topicId, creatorId, questionType, and createdAfter /
+// createdBefore as RFC 3339 timestamps or YYYY-MM-DD dates. Results are
+// paged as ListQuizzes pages them, best matches first unless sort says
+// otherwise.
 func (h *QuizHandler) SearchQuizzes(c *gin.Context) {
-	query := c.Query("q")
-	if query == "" {
+	search := repository.QuizSearch{Query: strings.TrimSpace(c.Query("q"))}
+	if search.Query == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
 		return
 	}
 
-	page := 1
-	pageSize := 10
+	view, ok := requestedView(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view"})
+		return
+	}
 
-	// Parse pagination parameters if provided
-	if p := c.Query("page"); p != "" {
-		if val, err := strconv.Atoi(p); err == nil && val > 0 {
-			page = val
+	if t := c.Query("topicId"); t != "" {
+		topicId, err := uuid.Parse(t)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+			return
+		}
+		search.TopicID = &topicId
+	}
+	if cr := c.Query("creatorId"); cr != "" {
+		creatorId, err := uuid.Parse(cr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator ID"})
+			return
 		}
+		search.CreatorID = &creatorId
+	}
+	if qt := models.QuestionType(c.Query("questionType")); qt != "" {
+		if !qt.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question type"})
+			return
+		}
+		search.QuestionType = qt
+	}
+
+	var err error
+	if search.CreatedAfter, err = parseTimeQuery(c, "createdAfter"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid createdAfter"})
+		return
 	}
+	if search.CreatedBefore, err = parseTimeQuery(c, "createdBefore"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid createdBefore"})
+		return
+	}
+
+	page, ok := quizPage(c, repository.QuizSortRelevance)
+	if !ok {
+		return
+	}
+
+	hits, total, next, err := h.repo.SearchQuizzes(c.Request.Context(), viewerFrom(c), search, page)
+	if err != nil {
+		log.Printf("Failed to search quizzes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search quizzes"})
+		return
+	}
+
+	if includesQuestions(c) {
+		quizzes := make([]*models.Quiz, len(hits))
+		for i, hit := range hits {
+			quizzes[i] = hit.Quiz
+		}
+		if err := h.withQuestions(c, quizzes, view); err != nil {
+			log.Printf("Failed to fetch quiz questions: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz questions"})
+			return
+		}
+		for i, hit := range hits {
+			hit.Quiz = quizzes[i]
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       hits,
+		"totalCount": total,
+		"nextCursor": cursorToken(next),
+		"pageSize":   page.Limit,
+		"success":    true,
+	})
+}
+
+// quizPage reads the sort, cursor and pageSize query parameters of a quiz
+// listing, writing the error response when they are invalid. Only listings
+// that rank results by default, searches, may sort by relevance.
+func quizPage(c *gin.Context, defaultSort repository.QuizSort) (repository.QuizPage, bool) {
+	page := repository.QuizPage{
+		Sort:  repository.QuizSort(c.DefaultQuery("sort", string(defaultSort))),
+		Limit: 10,
+	}
+	if !page.Sort.Valid() || (page.Sort == repository.QuizSortRelevance && defaultSort != repository.QuizSortRelevance) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort"})
+		return page, false
+	}
+
 	if ps := c.Query("pageSize"); ps != "" {
 		if val, err := strconv.Atoi(ps); err == nil && val > 0 {
-			pageSize = val
+			page.Limit = min(val, repository.MaxQuizPageSize)
+		}
+	}
+
+	if token := c.Query("cursor"); token != "" {
+		cursor, err := repository.DecodeQuizCursor(token, page.Sort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return page, false
+		}
+		page.After = cursor
+	}
+	return page, true
+}
+
+// cursorToken encodes the cursor of a listing's next page, or nil on the last page
+func cursorToken(next *repository.QuizCursor) *string {
+	if next == nil {
+		return nil
+	}
+	token := next.Encode()
+	return &token
+}
+
+// includesQuestions reports whether the ?include= query parameter asks
+// listings for quiz questions
+func includesQuestions(c *gin.Context) bool {
+	for _, include := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(include) == "questions" {
+			return true
 		}
 	}
+	return false
+}
 
-	quizzes, err := h.repo.SearchQuizzes(c.Request.Context(), query, page, pageSize)
+// withQuestions loads the questions of listed quizzes in one query and
+// projects each quiz in place. Answer keys are only included for quizzes
+// the caller may author; everything else gets the taker view.
+func (h *QuizHandler) withQuestions(c *gin.Context, quizzes []*models.Quiz, view models.QuizView) error {
+	ids := make([]uuid.UUID, len(quizzes))
+	for i, quiz := range quizzes {
+		ids[i] = quiz.ID
+	}
+	questions, err := h.repo.ListQuestionsByQuiz(c.Request.Context(), ids)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search quizzes"})
+		return err
+	}
+
+	caller := middleware.CallerFrom(c)
+	for i, quiz := range quizzes {
+		quiz.Questions = questions[quiz.ID]
+		if quiz.Questions == nil {
+			quiz.Questions = []*models.Question{}
+		}
+
+		quizView := view
+		if !canAuthor(caller, quiz) {
+			quizView = models.QuizViewTaker
+		}
+		quizzes[i] = project(quiz, quizView)
+		signQuestions(h.store, quizzes[i].Questions)
+	}
+	return nil
+}
+
+// RecordAttempt handles POST /quizzes/:id/attempts for the study service,
+// which reports each attempt a learner starts so listings can sort by them
+func (h *QuizHandler) RecordAttempt(c *gin.Context) {
+	if !middleware.CallerFrom(c).Trusted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only trusted services may record attempts"})
+		return
+	}
+
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	count, err := h.repo.RecordAttempt(c.Request.Context(), quizId)
+	if err == repository.ErrQuizNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	} else if err != nil {
+		log.Printf("Failed to record attempt at quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record attempt"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"quizzes": quizzes,
+		"data":    gin.H{"attemptCount": count},
+		"success": true,
 	})
 }
 
+// parseTimeQuery reads an optional RFC 3339 timestamp or YYYY-MM-DD date query parameter
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", value); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
 // GetQuizQuestions handles GET /api/quizzes/:id/questions
 func (h *QuizHandler) GetQuizQuestions(c *gin.Context) {
 	quizId, err := uuid.Parse(c.Param("id"))
@@ -339,17 +822,153 @@ func (h *QuizHandler) GetQuizQuestions(c *gin.Context) {
 		return
 	}
 
+	view, ok := requestedView(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view"})
+		return
+	}
+
 	log.Printf("Fetching questions for quiz: %s", quizId)
-	questions, err := h.repo.ListQuizQuestions(c.Request.Context(), quizId)
-	if err != nil {
-		log.Printf("Error fetching questions: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz questions"})
+	quiz, access, ok := h.authorizeQuiz(c, quizId, accessView)
+	if !ok {
 		return
 	}
 
-	log.Printf("Returning %d questions", len(questions))
+	if view == models.QuizViewAuthor && access < accessEdit {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to view answer keys for this quiz"})
+		return
+	}
+
+	questions := project(quiz, view).Questions
+	signQuestions(h.store, questions)
+	log.Printf("Returning %d questions (%s view)", len(questions), view)
 	c.JSON(http.StatusOK, gin.H{
 		"data": questions,
 		"success": true,
 	})
-} 
\ No newline at end of file
+}
+
+// requestedView reads the ?view= query parameter, defaulting to the taker view
+func requestedView(c *gin.Context) (models.QuizView, bool) {
+	switch view := models.QuizView(c.DefaultQuery("view", string(models.QuizViewTaker))); view {
+	case models.QuizViewTaker, models.QuizViewAuthor:
+		return view, true
+	default:
+		return "", false
+	}
+}
+
+// canAuthor reports whether the caller owns a quiz, which lets listings show
+// its answer keys without looking up share grants. Single-quiz endpoints also
+// let editors see answer keys; see quizAccess.
+func canAuthor(caller middleware.Caller, quiz *models.Quiz) bool {
+	level, _ := baseAccess(caller, quiz.CreatorID, quiz.Visibility)
+	return level == accessOwn
+}
+
+// viewerFrom describes the caller for repository listings
+func viewerFrom(c *gin.Context) repository.Viewer {
+	caller := middleware.CallerFrom(c)
+	return repository.Viewer{UserID: caller.UserID, Trusted: caller.Trusted}
+}
+
+// authorizeQuiz loads a quiz and checks that the caller has at least the
+// required access, writing the error response when they do not
+func (h *QuizHandler) authorizeQuiz(c *gin.Context, quizId uuid.UUID, required accessLevel) (*models.Quiz, accessLevel, bool) {
+	quiz, err := h.repo.GetQuiz(c.Request.Context(), quizId)
+	if err == repository.ErrQuizNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return nil, accessNone, false
+	} else if err != nil {
+		log.Printf("Error fetching quiz: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz"})
+		return nil, accessNone, false
+	}
+
+	access, err := h.quizAccess(c, middleware.CallerFrom(c), quiz)
+	if err != nil {
+		log.Printf("Error checking access to quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz"})
+		return nil, accessNone, false
+	}
+
+	if access < required {
+		denyAccess(c, access, "Quiz not found", "Not allowed to modify this quiz")
+		return nil, access, false
+	}
+	return quiz, access, true
+}
+
+// quizAccess works out a caller's access to a quiz from its creator,
+// visibility and any share grant
+func (h *QuizHandler) quizAccess(c *gin.Context, caller middleware.Caller, quiz *models.Quiz) (accessLevel, error) {
+	access, needsGrant := baseAccess(caller, quiz.CreatorID, quiz.Visibility)
+	if !needsGrant {
+		return access, nil
+	}
+
+	grant, err := h.repo.GetQuizAccess(c.Request.Context(), quiz.ID, caller.UserID)
+	if err == repository.ErrSharedAccessNotFound {
+		return access, nil
+	}
+	if err != nil {
+		return accessNone, err
+	}
+	return grantedAccess(grant.AccessType), nil
+}
+
+// project returns the quiz in the given view
+func project(quiz *models.Quiz, view models.QuizView) *models.Quiz {
+	if view == models.QuizViewAuthor {
+		return quiz
+	}
+	return quiz.ForTaker()
+} 
+
+// checkQuiz validates a quiz about to be saved, writing the error response
+// when it is invalid. Every write checks the whole quiz it would leave behind.
+func checkQuiz(c *gin.Context, quiz *models.Quiz) bool {
+	if errs := quiz.Validate(); len(errs) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid quiz", "errors": errs})
+		return false
+	}
+	return true
+}
+
+// errVersionRequired is returned when an update carries no version precondition
+var errVersionRequired = errors.New("version precondition required")
+
+// errInvalidVersion is returned for a version precondition that no quiz can have
+var errInvalidVersion = errors.New("invalid version precondition")
+
+// quizETag formats a quiz version as a strong entity tag
+func quizETag(version int) string {
+	return fmt.Sprintf("%q", strconv.Itoa(version))
+}
+
+// expectedVersion returns the quiz version the client last read, taken from
+// the If-Match header or the request body. "If-Match: *" matches any version.
+// Versions start at 1, so anything lower is rejected.
+func expectedVersion(c *gin.Context, bodyVersion *int, currentVersion int) (int, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	var version int
+	switch {
+	case ifMatch == "*":
+		version = currentVersion
+	case ifMatch != "":
+		tag := strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+		parsed, err := strconv.Atoi(tag)
+		if err != nil {
+			return 0, errInvalidVersion
+		}
+		version = parsed
+	case bodyVersion != nil:
+		version = *bodyVersion
+	default:
+		return 0, errVersionRequired
+	}
+	if version < 1 {
+		return 0, errInvalidVersion
+	}
+	return version, nil
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+)
+
+// fakeContentRepo keeps a single quiz in memory and records whether a write reached it
+type fakeContentRepo struct {
+	repository.ContentRepository
+	quiz  *models.Quiz
+	saved bool
+}
+
+func (r *fakeContentRepo) GetQuiz(ctx context.Context, id uuid.UUID) (*models.Quiz, error) {
+	if r.quiz == nil || r.quiz.ID != id {
+		return nil, repository.ErrQuizNotFound
+	}
+	quiz := *r.quiz
+	quiz.Questions = append([]*models.Question(nil), r.quiz.Questions...)
+	return &quiz, nil
+}
+
+func (r *fakeContentRepo) CreateQuiz(ctx context.Context, quiz *models.Quiz) error {
+	r.saved = true
+	quiz.Version = 1
+	r.quiz = quiz
+	return nil
+}
+
+func (r *fakeContentRepo) UpdateQuiz(ctx context.Context, quiz *models.Quiz) error {
+	r.saved = true
+	quiz.Version++
+	r.quiz = quiz
+	return nil
+}
+
+func (r *fakeContentRepo) UpdateQuizWithQuestions(ctx context.Context, quiz *models.Quiz) error {
+	return r.UpdateQuiz(ctx, quiz)
+}
+
+func (r *fakeContentRepo) AddQuestion(ctx context.Context, question *models.Question, expectedVersion int) (int, error) {
+	if expectedVersion != r.quiz.Version {
+		return 0, repository.ErrVersionConflict
+	}
+	r.saved = true
+	r.quiz.Version++
+	r.quiz.Questions = append(r.quiz.Questions, question)
+	return r.quiz.Version, nil
+}
+
+// testQuiz returns a valid quiz owned by owner
+func testQuiz(owner uuid.UUID) *models.Quiz {
+	id := uuid.New()
+	return &models.Quiz{
+		ID:         id,
+		Title:      "Capitals",
+		CreatorID:  owner,
+		Visibility: models.VisibilityPrivate,
+		Version:    3,
+		Questions: []*models.Question{{
+			ID:            uuid.New(),
+			QuizID:        id,
+			Position:      1,
+			Text:          "Capital of France?",
+			Type:          models.QuestionTypeMultipleChoice,
+			Options:       []string{"Paris", "Rome"},
+			CorrectAnswer: "Paris",
+		}},
+	}
+}
+
+func newTestRouter(repo *fakeContentRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewQuizHandler(repo, nil, nil, nil)
+	r := gin.New()
+	r.Use(middleware.Identity())
+	r.POST("/quizzes", h.CreateQuiz)
+	r.PATCH("/quizzes/:id", h.UpdateQuiz)
+	r.POST("/quizzes/:id/questions", h.AddQuestion)
+	r.PUT("/quizzes/:id/questions/order", h.ReorderQuestions)
+	r.POST("/quizzes/import", h.ImportQuiz)
+	r.POST("/quizzes/:id/import", h.ImportQuestions)
+	return r
+}
+
+// send performs a request as user and decodes the JSON response
+func send(t *testing.T, r http.Handler, user uuid.UUID, method, path, body string, headers map[string]string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.UserIDHeader, user.String())
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), w.Body.String())
+	return w.Code, response
+}
+
+// errorFields lists the fields of the validation errors in a 422 response
+func errorFields(response map[string]interface{}) []string {
+	var fields []string
+	errs, _ := response["errors"].([]interface{})
+	for _, err := range errs {
+		fields = append(fields, err.(map[string]interface{})["field"].(string))
+	}
+	return fields
+}
+
+func TestWritesRejectInvalidQuizzes(t *testing.T) {
+	owner := uuid.New()
+	// Multiple choice without a correct option; GIFT cannot express it, so
+	// imports use options that only differ by case
+	invalid := `{"text": "Capital of Italy?", "type": "multiple_choice", "options": ["Paris", "Rome"], "version": 3}`
+	gift := "Capital of Italy? {~Rome =Paris ~rome}"
+
+	tests := []struct {
+		name   string
+		method string
+		path   func(quiz *models.Quiz) string
+		body   string
+		fields []string
+	}{
+		{
+			name:   "update",
+			method: http.MethodPatch,
+			path:   func(quiz *models.Quiz) string { return "/quizzes/" + quiz.ID.String() },
+			body:   `{"version": 3, "questions": [` + invalid + `]}`,
+			fields: []string{"questions[0].correctAnswer"},
+		},
+		{
+			name:   "update settings",
+			method: http.MethodPatch,
+			path:   func(quiz *models.Quiz) string { return "/quizzes/" + quiz.ID.String() },
+			body:   `{"version": 3, "settings": {"timeLimit": -1}}`,
+			fields: []string{"settings.timeLimit"},
+		},
+		{
+			name:   "add question",
+			method: http.MethodPost,
+			path:   func(quiz *models.Quiz) string { return "/quizzes/" + quiz.ID.String() + "/questions" },
+			body:   invalid,
+			fields: []string{"correctAnswer"},
+		},
+		{
+			name:   "import quiz",
+			method: http.MethodPost,
+			path:   func(*models.Quiz) string { return "/quizzes/import?format=gift" },
+			body:   gift,
+			fields: []string{"questions[0].options[2]"},
+		},
+		{
+			name:   "import questions",
+			method: http.MethodPost,
+			path: func(quiz *models.Quiz) string {
+				return "/quizzes/" + quiz.ID.String() + "/import?format=gift&version=3"
+			},
+			body:   gift,
+			fields: []string{"questions[0].options[2]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quiz := testQuiz(owner)
+			repo := &fakeContentRepo{quiz: quiz}
+
+			code, response := send(t, newTestRouter(repo), owner, tt.method, tt.path(quiz), tt.body, nil)
+			assert.Equal(t, http.StatusUnprocessableEntity, code)
+			assert.Equal(t, tt.fields, errorFields(response))
+			assert.False(t, repo.saved, "invalid quiz was saved")
+		})
+	}
+}
+
+func TestAddQuestionChecksWholeQuiz(t *testing.T) {
+	owner := uuid.New()
+	quiz := testQuiz(owner)
+	score := 150.0
+	quiz.Settings.PassingScore = &score
+	repo := &fakeContentRepo{quiz: quiz}
+	body := `{"text": "Capital of Italy?", "type": "multiple_choice", "options": ["Paris", "Rome"], "correctAnswer": "Rome", "position": 1, "version": 3}`
+
+	code, response := send(t, newTestRouter(repo), owner, http.MethodPost, "/quizzes/"+quiz.ID.String()+"/questions", body, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, []string{"settings.passingScore"}, errorFields(response))
+	assert.False(t, repo.saved, "invalid quiz was saved")
+	assert.Len(t, quiz.Questions, 1)
+}
+
+func TestCreateQuizNeedsUser(t *testing.T) {
+	repo := &fakeContentRepo{}
+	r := newTestRouter(repo)
+	body := `{"title": "Capitals", "visibility": "public"}`
+
+	code, _ := send(t, r, uuid.Nil, http.MethodPost, "/quizzes", body, nil)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.False(t, repo.saved)
+
+	owner := uuid.New()
+	code, response := send(t, r, owner, http.MethodPost, "/quizzes", body, nil)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, owner.String(), response["data"].(map[string]interface{})["creatorId"])
+}
+
+func TestEditsReturnAuthorView(t *testing.T) {
+	owner := uuid.New()
+	quiz := testQuiz(owner)
+	repo := &fakeContentRepo{quiz: quiz}
+
+	code, response := send(t, newTestRouter(repo), owner, http.MethodPatch, "/quizzes/"+quiz.ID.String(), `{"version": 3, "title": "European capitals"}`, nil)
+	require.Equal(t, http.StatusOK, code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(4), data["version"])
+	question := data["questions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Paris", question["correctAnswer"], "answer keys are kept for the author")
+}
+
+func TestQuestionEditsNeedVersion(t *testing.T) {
+	owner := uuid.New()
+	quiz := testQuiz(owner)
+	repo := &fakeContentRepo{quiz: quiz}
+	r := newTestRouter(repo)
+	path := "/quizzes/" + quiz.ID.String() + "/questions"
+	question := `{"text": "Capital of Italy?", "type": "multiple_choice", "options": ["Paris", "Rome"], "correctAnswer": "Rome"`
+
+	code, _ := send(t, r, owner, http.MethodPut, path+"/order", `{"questionIds": ["`+quiz.Questions[0].ID.String()+`"]}`, nil)
+	assert.Equal(t, http.StatusPreconditionRequired, code)
+
+	code, _ = send(t, r, owner, http.MethodPost, path, question+`}`, nil)
+	assert.Equal(t, http.StatusPreconditionRequired, code)
+
+	// Version 0 would switch the precondition off, so it is rejected
+	code, _ = send(t, r, owner, http.MethodPut, path+"/order", `{"version": 0, "questionIds": ["`+quiz.Questions[0].ID.String()+`"]}`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = send(t, r, owner, http.MethodPost, path, question+`, "version": 0}`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = send(t, r, owner, http.MethodPost, path, question+`}`, map[string]string{"If-Match": `"0"`})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = send(t, r, owner, http.MethodPost, path, question+`}`, map[string]string{"If-Match": `"2"`})
+	assert.Equal(t, http.StatusConflict, code)
+	assert.False(t, repo.saved)
+
+	code, _ = send(t, r, owner, http.MethodPost, path, question+`, "version": 3}`, nil)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Len(t, repo.quiz.Questions, 2)
+}
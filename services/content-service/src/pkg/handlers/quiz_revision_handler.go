@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+)
+
+// ListRevisions handles GET /api/quizzes/:id/revisions
+func (h *QuizHandler) ListRevisions(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	if _, _, ok := h.authorizeQuiz(c, quizId, accessView); !ok {
+		return
+	}
+
+	revisions, err := h.repo.ListRevisions(c.Request.Context(), quizId)
+	if err != nil {
+		log.Printf("Error listing revisions of quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list revisions"})
+		return
+	}
+	if revisions == nil {
+		revisions = []*models.QuizRevision{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    revisions,
+		"success": true,
+	})
+}
+
+// GetRevision handles GET /api/quizzes/:id/revisions/:revisionId
+func (h *QuizHandler) GetRevision(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	revisionId, err := uuid.Parse(c.Param("revisionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision ID"})
+		return
+	}
+
+	view, ok := requestedView(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view"})
+		return
+	}
+
+	_, access, ok := h.authorizeQuiz(c, quizId, accessView)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.fetchRevision(c, quizId, revisionId)
+	if !ok {
+		return
+	}
+
+	if view == models.QuizViewAuthor && access < accessEdit {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to view answer keys for this quiz"})
+		return
+	}
+
+	projected := *revision
+	projected.Quiz = project(revision.Quiz, view)
+	signQuestions(h.store, projected.Quiz.Questions)
+	c.JSON(http.StatusOK, gin.H{
+		"data":    projected,
+		"success": true,
+	})
+}
+
+// DiffRevisions handles GET /api/quizzes/:id/revisions/diff?from=&to=
+// When "to" is omitted the diff is taken against the live revision.
+func (h *QuizHandler) DiffRevisions(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	// Diffs expose answer key changes, so they are limited to authors
+	quiz, _, ok := h.authorizeQuiz(c, quizId, accessEdit)
+	if !ok {
+		return
+	}
+
+	fromId, err := uuid.Parse(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from revision ID"})
+		return
+	}
+
+	var toId uuid.UUID
+	if to := c.Query("to"); to != "" {
+		if toId, err = uuid.Parse(to); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to revision ID"})
+			return
+		}
+	} else if quiz.RevisionID != nil {
+		toId = *quiz.RevisionID
+	} else {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz has no revisions yet"})
+		return
+	}
+
+	from, ok := h.fetchRevision(c, quizId, fromId)
+	if !ok {
+		return
+	}
+	to, ok := h.fetchRevision(c, quizId, toId)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    models.DiffRevisions(from, to),
+		"success": true,
+	})
+}
+
+// RestoreRevision handles POST /api/quizzes/:id/revisions/:revisionId/restore
+func (h *QuizHandler) RestoreRevision(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	revisionId, err := uuid.Parse(c.Param("revisionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision ID"})
+		return
+	}
+
+	quiz, _, ok := h.authorizeQuiz(c, quizId, accessEdit)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Version *int `json:"version"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+	}
+
+	// Restoring replaces the whole quiz, so like PATCH it must name the
+	// version it was based on
+	version, err := expectedVersion(c, input.Version, quiz.Version)
+	if err == errVersionRequired {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header or version is required"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header or version"})
+		return
+	}
+
+	err = h.repo.RestoreRevision(c.Request.Context(), quizId, revisionId, version)
+	switch err {
+	case nil:
+	case repository.ErrQuizNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	case repository.ErrRevisionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	case repository.ErrVersionConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "Quiz was modified by someone else; reload and try again"})
+		return
+	default:
+		log.Printf("Failed to restore revision %s of quiz %s: %v", revisionId, quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore revision"})
+		return
+	}
+
+	// Return the restored quiz
+	h.respondWithEditedQuiz(c, quizId)
+}
+
+// fetchRevision loads a revision, writing the error response when it fails
+func (h *QuizHandler) fetchRevision(c *gin.Context, quizId, revisionId uuid.UUID) (*models.QuizRevision, bool) {
+	revision, err := h.repo.GetRevision(c.Request.Context(), quizId, revisionId)
+	if err == repository.ErrRevisionNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return nil, false
+	} else if err != nil {
+		log.Printf("Error fetching revision %s: %v", revisionId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revision"})
+		return nil, false
+	}
+	return revision, true
+}
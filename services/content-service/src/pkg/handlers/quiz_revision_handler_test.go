@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/repository"
+)
+
+// fakeRevisionRepo records the version precondition a restore was made with
+type fakeRevisionRepo struct {
+	*fakeContentRepo
+	restoredAt int
+}
+
+func (r *fakeRevisionRepo) RestoreRevision(ctx context.Context, quizID, revisionID uuid.UUID, expectedVersion int) error {
+	if expectedVersion != r.quiz.Version {
+		return repository.ErrVersionConflict
+	}
+	r.restoredAt = expectedVersion
+	r.quiz.Version++
+	return nil
+}
+
+func TestRestoreRevisionNeedsVersion(t *testing.T) {
+	owner := uuid.New()
+	quiz := testQuiz(owner)
+	repo := &fakeRevisionRepo{fakeContentRepo: &fakeContentRepo{quiz: quiz}}
+
+	gin.SetMode(gin.TestMode)
+	h := NewQuizHandler(repo, nil, nil, nil)
+	r := gin.New()
+	r.Use(middleware.Identity())
+	r.POST("/quizzes/:id/revisions/:revisionId/restore", h.RestoreRevision)
+	path := "/quizzes/" + quiz.ID.String() + "/revisions/" + uuid.New().String() + "/restore"
+
+	code, _ := send(t, r, owner, http.MethodPost, path, "", nil)
+	assert.Equal(t, http.StatusPreconditionRequired, code)
+	code, _ = send(t, r, owner, http.MethodPost, path, `{"version": 0}`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = send(t, r, owner, http.MethodPost, path, `{"version": 2}`, nil)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Zero(t, repo.restoredAt)
+
+	// "If-Match: *" restores over the version the handler just read
+	code, _ = send(t, r, owner, http.MethodPost, path, "", map[string]string{"If-Match": "*"})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 3, repo.restoredAt)
+}
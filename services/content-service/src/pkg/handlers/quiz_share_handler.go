@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+)
+
+// ListQuizShares handles GET /api/quizzes/:id/shares
+func (h *QuizHandler) ListQuizShares(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	if _, _, ok := h.authorizeQuiz(c, quizId, accessOwn); !ok {
+		return
+	}
+
+	grants, err := h.repo.ListQuizAccess(c.Request.Context(), quizId)
+	if err != nil {
+		log.Printf("Error listing shares of quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz shares"})
+		return
+	}
+	if grants == nil {
+		grants = []*models.QuizAccess{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    grants,
+		"success": true,
+	})
+}
+
+// CheckQuizAccess handles GET /quizzes/:id/access for trusted services
+// acting for the user named by X-User-ID. Trusted requests may read any quiz
+// with its answer keys, so services check here that the user may view the
+// quiz before reading it for them.
+func (h *QuizHandler) CheckQuizAccess(c *gin.Context) {
+	caller := middleware.CallerFrom(c)
+	if !caller.Trusted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only trusted services may check access for users"})
+		return
+	}
+	if !caller.Authenticated() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "X-User-ID header is required"})
+		return
+	}
+
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	quiz, err := h.repo.GetQuiz(c.Request.Context(), quizId)
+	if err == repository.ErrQuizNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	} else if err != nil {
+		log.Printf("Error fetching quiz: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz"})
+		return
+	}
+
+	// The user's own access, without the service's trust
+	access, err := h.quizAccess(c, middleware.Caller{UserID: caller.UserID}, quiz)
+	if err != nil {
+		log.Printf("Error checking access to quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check quiz access"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"canView": access >= accessView,
+			"canEdit": access >= accessEdit,
+		},
+		"success": true,
+	})
+}
+
+// ShareQuiz handles PUT /api/quizzes/:id/shares/:userId
+func (h *QuizHandler) ShareQuiz(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	userId, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	quiz, _, ok := h.authorizeQuiz(c, quizId, accessOwn)
+	if !ok {
+		return
+	}
+	if userId == quiz.CreatorID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quiz creator already has full access"})
+		return
+	}
+
+	var input struct {
+		AccessType models.AccessType `json:"accessType" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if !input.AccessType.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid access type"})
+		return
+	}
+
+	access := models.NewQuizAccess(quizId, userId, input.AccessType)
+	if err := h.repo.ShareQuiz(c.Request.Context(), access); err != nil {
+		if err == repository.ErrQuizNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+			return
+		}
+		log.Printf("Failed to share quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share quiz"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    access,
+		"success": true,
+	})
+}
+
+// RevokeQuizShare handles DELETE /api/quizzes/:id/shares/:userId
+func (h *QuizHandler) RevokeQuizShare(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	userId, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if _, _, ok := h.authorizeQuiz(c, quizId, accessOwn); !ok {
+		return
+	}
+
+	if err := h.repo.RevokeQuizAccess(c.Request.Context(), quizId, userId); err != nil {
+		if err == repository.ErrSharedAccessNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User has no access to revoke"})
+			return
+		}
+		log.Printf("Failed to revoke access to quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke quiz access"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+)
+
+func TestCheckQuizAccess(t *testing.T) {
+	t.Setenv("INTERNAL_SERVICE_TOKEN", "service-token")
+	owner := uuid.New()
+	quiz := testQuiz(owner)
+
+	gin.SetMode(gin.TestMode)
+	h := NewQuizHandler(&fakeContentRepo{quiz: quiz}, nil, nil, nil)
+	r := gin.New()
+	r.Use(middleware.Identity())
+	r.GET("/quizzes/:id/access", h.CheckQuizAccess)
+	path := "/quizzes/" + quiz.ID.String() + "/access"
+	trusted := map[string]string{middleware.ServiceTokenHeader: "service-token"}
+
+	code, response := send(t, r, owner, http.MethodGet, path, "", trusted)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"canView": true, "canEdit": true}, response["data"])
+
+	// Trust lets the service read the private quiz, but not on behalf of a stranger
+	code, response = send(t, r, uuid.New(), http.MethodGet, path, "", trusted)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"canView": false, "canEdit": false}, response["data"])
+
+	code, _ = send(t, r, owner, http.MethodGet, path, "", nil)
+	assert.Equal(t, http.StatusForbidden, code)
+}
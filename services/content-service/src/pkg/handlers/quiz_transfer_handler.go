@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/formats"
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+)
+
+// maxImportSize limits the size of uploaded question files
+const maxImportSize = 5 << 20
+
+// importParsers maps the ?format= values accepted by ImportQuiz to their parsers
+var importParsers = map[string]func(io.Reader) (*formats.Result, error){
+	"gift":   formats.ParseGIFT,
+	"aiken":  formats.ParseAiken,
+	"qti":    formats.ParseQTI,
+	"moodle": formats.ParseMoodleXML,
+	"csv":    formats.ParseCSV,
+	"xlsx":   formats.ParseXLSX,
+}
+
+// exportFormat describes a ?format= value accepted by ExportQuiz
+type exportFormat struct {
+	write       func(io.Writer, *models.Quiz) error
+	contentType string
+	extension   string
+}
+
+// exportFormats maps the ?format= values accepted by ExportQuiz to their writers
+var exportFormats = map[string]exportFormat{
+	"qti":    {write: formats.WriteQTI, contentType: "application/zip", extension: ".zip"},
+	"moodle": {write: formats.WriteMoodleXML, contentType: "application/xml", extension: ".xml"},
+	"csv":    {write: formats.WriteCSV, contentType: "text/csv; charset=utf-8", extension: ".csv"},
+	"xlsx": {
+		write:       formats.WriteXLSX,
+		contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		extension:   ".xlsx",
+	},
+}
+
+// ExportQuiz handles GET /api/quizzes/:id/export?format=qti|moodle|csv|xlsx.
+// Exports carry the answer keys, so they are limited to authors.
+func (h *QuizHandler) ExportQuiz(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	format, ok := exportFormats[strings.ToLower(c.Query("format"))]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format"})
+		return
+	}
+
+	quiz, _, ok := h.authorizeQuiz(c, quizId, accessEdit)
+	if !ok {
+		return
+	}
+
+	// Write to a buffer so that failures can still be reported as JSON
+	var buf bytes.Buffer
+	if err := format.write(&buf, quiz); err != nil {
+		if errors.Is(err, formats.ErrUnsupportedQuestion) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Failed to export quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export quiz"})
+		return
+	}
+
+	name := models.Slugify(quiz.Title)
+	if name == "" {
+		name = "quiz"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s%s"`, name, format.extension))
+	c.Data(http.StatusOK, format.contentType, buf.Bytes())
+}
+
+// ImportQuiz handles POST /api/quizzes/import?format=gift|aiken|qti|moodle|csv|xlsx.
+// The file is sent as the "file" field of a multipart form or as the raw
+// request body; title, description, topicId and visibility may be given as
+// form fields or query parameters. With dryRun=true the parsed quiz is
+// returned without being saved, along with any validationErrors the saved
+// quiz would fail. Questions skipped with a warning do not stop the import;
+// errors and validation errors do.
+func (h *QuizHandler) ImportQuiz(c *gin.Context) {
+	parse, ok := importParsers[strings.ToLower(c.Query("format"))]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported import format"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	source, name, ok := importSource(c)
+	if !ok {
+		return
+	}
+	defer source.Close()
+
+	result, err := parse(source)
+	if err != nil {
+		log.Printf("Failed to read import file: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file"})
+		return
+	}
+
+	var topicID *uuid.UUID
+	if t := importField(c, "topicId"); t != "" {
+		id, err := uuid.Parse(t)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+			return
+		}
+		topicID = &id
+	}
+
+	// Prefer the given title, then the one in the file, then the file name
+	title := importField(c, "title")
+	if title == "" {
+		title = result.Title
+	}
+	if title == "" {
+		title = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if title == "" {
+		title = "Imported quiz"
+	}
+	description := importField(c, "description")
+	if description == "" {
+		description = result.Description
+	}
+
+	quiz, ok := newCallerQuiz(c, title, description, topicID, models.VisibilityType(importField(c, "visibility")))
+	if !ok {
+		return
+	}
+	for i, question := range result.Questions {
+		question.ID = uuid.New()
+		question.QuizID = quiz.ID
+		question.Position = i + 1
+	}
+	quiz.Questions = result.Questions
+
+	if c.Query("dryRun") == "true" {
+		c.JSON(http.StatusOK, gin.H{
+			"data":             quiz,
+			"errors":           orNoProblems(result.Errors),
+			"warnings":         orNoProblems(result.Warnings),
+			"validationErrors": orNoErrors(quiz.Validate()),
+			"dryRun":           true,
+			"success":          true,
+		})
+		return
+	}
+
+	// Nothing is saved unless the whole file imports cleanly
+	if !result.OK() {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":    "File contains questions that cannot be imported",
+			"errors":   result.Errors,
+			"warnings": orNoProblems(result.Warnings),
+		})
+		return
+	}
+	if len(quiz.Questions) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "File contains no questions"})
+		return
+	}
+	if !checkQuiz(c, quiz) {
+		return
+	}
+
+	if err := h.repo.CreateQuiz(c.Request.Context(), quiz); err != nil {
+		if err == repository.ErrTopicNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Topic does not exist"})
+			return
+		}
+		log.Printf("Failed to save imported quiz: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quiz"})
+		return
+	}
+
+	c.Header("ETag", quizETag(quiz.Version))
+	c.JSON(http.StatusCreated, gin.H{
+		"data":     quiz,
+		"warnings": orNoProblems(result.Warnings),
+		"success":  true,
+	})
+}
+
+// ImportQuestions handles POST /api/quizzes/:id/import?format=.
+// The file replaces the quiz's questions in one transaction: rows that carry
+// the ID of one of the quiz's questions update it, rows without an ID add a
+// question and questions missing from the file are deleted. The file is sent
+// like for ImportQuiz; the quiz version comes from If-Match or a "version"
+// field. With dryRun=true the parsed questions are returned without being
+// saved, with the quiz's validationErrors. Nothing is saved if any row has
+// errors or the quiz would be invalid.
+func (h *QuizHandler) ImportQuestions(c *gin.Context) {
+	quizId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	parse, ok := importParsers[strings.ToLower(c.Query("format"))]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported import format"})
+		return
+	}
+
+	quiz, _, ok := h.authorizeQuiz(c, quizId, accessEdit)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	source, _, ok := importSource(c)
+	if !ok {
+		return
+	}
+	defer source.Close()
+
+	var bodyVersion *int
+	if v := importField(c, "version"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+			return
+		}
+		bodyVersion = &version
+	}
+	version, err := expectedVersion(c, bodyVersion, quiz.Version)
+	if err == errVersionRequired {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header or version is required"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header or version"})
+		return
+	}
+
+	result, err := parse(source)
+	if err != nil {
+		log.Printf("Failed to read import file: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file"})
+		return
+	}
+	for i, question := range result.Questions {
+		question.QuizID = quiz.ID
+		question.Position = i + 1
+	}
+	quiz.Questions = result.Questions
+
+	if c.Query("dryRun") == "true" {
+		c.JSON(http.StatusOK, gin.H{
+			"data":             result.Questions,
+			"errors":           orNoProblems(result.Errors),
+			"warnings":         orNoProblems(result.Warnings),
+			"validationErrors": orNoErrors(quiz.Validate()),
+			"dryRun":           true,
+			"success":          true,
+		})
+		return
+	}
+
+	if !result.OK() {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":    "File contains questions that cannot be imported",
+			"errors":   result.Errors,
+			"warnings": orNoProblems(result.Warnings),
+		})
+		return
+	}
+	if len(result.Questions) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "File contains no questions"})
+		return
+	}
+	if !checkQuiz(c, quiz) {
+		return
+	}
+
+	quiz.Version = version
+	switch err := h.repo.UpdateQuizWithQuestions(c.Request.Context(), quiz); err {
+	case nil:
+	case repository.ErrQuizNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	case repository.ErrInvalidInput:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Questions must be new or already belong to this quiz"})
+		return
+	case repository.ErrTopicNotFound:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Topic does not exist"})
+		return
+	case repository.ErrVersionConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "Quiz was modified by someone else; reload and try again"})
+		return
+	default:
+		log.Printf("Failed to import questions into quiz %s: %v", quizId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quiz"})
+		return
+	}
+
+	h.respondWithEditedQuiz(c, quizId)
+}
+
+// importSource opens the uploaded file, writing the error response when it
+// is missing. The name is empty for raw request bodies.
+func importSource(c *gin.Context) (io.ReadCloser, string, bool) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, "", true
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file field is required"})
+		return nil, "", false
+	}
+	file, err := header.Open()
+	if err != nil {
+		log.Printf("Failed to open uploaded file: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file"})
+		return nil, "", false
+	}
+	return file, header.Filename, true
+}
+
+// importField reads an import option from the form, falling back to the query
+func importField(c *gin.Context, key string) string {
+	return strings.TrimSpace(c.DefaultPostForm(key, c.Query(key)))
+}
+
+// orNoProblems keeps an empty problem list from being encoded as null
+func orNoProblems(problems []formats.Problem) []formats.Problem {
+	if problems == nil {
+		return []formats.Problem{}
+	}
+	return problems
+}
+
+// orNoErrors keeps an empty validation error list from being encoded as null
+func orNoErrors(errs []models.ValidationError) []models.ValidationError {
+	if errs == nil {
+		return []models.ValidationError{}
+	}
+	return errs
+}
@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+)
+
+// StudySetHandler handles HTTP requests for study sets and their items
+type StudySetHandler struct {
+	repo repository.StudySetRepository
+}
+
+// NewStudySetHandler creates a new StudySetHandler instance
+func NewStudySetHandler(repo repository.StudySetRepository) *StudySetHandler {
+	return &StudySetHandler{repo: repo}
+}
+
+// ListStudySets handles GET /api/study-sets
+func (h *StudySetHandler) ListStudySets(c *gin.Context) {
+	caller := middleware.CallerFrom(c)
+	filter := repository.StudySetFilter{
+		ViewerID:   caller.UserID,
+		AllVisible: caller.Trusted,
+		Visibility: models.VisibilityType(c.Query("visibility")),
+		Tags:       models.NormalizeTags(c.QueryArray("tag")),
+	}
+
+	if filter.Visibility != "" && !filter.Visibility.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid visibility"})
+		return
+	}
+	if o := c.Query("ownerId"); o != "" {
+		ownerId, err := uuid.Parse(o)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid owner ID"})
+			return
+		}
+		filter.OwnerID = &ownerId
+	}
+
+	page := 1
+	pageSize := 10
+
+	// Parse pagination parameters if provided
+	if p := c.Query("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil && val > 0 {
+			page = val
+		}
+	}
+	if ps := c.Query("pageSize"); ps != "" {
+		if val, err := strconv.Atoi(ps); err == nil && val > 0 {
+			pageSize = val
+		}
+	}
+
+	sets, err := h.repo.ListStudySets(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		log.Printf("Error listing study sets: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch study sets"})
+		return
+	}
+	if sets == nil {
+		sets = []*models.StudySet{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    sets,
+		"success": true,
+	})
+}
+
+// CreateStudySet handles POST /api/study-sets
+func (h *StudySetHandler) CreateStudySet(c *gin.Context) {
+	caller := middleware.CallerFrom(c)
+	if !caller.Authenticated() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var input struct {
+		Title       string                `json:"title" binding:"required"`
+		Description string                `json:"description"`
+		Visibility  models.VisibilityType `json:"visibility"`
+		Tags        []string              `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if input.Visibility == "" {
+		input.Visibility = models.VisibilityPrivate
+	}
+	if !input.Visibility.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid visibility"})
+		return
+	}
+
+	set := models.NewStudySet(input.Title, input.Description, caller.UserID, input.Visibility, input.Tags)
+	if err := h.repo.CreateStudySet(c.Request.Context(), set); err != nil {
+		log.Printf("Error creating study set: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create study set"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    set,
+		"success": true,
+	})
+}
+
+// GetStudySet handles GET /api/study-sets/:id
+func (h *StudySetHandler) GetStudySet(c *gin.Context) {
+	set, ok := h.authorize(c, accessView)
+	if !ok {
+		return
+	}
+
+	items, err := h.repo.ListContentItems(c.Request.Context(), set.ID)
+	if err != nil {
+		log.Printf("Error listing items of study set %s: %v", set.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch study set items"})
+		return
+	}
+	set.Items = items
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    set,
+		"success": true,
+	})
+}
+
+// UpdateStudySet handles PATCH /api/study-sets/:id
+func (h *StudySetHandler) UpdateStudySet(c *gin.Context) {
+	var input struct {
+		Title       *string                `json:"title"`
+		Description *string                `json:"description"`
+		Visibility  *models.VisibilityType `json:"visibility"`
+		Tags        []string               `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	// Editors may change content; only the owner decides who can see it
+	required := accessEdit
+	if input.Visibility != nil {
+		required = accessOwn
+	}
+	set, ok := h.authorize(c, required)
+	if !ok {
+		return
+	}
+
+	if input.Title != nil {
+		if *input.Title == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Title cannot be empty"})
+			return
+		}
+		set.Title = *input.Title
+	}
+	if input.Description != nil {
+		set.Description = *input.Description
+	}
+	if input.Visibility != nil {
+		if !input.Visibility.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid visibility"})
+			return
+		}
+		set.Visibility = *input.Visibility
+	}
+	if input.Tags != nil {
+		set.Tags = models.NormalizeTags(input.Tags)
+	}
+
+	if err := h.repo.UpdateStudySet(c.Request.Context(), set); err != nil {
+		h.writeError(c, err, "Failed to update study set")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    set,
+		"success": true,
+	})
+}
+
+// DeleteStudySet handles DELETE /api/study-sets/:id
+func (h *StudySetHandler) DeleteStudySet(c *gin.Context) {
+	set, ok := h.authorize(c, accessOwn)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteStudySet(c.Request.Context(), set.ID); err != nil {
+		h.writeError(c, err, "Failed to delete study set")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListContentItems handles GET /api/study-sets/:id/items
+func (h *StudySetHandler) ListContentItems(c *gin.Context) {
+	set, ok := h.authorize(c, accessView)
+	if !ok {
+		return
+	}
+
+	items, err := h.repo.ListContentItems(c.Request.Context(), set.ID)
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch study set items")
+		return
+	}
+	if items == nil {
+		items = []*models.ContentItem{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    items,
+		"success": true,
+	})
+}
+
+// AddContentItem handles POST /api/study-sets/:id/items
+func (h *StudySetHandler) AddContentItem(c *gin.Context) {
+	set, ok := h.authorize(c, accessEdit)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		ContentType models.ContentType `json:"contentType" binding:"required"`
+		Question    string             `json:"question" binding:"required"`
+		Answer      string             `json:"answer"`
+		Hints       []string           `json:"hints"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if !input.ContentType.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid content type"})
+		return
+	}
+
+	item := models.NewContentItem(set.ID, input.ContentType, input.Question, input.Answer, input.Hints)
+	if err := h.repo.AddContentItem(c.Request.Context(), item); err != nil {
+		h.writeError(c, err, "Failed to add study set item")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    item,
+		"success": true,
+	})
+}
+
+// UpdateContentItem handles PATCH /api/study-sets/:id/items/:itemId
+func (h *StudySetHandler) UpdateContentItem(c *gin.Context) {
+	set, ok := h.authorize(c, accessEdit)
+	if !ok {
+		return
+	}
+
+	item, ok := h.fetchItem(c, set)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		ContentType *models.ContentType `json:"contentType"`
+		Question    *string             `json:"question"`
+		Answer      *string             `json:"answer"`
+		Hints       []string            `json:"hints"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if input.ContentType != nil {
+		if !input.ContentType.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid content type"})
+			return
+		}
+		item.ContentType = *input.ContentType
+	}
+	if input.Question != nil {
+		if *input.Question == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Question cannot be empty"})
+			return
+		}
+		item.Question = *input.Question
+	}
+	if input.Answer != nil {
+		item.Answer = *input.Answer
+	}
+	if input.Hints != nil {
+		item.Hints = input.Hints
+	}
+
+	if err := h.repo.UpdateContentItem(c.Request.Context(), item); err != nil {
+		h.writeError(c, err, "Failed to update study set item")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    item,
+		"success": true,
+	})
+}
+
+// DeleteContentItem handles DELETE /api/study-sets/:id/items/:itemId
+func (h *StudySetHandler) DeleteContentItem(c *gin.Context) {
+	set, ok := h.authorize(c, accessEdit)
+	if !ok {
+		return
+	}
+
+	item, ok := h.fetchItem(c, set)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteContentItem(c.Request.Context(), item.ID); err != nil {
+		h.writeError(c, err, "Failed to delete study set item")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListSharedAccess handles GET /api/study-sets/:id/shares
+func (h *StudySetHandler) ListSharedAccess(c *gin.Context) {
+	set, ok := h.authorize(c, accessOwn)
+	if !ok {
+		return
+	}
+
+	grants, err := h.repo.ListSharedAccess(c.Request.Context(), set.ID)
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch study set shares")
+		return
+	}
+	if grants == nil {
+		grants = []*models.SharedAccess{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    grants,
+		"success": true,
+	})
+}
+
+// ShareStudySet handles PUT /api/study-sets/:id/shares/:userId
+func (h *StudySetHandler) ShareStudySet(c *gin.Context) {
+	set, ok := h.authorize(c, accessOwn)
+	if !ok {
+		return
+	}
+
+	userId, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if userId == set.OwnerID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Study set owner already has full access"})
+		return
+	}
+
+	var input struct {
+		AccessType models.AccessType `json:"accessType" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if !input.AccessType.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid access type"})
+		return
+	}
+
+	access := models.NewSharedAccess(set.ID, userId, input.AccessType)
+	if err := h.repo.ShareStudySet(c.Request.Context(), access); err != nil {
+		h.writeError(c, err, "Failed to share study set")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    access,
+		"success": true,
+	})
+}
+
+// RevokeAccess handles DELETE /api/study-sets/:id/shares/:userId
+func (h *StudySetHandler) RevokeAccess(c *gin.Context) {
+	set, ok := h.authorize(c, accessOwn)
+	if !ok {
+		return
+	}
+
+	userId, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.repo.RevokeAccess(c.Request.Context(), set.ID, userId); err != nil {
+		h.writeError(c, err, "Failed to revoke study set access")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// authorize loads the study set named by the :id parameter and checks that
+// the caller has at least the required access, writing the error response
+// when they do not. Sets the caller cannot see are reported as not found.
+func (h *StudySetHandler) authorize(c *gin.Context, required accessLevel) (*models.StudySet, bool) {
+	setId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid study set ID"})
+		return nil, false
+	}
+
+	set, err := h.repo.GetStudySet(c.Request.Context(), setId)
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch study set")
+		return nil, false
+	}
+
+	access, err := h.accessFor(c, set)
+	if err != nil {
+		log.Printf("Error checking access to study set %s: %v", setId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch study set"})
+		return nil, false
+	}
+
+	if access < required {
+		denyAccess(c, access, "Study set not found", "Not allowed to modify this study set")
+		return nil, false
+	}
+	return set, true
+}
+
+// accessFor works out the caller's access to a study set from its owner,
+// visibility and any share grant
+func (h *StudySetHandler) accessFor(c *gin.Context, set *models.StudySet) (accessLevel, error) {
+	access, needsGrant := baseAccess(middleware.CallerFrom(c), set.OwnerID, set.Visibility)
+	if !needsGrant {
+		return access, nil
+	}
+
+	grant, err := h.repo.GetAccess(c.Request.Context(), set.ID, middleware.CallerFrom(c).UserID)
+	if err == repository.ErrSharedAccessNotFound {
+		return access, nil
+	}
+	if err != nil {
+		return accessNone, err
+	}
+	return grantedAccess(grant.AccessType), nil
+}
+
+// fetchItem loads the item named by the :itemId parameter, which must belong to set
+func (h *StudySetHandler) fetchItem(c *gin.Context, set *models.StudySet) (*models.ContentItem, bool) {
+	itemId, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return nil, false
+	}
+
+	item, err := h.repo.GetContentItem(c.Request.Context(), itemId)
+	if err == nil && item.StudySetID != set.ID {
+		err = repository.ErrContentItemNotFound
+	}
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch study set item")
+		return nil, false
+	}
+	return item, true
+}
+
+// writeError maps repository errors to HTTP responses
+func (h *StudySetHandler) writeError(c *gin.Context, err error, message string) {
+	switch err {
+	case repository.ErrStudySetNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Study set not found"})
+	case repository.ErrContentItemNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Study set item not found"})
+	case repository.ErrSharedAccessNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "User has no access to share"})
+	default:
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+)
+
+// TopicHandler handles HTTP requests for the topic taxonomy
+type TopicHandler struct {
+	repo        repository.TopicRepository
+	contentRepo repository.ContentRepository
+}
+
+// NewTopicHandler creates a new TopicHandler instance
+func NewTopicHandler(repo repository.TopicRepository, contentRepo repository.ContentRepository) *TopicHandler {
+	return &TopicHandler{repo: repo, contentRepo: contentRepo}
+}
+
+// ListTopics handles GET /api/topics
+func (h *TopicHandler) ListTopics(c *gin.Context) {
+	topics, err := h.repo.ListTopics(c.Request.Context(), viewerFrom(c))
+	if err != nil {
+		log.Printf("Error listing topics: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch topics"})
+		return
+	}
+	if topics == nil {
+		topics = []*models.Topic{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    topics,
+		"success": true,
+	})
+}
+
+// GetTopic handles GET /api/topics/:id. The topic may be named by ID or slug.
+func (h *TopicHandler) GetTopic(c *gin.Context) {
+	topic, ok := h.fetchTopic(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    topic,
+		"success": true,
+	})
+}
+
+// ListTopicQuizzes handles GET /api/topics/:id/quizzes.
+// Quizzes filed under subtopics are included.
+func (h *TopicHandler) ListTopicQuizzes(c *gin.Context) {
+	topic, ok := h.fetchTopic(c)
+	if !ok {
+		return
+	}
+
+	page := 1
+	pageSize := 10
+
+	// Parse pagination parameters if provided
+	if p := c.Query("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil && val > 0 {
+			page = val
+		}
+	}
+	if ps := c.Query("pageSize"); ps != "" {
+		if val, err := strconv.Atoi(ps); err == nil && val > 0 {
+			pageSize = val
+		}
+	}
+
+	quizzes, err := h.contentRepo.ListTopicQuizzes(c.Request.Context(), viewerFrom(c), topic.ID, page, pageSize)
+	if err != nil {
+		log.Printf("Error listing quizzes of topic %s: %v", topic.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quizzes"})
+		return
+	}
+	if quizzes == nil {
+		quizzes = []*models.Quiz{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       quizzes,
+		"totalCount": topic.TotalQuizCount,
+		"page":       page,
+		"pageSize":   pageSize,
+		"success":    true,
+	})
+}
+
+// CreateTopic handles POST /api/topics. The caller becomes the topic's creator.
+func (h *TopicHandler) CreateTopic(c *gin.Context) {
+	caller := middleware.CallerFrom(c)
+	if !caller.Authenticated() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var input struct {
+		Name        string     `json:"name" binding:"required"`
+		Slug        string     `json:"slug"`
+		Description string     `json:"description"`
+		ParentID    *uuid.UUID `json:"parentId"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	topic := models.NewTopic(input.Name, input.Slug, input.Description, input.ParentID)
+	topic.CreatedBy = &caller.UserID
+	if !models.ValidSlug(topic.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug must be lowercase letters and digits separated by hyphens"})
+		return
+	}
+
+	if err := h.repo.CreateTopic(c.Request.Context(), topic); err != nil {
+		h.writeError(c, err, "Failed to create topic")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    topic,
+		"success": true,
+	})
+}
+
+// UpdateTopic handles PATCH /api/topics/:id. Only the topic's creator, an
+// admin or a trusted service may update it.
+func (h *TopicHandler) UpdateTopic(c *gin.Context) {
+	if caller := middleware.CallerFrom(c); !caller.Authenticated() && !caller.Trusted {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var input struct {
+		Name        *string `json:"name"`
+		Slug        *string `json:"slug"`
+		Description *string `json:"description"`
+		ParentID    *string `json:"parentId"` // "" moves the topic to the top level
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	topic, ok := h.fetchManagedTopic(c)
+	if !ok {
+		return
+	}
+
+	// Only update fields that were provided
+	if input.Name != nil {
+		topic.Name = *input.Name
+	}
+	if input.Slug != nil {
+		if !models.ValidSlug(*input.Slug) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Slug must be lowercase letters and digits separated by hyphens"})
+			return
+		}
+		topic.Slug = *input.Slug
+	}
+	if input.Description != nil {
+		topic.Description = *input.Description
+	}
+	if input.ParentID != nil {
+		topic.ParentID = nil
+		if *input.ParentID != "" {
+			parentId, err := uuid.Parse(*input.ParentID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent topic ID"})
+				return
+			}
+			topic.ParentID = &parentId
+		}
+	}
+
+	if err := h.repo.UpdateTopic(c.Request.Context(), topic); err != nil {
+		h.writeError(c, err, "Failed to update topic")
+		return
+	}
+
+	// Return the updated topic by ID, since the slug may have changed
+	updated, err := h.repo.GetTopic(c.Request.Context(), viewerFrom(c), topic.ID)
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch topic")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updated,
+		"success": true,
+	})
+}
+
+// DeleteTopic handles DELETE /api/topics/:id.
+// Topics with subtopics cannot be deleted; their quizzes become uncategorized.
+// Like updates, deletes are limited to the creator, admins and trusted services.
+func (h *TopicHandler) DeleteTopic(c *gin.Context) {
+	if caller := middleware.CallerFrom(c); !caller.Authenticated() && !caller.Trusted {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	topic, ok := h.fetchManagedTopic(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteTopic(c.Request.Context(), topic.ID); err != nil {
+		h.writeError(c, err, "Failed to delete topic")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// fetchTopic loads the topic named by the :id parameter, which may be a
+// topic ID or a slug, writing the error response when it fails
+func (h *TopicHandler) fetchTopic(c *gin.Context) (*models.Topic, bool) {
+	var (
+		topic *models.Topic
+		err   error
+	)
+	if id, parseErr := uuid.Parse(c.Param("id")); parseErr == nil {
+		topic, err = h.repo.GetTopic(c.Request.Context(), viewerFrom(c), id)
+	} else {
+		topic, err = h.repo.GetTopicBySlug(c.Request.Context(), viewerFrom(c), c.Param("id"))
+	}
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch topic")
+		return nil, false
+	}
+	return topic, true
+}
+
+// fetchManagedTopic loads the topic named by the :id parameter and checks that
+// the caller may change it, writing the error response when they may not.
+// Topics are shared by every author, so only their creator, an admin or a
+// trusted service may.
+func (h *TopicHandler) fetchManagedTopic(c *gin.Context) (*models.Topic, bool) {
+	topic, ok := h.fetchTopic(c)
+	if !ok {
+		return nil, false
+	}
+
+	caller := middleware.CallerFrom(c)
+	owner := topic.CreatedBy != nil && *topic.CreatedBy == caller.UserID
+	if !caller.Trusted && !caller.Admin && !owner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to modify this topic"})
+		return nil, false
+	}
+	return topic, true
+}
+
+// writeError maps repository errors to HTTP responses
+func (h *TopicHandler) writeError(c *gin.Context, err error, message string) {
+	switch err {
+	case repository.ErrTopicNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Topic not found"})
+	case repository.ErrTopicSlugTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": "Slug is already used by another topic"})
+	case repository.ErrTopicHasChildren:
+		c.JSON(http.StatusConflict, gin.H{"error": "Topic has subtopics; move or delete them first"})
+	case repository.ErrTopicCycle:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A topic cannot be moved under itself or its subtopics"})
+	case repository.ErrInvalidInput:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parent topic does not exist"})
+	default:
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
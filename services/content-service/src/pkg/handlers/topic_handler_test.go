@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"QuizApp/services/content-service/src/pkg/middleware"
+	"QuizApp/services/content-service/src/pkg/models"
+	"QuizApp/services/content-service/src/pkg/repository"
+)
+
+// fakeTopicRepo keeps a single topic in memory and records whether a write reached it
+type fakeTopicRepo struct {
+	repository.TopicRepository
+	topic   *models.Topic
+	changed bool
+}
+
+func (r *fakeTopicRepo) GetTopic(ctx context.Context, viewer repository.Viewer, id uuid.UUID) (*models.Topic, error) {
+	if r.topic == nil || r.topic.ID != id {
+		return nil, repository.ErrTopicNotFound
+	}
+	topic := *r.topic
+	return &topic, nil
+}
+
+func (r *fakeTopicRepo) UpdateTopic(ctx context.Context, topic *models.Topic) error {
+	r.changed = true
+	r.topic = topic
+	return nil
+}
+
+func (r *fakeTopicRepo) DeleteTopic(ctx context.Context, id uuid.UUID) error {
+	r.changed = true
+	r.topic = nil
+	return nil
+}
+
+func TestTopicChangesNeedCreator(t *testing.T) {
+	t.Setenv("INTERNAL_SERVICE_TOKEN", "service-token")
+	creator := uuid.New()
+
+	tests := []struct {
+		name    string
+		user    uuid.UUID
+		headers map[string]string
+		want    int
+	}{
+		{name: "creator", user: creator, want: http.StatusOK},
+		{name: "admin", user: uuid.New(), headers: map[string]string{middleware.RoleHeader: middleware.RoleAdmin}, want: http.StatusOK},
+		{name: "trusted service", headers: map[string]string{middleware.ServiceTokenHeader: "service-token"}, want: http.StatusOK},
+		{name: "other user", user: uuid.New(), want: http.StatusForbidden},
+		{name: "anonymous", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, method := range []string{http.MethodPatch, http.MethodDelete} {
+				topic := models.NewTopic("Geography", "", "", nil)
+				topic.CreatedBy = &creator
+				repo := &fakeTopicRepo{topic: topic}
+
+				gin.SetMode(gin.TestMode)
+				h := NewTopicHandler(repo, nil)
+				r := gin.New()
+				r.Use(middleware.Identity())
+				r.PATCH("/topics/:id", h.UpdateTopic)
+				r.DELETE("/topics/:id", h.DeleteTopic)
+
+				code, _ := send(t, r, tt.user, method, "/topics/"+topic.ID.String(), `{"name": "World geography"}`, tt.headers)
+				assert.Equal(t, tt.want, code, method)
+				assert.Equal(t, tt.want == http.StatusOK, repo.changed, method)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package media checks uploaded images and audio and makes image thumbnails
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// Upload limits per kind of media
+const (
+	MaxImageSize = 5 << 20
+	MaxAudioSize = 20 << 20
+
+	// MaxUploadSize is the largest upload of any kind
+	MaxUploadSize = MaxAudioSize
+
+	// MaxImagePixels bounds image dimensions, since small files can
+	// decompress into huge images
+	MaxImagePixels = 40000000
+
+	// ThumbnailSize bounds the width and height of image thumbnails
+	ThumbnailSize = 256
+)
+
+// ContentTypes maps every accepted MIME type to its kind of media
+var ContentTypes = map[string]models.MediaKind{
+	"image/png":  models.MediaImage,
+	"image/jpeg": models.MediaImage,
+	"image/gif":  models.MediaImage,
+	"image/webp": models.MediaImage,
+	"audio/mpeg": models.MediaAudio,
+	"audio/wave": models.MediaAudio,
+	"audio/ogg":  models.MediaAudio,
+}
+
+var (
+	// ErrUnsupportedType is returned for files that are not an accepted image or audio type
+	ErrUnsupportedType = errors.New("unsupported media type")
+
+	// ErrTooLarge is returned for files over their kind's size limit
+	ErrTooLarge = errors.New("media file too large")
+)
+
+// File is a checked upload, ready to store
+type File struct {
+	Media     models.Media
+	Thumbnail []byte
+}
+
+// Key is the blob store key of a media file
+func Key(id uuid.UUID) string {
+	return "media/" + id.String() + "/original"
+}
+
+// ThumbnailKey is the blob store key of an image's thumbnail
+func ThumbnailKey(id uuid.UUID) string {
+	return "media/" + id.String() + "/thumbnail"
+}
+
+// Inspect checks an uploaded file and describes it as media owned by
+// ownerID. The type is detected from the content, not taken from the
+// client. Images that the standard decoders read get their dimensions and a
+// JPEG thumbnail.
+func Inspect(data []byte, ownerID uuid.UUID) (*File, error) {
+	contentType := detectContentType(data)
+	kind, ok := ContentTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	limit := int64(MaxAudioSize)
+	if kind == models.MediaImage {
+		limit = MaxImageSize
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: %s files may be at most %d MB", ErrTooLarge, kind, limit>>20)
+	}
+
+	file := &File{Media: models.Media{
+		ID:          uuid.New(),
+		OwnerID:     ownerID,
+		Kind:        kind,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}}
+	if kind != models.MediaImage {
+		return file, nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err == image.ErrFormat {
+		// WebP has no standard decoder; keep it without a thumbnail
+		return file, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: corrupt image: %v", ErrUnsupportedType, err)
+	}
+	if config.Width*config.Height > MaxImagePixels {
+		return nil, fmt.Errorf("%w: images may be at most %d megapixels", ErrTooLarge, MaxImagePixels/1000000)
+	}
+	file.Media.Width = config.Width
+	file.Media.Height = config.Height
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: corrupt image: %v", ErrUnsupportedType, err)
+	}
+
+	var thumbnail bytes.Buffer
+	if err := jpeg.Encode(&thumbnail, Thumbnail(img, ThumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, fmt.Errorf("error encoding thumbnail: %v", err)
+	}
+	file.Thumbnail = thumbnail.Bytes()
+	file.Media.HasThumbnail = true
+	return file, nil
+}
+
+// detectContentType sniffs a file's MIME type, naming Ogg files as audio
+func detectContentType(data []byte) string {
+	contentType := http.DetectContentType(data)
+	if contentType == "application/ogg" {
+		return "audio/ogg"
+	}
+	return contentType
+}
+
+// Thumbnail scales an image down to fit within size by size pixels, keeping
+// its aspect ratio, by averaging the source pixels behind each output pixel.
+// Images that already fit are copied as they are. Transparent areas come out
+// white, since thumbnails are JPEG.
+func Thumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/bounds.Dx())
+		} else {
+			width, height = max(1, width*size/bounds.Dy()), size
+		}
+	}
+
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, src, bounds.Min, draw.Over)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					offset := flat.PixOffset(sx, sy)
+					r += uint32(flat.Pix[offset])
+					g += uint32(flat.Pix[offset+1])
+					b += uint32(flat.Pix[offset+2])
+					n++
+				}
+			}
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = 0xff
+		}
+	}
+	return dst
+}
@@ -0,0 +1,85 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 10, B: 10, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestInspectImage(t *testing.T) {
+	owner := uuid.New()
+	file, err := Inspect(encodePNG(t, 1024, 512), owner)
+	require.NoError(t, err)
+
+	assert.Equal(t, owner, file.Media.OwnerID)
+	assert.Equal(t, models.MediaImage, file.Media.Kind)
+	assert.Equal(t, "image/png", file.Media.ContentType)
+	assert.Equal(t, 1024, file.Media.Width)
+	assert.Equal(t, 512, file.Media.Height)
+	assert.True(t, file.Media.HasThumbnail)
+
+	thumbnail, err := jpeg.Decode(bytes.NewReader(file.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 256, 128), thumbnail.Bounds())
+	r, g, b, _ := thumbnail.At(100, 50).RGBA()
+	assert.InDelta(t, 200, r>>8, 10)
+	assert.InDelta(t, 10, g>>8, 10)
+	assert.InDelta(t, 10, b>>8, 10)
+}
+
+func TestInspectAudio(t *testing.T) {
+	data := append([]byte("ID3"), make([]byte, 64)...)
+	file, err := Inspect(data, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, models.MediaAudio, file.Media.Kind)
+	assert.Equal(t, "audio/mpeg", file.Media.ContentType)
+	assert.False(t, file.Media.HasThumbnail)
+	assert.Nil(t, file.Thumbnail)
+}
+
+func TestInspectRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("<html><script>alert(1)</script></html>"), ErrUnsupportedType},
+		{"pdf", []byte("%PDF-1.4 ..."), ErrUnsupportedType},
+		{"truncated png", encodePNG(t, 10, 10)[:40], ErrUnsupportedType},
+		{"large audio", append([]byte("ID3"), make([]byte, MaxAudioSize)...), ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Inspect(tt.data, uuid.New())
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestThumbnailSize(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 40, 300))
+	assert.Equal(t, image.Rect(0, 0, 34, 256), Thumbnail(src, 256).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 40, 30), Thumbnail(image.NewRGBA(image.Rect(0, 0, 40, 30)), 256).Bounds())
+}
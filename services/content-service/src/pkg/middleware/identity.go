@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// UserIDHeader carries the authenticated user's ID, set by the API gateway
+	UserIDHeader = "X-User-ID"
+
+	// ServiceTokenHeader carries the shared secret used by trusted internal services
+	ServiceTokenHeader = "X-Service-Token"
+
+	// RoleHeader carries the authenticated user's role, set by the API gateway
+	RoleHeader = "X-User-Role"
+
+	// RoleAdmin is the role of users who administer shared content such as topics
+	RoleAdmin = "admin"
+
+	callerKey = "caller"
+)
+
+// Caller identifies who a request is made on behalf of
+type Caller struct {
+	UserID  uuid.UUID
+	Trusted bool
+	Admin   bool
+}
+
+// Authenticated reports whether the request carried a user identity
+func (c Caller) Authenticated() bool {
+	return c.UserID != uuid.Nil
+}
+
+// Identity resolves the caller from request headers and stores it on the context.
+// Requests are trusted only when INTERNAL_SERVICE_TOKEN is configured and matched.
+func Identity() gin.HandlerFunc {
+	serviceToken := os.Getenv("INTERNAL_SERVICE_TOKEN")
+
+	return func(c *gin.Context) {
+		var caller Caller
+		if userID, err := uuid.Parse(c.GetHeader(UserIDHeader)); err == nil {
+			caller.UserID = userID
+			caller.Admin = c.GetHeader(RoleHeader) == RoleAdmin
+		}
+		if serviceToken != "" {
+			token := c.GetHeader(ServiceTokenHeader)
+			caller.Trusted = subtle.ConstantTimeCompare([]byte(token), []byte(serviceToken)) == 1
+		}
+
+		c.Set(callerKey, caller)
+		c.Next()
+	}
+}
+
+// CallerFrom returns the caller resolved by Identity, or an anonymous caller
+func CallerFrom(c *gin.Context) Caller {
+	if value, ok := c.Get(callerKey); ok {
+		if caller, ok := value.(Caller); ok {
+			return caller
+		}
+	}
+	return Caller{}
+}
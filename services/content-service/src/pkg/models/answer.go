@@ -0,0 +1,110 @@
+package models
+
+import "regexp"
+
+// ToleranceType selects how a numeric answer's tolerance is applied
+type ToleranceType string
+
+const (
+	// ToleranceAbsolute accepts answers within Tolerance of the value
+	ToleranceAbsolute ToleranceType = "absolute"
+	// ToleranceRelative accepts answers within Tolerance times the value,
+	// e.g. 0.01 for 1%
+	ToleranceRelative ToleranceType = "relative"
+)
+
+// NumericAnswer is the answer key of a numeric question, e.g. 9.81 ± 0.05 m/s².
+// Answers may be given in Unit or in any of the accepted Units; a bare number
+// is taken to be in Unit unless UnitRequired is set.
+type NumericAnswer struct {
+	Value         float64       `json:"value"`
+	Tolerance     float64       `json:"tolerance,omitempty"`
+	ToleranceType ToleranceType `json:"toleranceType,omitempty"` // defaults to absolute
+	Unit          string        `json:"unit,omitempty"`
+	Units         []NumericUnit `json:"units,omitempty"`
+	UnitRequired  bool          `json:"unitRequired,omitempty"`
+}
+
+// NumericUnit is another unit a numeric answer may be given in. Multiplier
+// converts a value in this unit to the answer's Unit: with Unit "m", the unit
+// "cm" has Multiplier 0.01.
+type NumericUnit struct {
+	Unit       string  `json:"unit"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// Valid reports whether t is a known tolerance type
+func (t ToleranceType) Valid() bool {
+	return t == ToleranceAbsolute || t == ToleranceRelative
+}
+
+// BlankKind selects how a learner fills in a cloze blank
+type BlankKind string
+
+const (
+	// BlankText blanks are typed in
+	BlankText BlankKind = "text"
+	// BlankDropdown blanks are chosen from the blank's options
+	BlankDropdown BlankKind = "dropdown"
+)
+
+// ClozeBlankMarkup matches the placeholder of a cloze blank in question
+// text: {{1}} for the first blank, {{2}} for the second and so on
+var ClozeBlankMarkup = regexp.MustCompile(`\{\{\s*(\d+)\s*\}\}`)
+
+// ClozeBlank is one blank of a cloze question and the answers it accepts.
+// Answers match ignoring surrounding and repeated whitespace, and also case
+// or accents when IgnoreCase or IgnoreAccents is set.
+type ClozeBlank struct {
+	Kind          BlankKind `json:"kind"`
+	Options       []string  `json:"options,omitempty"` // choices of a dropdown blank
+	Answers       []string  `json:"answers,omitempty"`
+	IgnoreCase    bool      `json:"ignoreCase,omitempty"`
+	IgnoreAccents bool      `json:"ignoreAccents,omitempty"`
+}
+
+// Valid reports whether k is a known blank kind
+func (k BlankKind) Valid() bool {
+	return k == BlankText || k == BlankDropdown
+}
+
+// MatchRule selects how an open-ended answer is compared with an accepted
+// answer. Surrounding whitespace is always ignored.
+type MatchRule string
+
+const (
+	// MatchExact accepts the answer character for character
+	MatchExact MatchRule = "exact"
+	// MatchCaseInsensitive ignores case and repeated whitespace, the way
+	// CorrectAnswer is compared
+	MatchCaseInsensitive MatchRule = "case_insensitive"
+	// MatchWhitespaceNormalized ignores repeated whitespace but not case
+	MatchWhitespaceNormalized MatchRule = "whitespace_normalized"
+	// MatchRegex accepts answers the regular expression matches in full
+	MatchRegex MatchRule = "regex"
+	// MatchLevenshtein accepts answers within MaxDistance edits, ignoring
+	// case and repeated whitespace
+	MatchLevenshtein MatchRule = "levenshtein"
+)
+
+// MatchRules lists every match rule
+var MatchRules = []MatchRule{MatchExact, MatchCaseInsensitive, MatchWhitespaceNormalized, MatchRegex, MatchLevenshtein}
+
+// AcceptedAnswer is one answer an open-ended question accepts and how
+// learners' answers are compared with it. For MatchRegex, Answer is the
+// pattern.
+type AcceptedAnswer struct {
+	Answer      string    `json:"answer"`
+	Match       MatchRule `json:"match"`
+	MaxDistance int       `json:"maxDistance,omitempty"` // for MatchLevenshtein
+}
+
+// Valid reports whether r is a known match rule
+func (r MatchRule) Valid() bool {
+	for _, rule := range MatchRules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
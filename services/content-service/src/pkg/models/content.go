@@ -1,6 +1,8 @@
 package models
 
 import (
+	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +17,18 @@ type VisibilityType string
 // QuestionType represents the type of question
 type QuestionType string
 
+// ScoringMode selects how answers to questions with several parts earn credit
+type ScoringMode string
+
+// GradingMode selects who grades answers to an open-ended question
+type GradingMode string
+
+// AccessType represents the level of access granted to another user
+type AccessType string
+
+// QuizView selects which projection of a quiz is exposed to a caller
+type QuizView string
+
 const (
 	// Content types
 	ContentTypeFlashcard ContentType = "flashcard"
@@ -26,35 +40,110 @@ const (
 	VisibilityPublic  VisibilityType = "public"
 	VisibilityShared  VisibilityType = "shared"
 
+	// Access types
+	AccessViewer AccessType = "viewer"
+	AccessEditor AccessType = "editor"
+
 	// Question types
 	QuestionTypeMultipleChoice QuestionType = "multiple_choice"
 	QuestionTypeTrueFalse     QuestionType = "true_false"
 	QuestionTypeOpenEnded     QuestionType = "open_ended"
+	QuestionTypeMultipleSelect QuestionType = "multiple_select"
+	QuestionTypeNumeric        QuestionType = "numeric"
+	QuestionTypeMatching       QuestionType = "matching"
+	QuestionTypeOrdering       QuestionType = "ordering"
+	QuestionTypeCloze          QuestionType = "cloze"
+
+	// Scoring modes
+	ScoringAllOrNothing  ScoringMode = "all_or_nothing"
+	ScoringPartialCredit ScoringMode = "partial_credit"
+
+	// Grading modes
+	GradingAuto   GradingMode = "auto"
+	GradingManual GradingMode = "manual"
+	GradingAI     GradingMode = "ai"
+
+	// Quiz views
+	QuizViewTaker  QuizView = "taker"
+	QuizViewAuthor QuizView = "author"
 )
 
-// Quiz represents a quiz with questions
+// QuestionTypes lists every supported question type
+var QuestionTypes = []QuestionType{
+	QuestionTypeMultipleChoice,
+	QuestionTypeTrueFalse,
+	QuestionTypeOpenEnded,
+	QuestionTypeMultipleSelect,
+	QuestionTypeNumeric,
+	QuestionTypeMatching,
+	QuestionTypeOrdering,
+	QuestionTypeCloze,
+}
+
+// Quiz represents a quiz with questions. Besides its own Questions, a quiz
+// may draw random questions from question pools for every attempt. Its
+// Settings control how attempts at it are delivered and graded. Listings
+// also count its questions and the attempts learners have started.
 type Quiz struct {
-	ID          uuid.UUID    `json:"id"`
-	Title       string       `json:"title"`
-	Description string       `json:"description"`
-	TopicID     *uuid.UUID   `json:"topicId,omitempty"`
-	CreatorID   uuid.UUID    `json:"creatorId"`
-	Questions   []*Question  `json:"questions,omitempty"`
-	CreatedAt   time.Time    `json:"createdAt"`
-	UpdatedAt   time.Time    `json:"updatedAt"`
+	ID          uuid.UUID      `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	TopicID     *uuid.UUID     `json:"topicId,omitempty"`
+	CreatorID   uuid.UUID      `json:"creatorId"`
+	Visibility  VisibilityType `json:"visibility"`
+	Version     int            `json:"version"`
+	RevisionID  *uuid.UUID     `json:"revisionId,omitempty"`
+	Questions   []*Question    `json:"questions,omitempty"`
+	Draws       []PoolDraw     `json:"draws,omitempty"`
+	Settings    QuizSettings   `json:"settings"`
+	QuestionCount int          `json:"questionCount,omitempty"`
+	AttemptCount  int          `json:"attemptCount,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
 }
 
-// Question represents a quiz question
+// Question represents a quiz question. Multiple select questions keep their
+// answer key in CorrectAnswers and numeric questions in Numeric; the other
+// types use CorrectAnswer.
+//
+// Matching questions pair each of the Prompts with one of the Options;
+// Matches holds the option for each prompt, in the same order. Ordering
+// questions show their Options in any order and keep the right sequence in
+// CorrectOrder. Cloze questions mark their blanks in Text as {{1}}, {{2}}
+// and so on, and describe each blank in Blanks. Open-ended questions may
+// list AcceptedAnswers with their own match rules; CorrectAnswer is then
+// only shown to learners, not graded. With manual Grading, answers that no
+// accepted answer matches are left for the quiz creator to grade; with AI
+// Grading, a model grades them against CorrectAnswer and the Rubric first.
+//
+// Media holds images and audio shown with the question; OptionMedia holds
+// one optional attachment per option, in the same order as Options.
+// Questions in a question pool have a PoolID instead of a QuizID.
 type Question struct {
-	ID            uuid.UUID    `json:"id"`
-	QuizID        uuid.UUID    `json:"quizId"`
-	Text          string       `json:"text"`
-	Type          QuestionType `json:"type"`
-	Options       []string     `json:"options"`
-	CorrectAnswer string       `json:"correctAnswer"`
-	Explanation   string       `json:"explanation,omitempty"`
-	CreatedAt     time.Time    `json:"createdAt"`
-	UpdatedAt     time.Time    `json:"updatedAt"`
+	ID              uuid.UUID        `json:"id"`
+	QuizID          uuid.UUID        `json:"quizId"`
+	PoolID          *uuid.UUID       `json:"poolId,omitempty"`
+	Position        int              `json:"position"`
+	Text            string           `json:"text"`
+	Type            QuestionType     `json:"type"`
+	Difficulty      Difficulty       `json:"difficulty,omitempty"`
+	Options         []string         `json:"options"`
+	Prompts         []string         `json:"prompts,omitempty"`
+	CorrectAnswer   string           `json:"correctAnswer,omitempty"`
+	CorrectAnswers  []string         `json:"correctAnswers,omitempty"`
+	Matches         []string         `json:"matches,omitempty"`
+	CorrectOrder    []string         `json:"correctOrder,omitempty"`
+	Scoring         ScoringMode      `json:"scoring,omitempty"`
+	Grading         GradingMode      `json:"grading,omitempty"`
+	Numeric         *NumericAnswer   `json:"numeric,omitempty"`
+	Blanks          []ClozeBlank     `json:"blanks,omitempty"`
+	AcceptedAnswers []AcceptedAnswer `json:"acceptedAnswers,omitempty"`
+	Rubric          string           `json:"rubric,omitempty"`
+	Media           []Attachment     `json:"media,omitempty"`
+	OptionMedia     []*Attachment    `json:"optionMedia,omitempty"`
+	Explanation     string           `json:"explanation,omitempty"`
+	CreatedAt       time.Time        `json:"createdAt"`
+	UpdatedAt       time.Time        `json:"updatedAt"`
 }
 
 // StudySet represents a collection of study content
@@ -65,6 +154,7 @@ type StudySet struct {
 	OwnerID     uuid.UUID      `json:"ownerId"`
 	Visibility  VisibilityType `json:"visibility"`
 	Tags        []string       `json:"tags,omitempty"`
+	Items       []*ContentItem `json:"items,omitempty"`
 	CreatedAt   time.Time      `json:"createdAt"`
 	UpdatedAt   time.Time      `json:"updatedAt"`
 }
@@ -83,10 +173,18 @@ type ContentItem struct {
 
 // SharedAccess represents shared access to a study set
 type SharedAccess struct {
-	StudySetID uuid.UUID `json:"studySetId"`
-	UserID     uuid.UUID `json:"userId"`
-	AccessType string    `json:"accessType"`
-	CreatedAt  time.Time `json:"createdAt"`
+	StudySetID uuid.UUID  `json:"studySetId"`
+	UserID     uuid.UUID  `json:"userId"`
+	AccessType AccessType `json:"accessType"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// QuizAccess grants a user access to a quiz shared with them
+type QuizAccess struct {
+	QuizID     uuid.UUID  `json:"quizId"`
+	UserID     uuid.UUID  `json:"userId"`
+	AccessType AccessType `json:"accessType"`
+	CreatedAt  time.Time  `json:"createdAt"`
 }
 
 // NewQuiz creates a new quiz
@@ -98,6 +196,7 @@ func NewQuiz(title, description string, creatorID uuid.UUID, topicID *uuid.UUID)
 		Description: description,
 		TopicID:     topicID,
 		CreatorID:   creatorID,
+		Visibility:  VisibilityPrivate,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -119,6 +218,66 @@ func NewQuestion(quizID uuid.UUID, text string, questionType QuestionType, optio
 	}
 }
 
+// ForTaker returns a copy of the quiz that is safe to show learners before
+// they complete an attempt: answer keys and explanations are removed.
+func (q *Quiz) ForTaker() *Quiz {
+	taker := *q
+	if q.Questions != nil {
+		taker.Questions = make([]*Question, len(q.Questions))
+		for i, question := range q.Questions {
+			taker.Questions[i] = question.ForTaker()
+		}
+	}
+	return &taker
+}
+
+// ForTaker returns a copy of the question without its answer key or
+// explanation. The options of matching and ordering questions are shuffled,
+// since authors often list them in answer order; their media moves with them.
+func (q *Question) ForTaker() *Question {
+	taker := *q
+	if q.Type == QuestionTypeMatching || q.Type == QuestionTypeOrdering {
+		order := rand.Perm(len(q.Options))
+		taker.Options = make([]string, len(order))
+		for i, j := range order {
+			taker.Options[i] = q.Options[j]
+		}
+		if q.OptionMedia != nil {
+			taker.OptionMedia = make([]*Attachment, len(order))
+			for i, j := range order {
+				if j < len(q.OptionMedia) {
+					taker.OptionMedia[i] = q.OptionMedia[j]
+				}
+			}
+		}
+	}
+	taker.CorrectAnswer = ""
+	taker.CorrectAnswers = nil
+	taker.Matches = nil
+	taker.CorrectOrder = nil
+	taker.Numeric = nil
+	taker.AcceptedAnswers = nil
+	taker.Rubric = ""
+	if q.Blanks != nil {
+		taker.Blanks = make([]ClozeBlank, len(q.Blanks))
+		for i, blank := range q.Blanks {
+			taker.Blanks[i] = ClozeBlank{Kind: blank.Kind, Options: blank.Options}
+		}
+	}
+	taker.Explanation = ""
+	return &taker
+}
+
+// NewQuizAccess creates a new quiz share grant
+func NewQuizAccess(quizID, userID uuid.UUID, accessType AccessType) *QuizAccess {
+	return &QuizAccess{
+		QuizID:     quizID,
+		UserID:     userID,
+		AccessType: accessType,
+		CreatedAt:  time.Now().UTC(),
+	}
+}
+
 // NewStudySet creates a new study set
 func NewStudySet(title, description string, ownerID uuid.UUID, visibility VisibilityType, tags []string) *StudySet {
 	now := time.Now().UTC()
@@ -128,7 +287,7 @@ func NewStudySet(title, description string, ownerID uuid.UUID, visibility Visibi
 		Description: description,
 		OwnerID:     ownerID,
 		Visibility:  visibility,
-		Tags:        tags,
+		Tags:        NormalizeTags(tags),
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -150,11 +309,69 @@ func NewContentItem(studySetID uuid.UUID, contentType ContentType, question, ans
 }
 
 // NewSharedAccess creates a new shared access record
-func NewSharedAccess(studySetID, userID uuid.UUID, accessType string) *SharedAccess {
+func NewSharedAccess(studySetID, userID uuid.UUID, accessType AccessType) *SharedAccess {
 	return &SharedAccess{
 		StudySetID: studySetID,
 		UserID:     userID,
 		AccessType: accessType,
 		CreatedAt:  time.Now().UTC(),
 	}
-} 
\ No newline at end of file
+}
+
+// Valid reports whether v is a known visibility
+func (v VisibilityType) Valid() bool {
+	switch v {
+	case VisibilityPrivate, VisibilityPublic, VisibilityShared:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether t is a known question type
+func (t QuestionType) Valid() bool {
+	for _, known := range QuestionTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid reports whether m is a known scoring mode
+func (m ScoringMode) Valid() bool {
+	return m == ScoringAllOrNothing || m == ScoringPartialCredit
+}
+
+// Valid reports whether m is a known grading mode
+func (m GradingMode) Valid() bool {
+	return m == GradingAuto || m == GradingManual || m == GradingAI
+}
+
+// Valid reports whether t is a known content type
+func (t ContentType) Valid() bool {
+	switch t {
+	case ContentTypeFlashcard, ContentTypeQuiz, ContentTypeNote:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether a is a known access type
+func (a AccessType) Valid() bool {
+	return a == AccessViewer || a == AccessEditor
+}
+
+// NormalizeTags lowercases and trims tags, dropping blanks and duplicates
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// answerKeys are the JSON keys that give away how a question is graded
+var answerKeys = []string{"correctAnswer", "correctAnswers", "matches", "correctOrder", "numeric", "acceptedAnswers", "rubric", "explanation"}
+
+func TestQuestionForTakerHidesAnswerKeys(t *testing.T) {
+	tests := map[QuestionType]*Question{
+		QuestionTypeMultipleChoice: {Options: []string{"Paris", "Rome"}, CorrectAnswer: "Paris"},
+		QuestionTypeTrueFalse:      {Options: []string{"true", "false"}, CorrectAnswer: "true"},
+		QuestionTypeOpenEnded: {
+			CorrectAnswer:   "Photosynthesis",
+			AcceptedAnswers: []AcceptedAnswer{{Answer: "photosynthesis", Match: MatchCaseInsensitive}},
+			Rubric:          "Mentions light",
+			Grading:         GradingManual,
+		},
+		QuestionTypeMultipleSelect: {Options: []string{"2", "3", "4"}, CorrectAnswers: []string{"2", "3"}},
+		QuestionTypeNumeric:        {Numeric: &NumericAnswer{Value: 9.81, Tolerance: 0.01, Unit: "m/s2"}},
+		QuestionTypeMatching:       {Prompts: []string{"France", "Italy"}, Options: []string{"Paris", "Rome"}, Matches: []string{"Paris", "Rome"}},
+		QuestionTypeOrdering:       {Options: []string{"1", "2", "3"}, CorrectOrder: []string{"1", "2", "3"}},
+		QuestionTypeCloze: {Blanks: []ClozeBlank{
+			{Kind: BlankText, Answers: []string{"Paris"}, IgnoreCase: true},
+			{Kind: BlankDropdown, Options: []string{"Seine", "Tiber"}, Answers: []string{"Seine"}},
+		}},
+	}
+
+	for _, questionType := range QuestionTypes {
+		t.Run(string(questionType), func(t *testing.T) {
+			question, ok := tests[questionType]
+			require.True(t, ok, "no test question for this type")
+			question.ID = uuid.New()
+			question.Text = "Question {{1}} {{2}}"
+			question.Type = questionType
+			question.Explanation = "Because"
+			before, err := json.Marshal(question)
+			require.NoError(t, err)
+
+			for name, taker := range map[string]*Question{
+				"question": question.ForTaker(),
+				"quiz":     (&Quiz{Questions: []*Question{question}}).ForTaker().Questions[0],
+			} {
+				encoded, err := json.Marshal(taker)
+				require.NoError(t, err)
+				var fields map[string]interface{}
+				require.NoError(t, json.Unmarshal(encoded, &fields))
+
+				for _, key := range answerKeys {
+					assert.NotContains(t, fields, key, name)
+				}
+				assert.Equal(t, question.Text, taker.Text, name)
+				assert.ElementsMatch(t, question.Options, taker.Options, name)
+				assert.Len(t, taker.Blanks, len(question.Blanks), name)
+				for i, blank := range taker.Blanks {
+					assert.Equal(t, ClozeBlank{Kind: question.Blanks[i].Kind, Options: question.Blanks[i].Options}, blank, name)
+				}
+			}
+
+			// The stored question keeps its answer key
+			after, err := json.Marshal(question)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(before), string(after))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MediaKind is the kind of an uploaded media file
+type MediaKind string
+
+const (
+	// Media kinds
+	MediaImage MediaKind = "image"
+	MediaAudio MediaKind = "audio"
+)
+
+// Media is an uploaded image or audio file. The file and, for images, its
+// thumbnail live in the blob store; see the media package for their keys.
+type Media struct {
+	ID           uuid.UUID `json:"id"`
+	OwnerID      uuid.UUID `json:"ownerId"`
+	Kind         MediaKind `json:"kind"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	HasThumbnail bool      `json:"hasThumbnail,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Attachment shows uploaded media with a question or one of its options.
+// Authors only send MediaID and AltText; the rest is copied from the media
+// when the question is saved. URL and ThumbnailURL are signed, time-limited
+// links filled in when the question is read.
+type Attachment struct {
+	MediaID      uuid.UUID `json:"mediaId"`
+	Kind         MediaKind `json:"kind,omitempty"`
+	ContentType  string    `json:"contentType,omitempty"`
+	Size         int64     `json:"size,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	HasThumbnail bool      `json:"hasThumbnail,omitempty"`
+	AltText      string    `json:"altText,omitempty"`
+	URL          string    `json:"url,omitempty"`
+	ThumbnailURL string    `json:"thumbnailUrl,omitempty"`
+}
+
+// Attach returns an attachment for the media with the given alt text
+func (m *Media) Attach(altText string) Attachment {
+	return Attachment{
+		MediaID:      m.ID,
+		Kind:         m.Kind,
+		ContentType:  m.ContentType,
+		Size:         m.Size,
+		Width:        m.Width,
+		Height:       m.Height,
+		HasThumbnail: m.HasThumbnail,
+		AltText:      altText,
+	}
+}
+
+// Attachments lists every attachment of the question and its options
+func (q *Question) Attachments() []*Attachment {
+	var attachments []*Attachment
+	for i := range q.Media {
+		attachments = append(attachments, &q.Media[i])
+	}
+	for _, attachment := range q.OptionMedia {
+		if attachment != nil {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments
+}
+
+// validateMedia checks a question's attachments: option media must line up
+// with the options, and images need alt text for learners who cannot see them
+func (q *Question) validateMedia(fail func(field, format string, args ...interface{})) {
+	check := func(field string, attachment *Attachment) {
+		if attachment.MediaID == uuid.Nil {
+			fail(field+".mediaId", "This field is required")
+		}
+		if attachment.Kind == MediaImage && strings.TrimSpace(attachment.AltText) == "" {
+			fail(field+".altText", "Describe the image for learners who cannot see it")
+		}
+	}
+
+	for i := range q.Media {
+		check(fmt.Sprintf("media[%d]", i), &q.Media[i])
+	}
+	if len(q.OptionMedia) > len(q.Options) {
+		fail("optionMedia", "Has %d entries but the question has %d options", len(q.OptionMedia), len(q.Options))
+	}
+	for i, attachment := range q.OptionMedia {
+		if attachment != nil {
+			check(fmt.Sprintf("optionMedia[%d]", i), attachment)
+		}
+	}
+}
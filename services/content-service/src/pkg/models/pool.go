@@ -0,0 +1,171 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Difficulty rates how hard a question is
+type Difficulty string
+
+const (
+	// Difficulties
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyMedium Difficulty = "medium"
+	DifficultyHard   Difficulty = "hard"
+)
+
+// MaxDrawCount bounds how many questions one draw may take from a pool
+const MaxDrawCount = 100
+
+// ErrNotEnoughQuestions is returned when a pool has fewer matching questions than a draw asks for
+var ErrNotEnoughQuestions = errors.New("not enough questions in pool")
+
+// QuestionPool is a reusable bank of questions, kept apart from any quiz.
+// Quizzes draw random questions from pools when an attempt starts.
+type QuestionPool struct {
+	ID            uuid.UUID      `json:"id"`
+	OwnerID       uuid.UUID      `json:"ownerId"`
+	Title         string         `json:"title"`
+	Description   string         `json:"description,omitempty"`
+	Visibility    VisibilityType `json:"visibility"`
+	QuestionCount int            `json:"questionCount"`
+	Questions     []*Question    `json:"questions,omitempty"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+}
+
+// PoolDraw asks for Count random questions from a pool. With Difficulties
+// set, only questions of those difficulties are drawn.
+type PoolDraw struct {
+	PoolID       uuid.UUID    `json:"poolId"`
+	Count        int          `json:"count"`
+	Difficulties []Difficulty `json:"difficulties,omitempty"`
+}
+
+// NewQuestionPool creates a new question pool
+func NewQuestionPool(title, description string, ownerID uuid.UUID, visibility VisibilityType) *QuestionPool {
+	now := time.Now().UTC()
+	return &QuestionPool{
+		ID:          uuid.New(),
+		OwnerID:     ownerID,
+		Title:       title,
+		Description: description,
+		Visibility:  visibility,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Valid reports whether d is a known difficulty
+func (d Difficulty) Valid() bool {
+	return d == DifficultyEasy || d == DifficultyMedium || d == DifficultyHard
+}
+
+// Validate checks a pool's own fields; its questions are validated one by one
+func (p *QuestionPool) Validate() []ValidationError {
+	var errs []ValidationError
+	if strings.TrimSpace(p.Title) == "" {
+		errs = append(errs, ValidationError{Field: "title", Error: "This field is required"})
+	}
+	if p.Visibility != VisibilityPrivate && p.Visibility != VisibilityPublic {
+		errs = append(errs, ValidationError{Field: "visibility", Error: fmt.Sprintf("Must be %s or %s", VisibilityPrivate, VisibilityPublic)})
+	}
+	return errs
+}
+
+// ValidateDraws checks a quiz's draw rules. Draws from the same pool must
+// ask for different difficulties, so that no question can be drawn twice
+// and whether a pool holds enough questions does not depend on the seed.
+func ValidateDraws(draws []PoolDraw) []ValidationError {
+	var errs []ValidationError
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Field: field, Error: fmt.Sprintf(format, args...)})
+	}
+
+	for i, draw := range draws {
+		field := fmt.Sprintf("draws[%d]", i)
+		if draw.PoolID == uuid.Nil {
+			fail(field+".poolId", "This field is required")
+		}
+		if draw.Count < 1 || draw.Count > MaxDrawCount {
+			fail(field+".count", "Must be between 1 and %d", MaxDrawCount)
+		}
+		for j, difficulty := range draw.Difficulties {
+			if !difficulty.Valid() {
+				fail(fmt.Sprintf("%s.difficulties[%d]", field, j), "Must be %s, %s or %s", DifficultyEasy, DifficultyMedium, DifficultyHard)
+			}
+		}
+		for j, earlier := range draws[:i] {
+			if earlier.PoolID == draw.PoolID && draw.overlaps(&earlier) {
+				fail(field+".difficulties", "Overlaps draws[%d], which draws from the same pool", j)
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// accepts reports whether the draw may pick a question of the given difficulty
+func (d *PoolDraw) accepts(difficulty Difficulty) bool {
+	if len(d.Difficulties) == 0 {
+		return true
+	}
+	for _, accepted := range d.Difficulties {
+		if accepted == difficulty {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether two draws may pick questions of the same difficulty
+func (d *PoolDraw) overlaps(other *PoolDraw) bool {
+	if len(d.Difficulties) == 0 || len(other.Difficulties) == 0 {
+		return true
+	}
+	for _, difficulty := range d.Difficulties {
+		if other.accepts(difficulty) {
+			return true
+		}
+	}
+	return false
+}
+
+// DrawQuestions picks the questions for each draw from its pool, given the
+// pools' questions by pool ID, and returns them in draw order. The same seed
+// always picks the same questions from the same pools: each draw shuffles
+// its candidates, ordered by ID, with its own generator derived from the
+// seed, so reordering a pool or editing another pool does not change it.
+func DrawQuestions(draws []PoolDraw, pools map[uuid.UUID][]*Question, seed int64) ([]*Question, error) {
+	var questions []*Question
+	for i, draw := range draws {
+		var candidates []*Question
+		for _, question := range pools[draw.PoolID] {
+			if draw.accepts(question.Difficulty) {
+				candidates = append(candidates, question)
+			}
+		}
+		if len(candidates) < draw.Count {
+			return nil, fmt.Errorf("%w: pool %s has %d matching questions but %d are drawn",
+				ErrNotEnoughQuestions, draw.PoolID, len(candidates), draw.Count)
+		}
+
+		sort.Slice(candidates, func(a, b int) bool {
+			return candidates[a].ID.String() < candidates[b].ID.String()
+		})
+		rng := rand.New(rand.NewSource(seed + int64(i)))
+		rng.Shuffle(len(candidates), func(a, b int) {
+			candidates[a], candidates[b] = candidates[b], candidates[a]
+		})
+
+		questions = append(questions, candidates[:draw.Count]...)
+	}
+	return questions, nil
+}
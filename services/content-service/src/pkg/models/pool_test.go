@@ -0,0 +1,100 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func poolQuestions(n int, difficulty Difficulty) []*Question {
+	questions := make([]*Question, n)
+	for i := range questions {
+		questions[i] = &Question{ID: uuid.New(), Difficulty: difficulty}
+	}
+	return questions
+}
+
+func questionIDs(questions []*Question) []uuid.UUID {
+	ids := make([]uuid.UUID, len(questions))
+	for i, question := range questions {
+		ids[i] = question.ID
+	}
+	return ids
+}
+
+func TestDrawQuestions(t *testing.T) {
+	poolA, poolB := uuid.New(), uuid.New()
+	pools := map[uuid.UUID][]*Question{
+		poolA: append(poolQuestions(10, DifficultyEasy), poolQuestions(10, DifficultyHard)...),
+		poolB: poolQuestions(6, ""),
+	}
+	draws := []PoolDraw{
+		{PoolID: poolA, Count: 5, Difficulties: []Difficulty{DifficultyHard}},
+		{PoolID: poolB, Count: 3},
+	}
+
+	drawn, err := DrawQuestions(draws, pools, 42)
+	require.NoError(t, err)
+	require.Len(t, drawn, 8)
+	for _, question := range drawn[:5] {
+		assert.Equal(t, DifficultyHard, question.Difficulty)
+	}
+	assert.Subset(t, questionIDs(pools[poolB]), questionIDs(drawn[5:]))
+
+	// The same seed draws the same questions, whatever order the pool lists them in
+	reversed := make([]*Question, len(pools[poolA]))
+	for i, question := range pools[poolA] {
+		reversed[len(reversed)-1-i] = question
+	}
+	again, err := DrawQuestions(draws, map[uuid.UUID][]*Question{poolA: reversed, poolB: pools[poolB]}, 42)
+	require.NoError(t, err)
+	assert.Equal(t, questionIDs(drawn), questionIDs(again))
+
+	other, err := DrawQuestions(draws, pools, 43)
+	require.NoError(t, err)
+	assert.NotEqual(t, questionIDs(drawn), questionIDs(other))
+}
+
+func TestDrawQuestionsNotEnough(t *testing.T) {
+	pool := uuid.New()
+	pools := map[uuid.UUID][]*Question{pool: append(poolQuestions(5, DifficultyEasy), poolQuestions(2, DifficultyMedium)...)}
+
+	_, err := DrawQuestions([]PoolDraw{{PoolID: pool, Count: 3, Difficulties: []Difficulty{DifficultyMedium}}}, pools, 1)
+	assert.ErrorIs(t, err, ErrNotEnoughQuestions)
+	_, err = DrawQuestions([]PoolDraw{{PoolID: uuid.New(), Count: 1}}, pools, 1)
+	assert.ErrorIs(t, err, ErrNotEnoughQuestions)
+}
+
+func TestValidateDraws(t *testing.T) {
+	pool := uuid.New()
+	tests := []struct {
+		name  string
+		draws []PoolDraw
+		want  []string
+	}{
+		{"valid", []PoolDraw{
+			{PoolID: pool, Count: 2, Difficulties: []Difficulty{DifficultyEasy}},
+			{PoolID: pool, Count: 1, Difficulties: []Difficulty{DifficultyMedium, DifficultyHard}},
+			{PoolID: uuid.New(), Count: 4},
+		}, nil},
+		{"missing pool", []PoolDraw{{Count: 1}}, []string{"draws[0].poolId"}},
+		{"bad count", []PoolDraw{{PoolID: pool, Count: 0}, {PoolID: uuid.New(), Count: MaxDrawCount + 1}}, []string{"draws[0].count", "draws[1].count"}},
+		{"bad difficulty", []PoolDraw{{PoolID: pool, Count: 1, Difficulties: []Difficulty{"tricky"}}}, []string{"draws[0].difficulties[0]"}},
+		{"overlapping", []PoolDraw{
+			{PoolID: pool, Count: 1, Difficulties: []Difficulty{DifficultyEasy}},
+			{PoolID: pool, Count: 1},
+		}, []string{"draws[1].difficulties"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, err := range ValidateDraws(tt.draws) {
+				fields = append(fields, err.Field)
+			}
+			assert.Equal(t, tt.want, fields)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChangeKind describes how a question differs between two revisions
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// QuizRevision is an immutable snapshot of a quiz and its questions taken
+// after a change. Quiz is nil when revisions are listed without content.
+type QuizRevision struct {
+	ID        uuid.UUID `json:"id"`
+	QuizID    uuid.UUID `json:"quizId"`
+	Version   int       `json:"version"`
+	Quiz      *Quiz     `json:"quiz,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// FieldChange records a single field whose value differs between revisions
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// QuestionChange records how one question differs between revisions
+type QuestionChange struct {
+	QuestionID uuid.UUID     `json:"questionId"`
+	Change     ChangeKind    `json:"change"`
+	Fields     []FieldChange `json:"fields,omitempty"`
+}
+
+// QuizDiff describes the changes needed to go from one revision to another
+type QuizDiff struct {
+	From        uuid.UUID        `json:"from"`
+	To          uuid.UUID        `json:"to"`
+	FromVersion int              `json:"fromVersion"`
+	ToVersion   int              `json:"toVersion"`
+	Fields      []FieldChange    `json:"fields"`
+	Questions   []QuestionChange `json:"questions"`
+}
+
+// DiffRevisions compares the snapshots of two revisions of the same quiz.
+// Questions are matched by ID, so edits show up as modifications rather than
+// as a removal plus an addition.
+func DiffRevisions(from, to *QuizRevision) *QuizDiff {
+	diff := &QuizDiff{
+		From:        from.ID,
+		To:          to.ID,
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Fields:      []FieldChange{},
+		Questions:   []QuestionChange{},
+	}
+
+	a, b := from.Quiz, to.Quiz
+	if a == nil {
+		a = &Quiz{}
+	}
+	if b == nil {
+		b = &Quiz{}
+	}
+
+	diff.Fields = appendChange(diff.Fields, "title", a.Title, b.Title)
+	diff.Fields = appendChange(diff.Fields, "description", a.Description, b.Description)
+	diff.Fields = appendChange(diff.Fields, "topicId", a.TopicID, b.TopicID)
+	diff.Fields = appendChange(diff.Fields, "draws", orNoDraws(a.Draws), orNoDraws(b.Draws))
+	diff.Fields = appendChange(diff.Fields, "settings", a.Settings, b.Settings)
+
+	before := make(map[uuid.UUID]*Question, len(a.Questions))
+	for _, question := range a.Questions {
+		before[question.ID] = question
+	}
+
+	seen := make(map[uuid.UUID]bool, len(b.Questions))
+	for _, question := range b.Questions {
+		seen[question.ID] = true
+		old, ok := before[question.ID]
+		if !ok {
+			diff.Questions = append(diff.Questions, QuestionChange{QuestionID: question.ID, Change: ChangeAdded})
+			continue
+		}
+		if fields := diffQuestion(old, question); len(fields) > 0 {
+			diff.Questions = append(diff.Questions, QuestionChange{QuestionID: question.ID, Change: ChangeModified, Fields: fields})
+		}
+	}
+
+	for _, question := range a.Questions {
+		if !seen[question.ID] {
+			diff.Questions = append(diff.Questions, QuestionChange{QuestionID: question.ID, Change: ChangeRemoved})
+		}
+	}
+
+	return diff
+}
+
+// diffQuestion lists the authored fields that differ between two versions of a question
+func diffQuestion(a, b *Question) []FieldChange {
+	var fields []FieldChange
+	fields = appendChange(fields, "position", a.Position, b.Position)
+	fields = appendChange(fields, "text", a.Text, b.Text)
+	fields = appendChange(fields, "type", a.Type, b.Type)
+	fields = appendChange(fields, "difficulty", a.Difficulty, b.Difficulty)
+	fields = appendChange(fields, "options", orEmpty(a.Options), orEmpty(b.Options))
+	fields = appendChange(fields, "prompts", orEmpty(a.Prompts), orEmpty(b.Prompts))
+	fields = appendChange(fields, "correctAnswer", a.CorrectAnswer, b.CorrectAnswer)
+	fields = appendChange(fields, "correctAnswers", orEmpty(a.CorrectAnswers), orEmpty(b.CorrectAnswers))
+	fields = appendChange(fields, "matches", orEmpty(a.Matches), orEmpty(b.Matches))
+	fields = appendChange(fields, "correctOrder", orEmpty(a.CorrectOrder), orEmpty(b.CorrectOrder))
+	fields = appendChange(fields, "scoring", a.Scoring, b.Scoring)
+	fields = appendChange(fields, "grading", a.Grading, b.Grading)
+	fields = appendChange(fields, "numeric", a.Numeric, b.Numeric)
+	fields = appendChange(fields, "blanks", orNoBlanks(a.Blanks), orNoBlanks(b.Blanks))
+	fields = appendChange(fields, "acceptedAnswers", orNoAccepted(a.AcceptedAnswers), orNoAccepted(b.AcceptedAnswers))
+	fields = appendChange(fields, "rubric", a.Rubric, b.Rubric)
+	fields = appendChange(fields, "media", orNoMedia(a.Media), orNoMedia(b.Media))
+	fields = appendChange(fields, "optionMedia", orNoOptionMedia(a.OptionMedia), orNoOptionMedia(b.OptionMedia))
+	fields = appendChange(fields, "explanation", a.Explanation, b.Explanation)
+	return fields
+}
+
+// appendChange appends a FieldChange when from and to differ
+func appendChange(fields []FieldChange, field string, from, to interface{}) []FieldChange {
+	if reflect.DeepEqual(from, to) {
+		return fields
+	}
+	return append(fields, FieldChange{Field: field, From: from, To: to})
+}
+
+// orNoBlanks treats missing blanks like an empty list, as orEmpty does
+func orNoBlanks(blanks []ClozeBlank) []ClozeBlank {
+	if blanks == nil {
+		return []ClozeBlank{}
+	}
+	return blanks
+}
+
+// orNoAccepted treats missing accepted answers like an empty list, as orEmpty does
+func orNoAccepted(accepted []AcceptedAnswer) []AcceptedAnswer {
+	if accepted == nil {
+		return []AcceptedAnswer{}
+	}
+	return accepted
+}
+
+// orNoMedia treats missing media like an empty list, as orEmpty does
+func orNoMedia(media []Attachment) []Attachment {
+	if media == nil {
+		return []Attachment{}
+	}
+	return media
+}
+
+// orNoOptionMedia treats missing option media like an empty list, as orEmpty does
+func orNoOptionMedia(media []*Attachment) []*Attachment {
+	if media == nil {
+		return []*Attachment{}
+	}
+	return media
+}
+
+// orNoDraws treats missing draw rules like an empty list, as orEmpty does
+func orNoDraws(draws []PoolDraw) []PoolDraw {
+	if draws == nil {
+		return []PoolDraw{}
+	}
+	return draws
+}
+
+// orEmpty treats a nil slice like an empty one so decoded snapshots compare equal
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRevisions(t *testing.T) {
+	kept := &Question{ID: uuid.New(), Position: 1, Text: "2 + 2?", Type: QuestionTypeMultipleChoice, Options: []string{"3", "4"}, CorrectAnswer: "4"}
+	removed := &Question{ID: uuid.New(), Position: 2, Text: "Sky is blue", Type: QuestionTypeTrueFalse, CorrectAnswer: "true"}
+	added := &Question{ID: uuid.New(), Position: 2, Text: "Name a prime", Type: QuestionTypeOpenEnded, CorrectAnswer: "2"}
+
+	edited := *kept
+	edited.CorrectAnswer = "3"
+
+	from := &QuizRevision{ID: uuid.New(), Version: 1, Quiz: &Quiz{Title: "Math", Questions: []*Question{kept, removed}}}
+	to := &QuizRevision{ID: uuid.New(), Version: 2, Quiz: &Quiz{Title: "Arithmetic", Questions: []*Question{&edited, added}}}
+
+	diff := DiffRevisions(from, to)
+
+	assert.Equal(t, 1, diff.FromVersion)
+	assert.Equal(t, 2, diff.ToVersion)
+	assert.Equal(t, []FieldChange{{Field: "title", From: "Math", To: "Arithmetic"}}, diff.Fields)
+	assert.Equal(t, []QuestionChange{
+		{QuestionID: kept.ID, Change: ChangeModified, Fields: []FieldChange{{Field: "correctAnswer", From: "4", To: "3"}}},
+		{QuestionID: added.ID, Change: ChangeAdded},
+		{QuestionID: removed.ID, Change: ChangeRemoved},
+	}, diff.Questions)
+}
+
+func TestDiffRevisionsIdentical(t *testing.T) {
+	question := &Question{ID: uuid.New(), Position: 1, Text: "Sky is blue", Type: QuestionTypeTrueFalse, CorrectAnswer: "true"}
+	decoded := *question
+	decoded.Options = []string{}
+
+	from := &QuizRevision{ID: uuid.New(), Quiz: &Quiz{Title: "Sky", Questions: []*Question{question}}}
+	to := &QuizRevision{ID: uuid.New(), Quiz: &Quiz{Title: "Sky", Questions: []*Question{&decoded}}}
+
+	diff := DiffRevisions(from, to)
+
+	assert.Empty(t, diff.Fields)
+	assert.Empty(t, diff.Questions)
+}
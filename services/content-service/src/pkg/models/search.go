@@ -0,0 +1,17 @@
+package models
+
+// SearchSnippets holds highlighted fragments showing why a quiz matched.
+// Snippets are HTML: the quiz text is escaped and matched terms are wrapped in
+// <mark></mark>. Fields that did not match are empty.
+type SearchSnippets struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Question    string `json:"question,omitempty"`
+}
+
+// QuizSearchHit is a quiz matched by a full-text search, with its relevance
+type QuizSearchHit struct {
+	Quiz     *Quiz          `json:"quiz"`
+	Rank     float64        `json:"rank"`
+	Snippets SearchSnippets `json:"snippets"`
+}
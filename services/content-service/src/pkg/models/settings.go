@@ -0,0 +1,59 @@
+package models
+
+import "fmt"
+
+// RevealMode selects when learners see which answers were correct
+type RevealMode string
+
+const (
+	// Reveal modes
+	RevealImmediately     RevealMode = "immediately"
+	RevealAfterSubmission RevealMode = "after_submission"
+	RevealNever           RevealMode = "never"
+)
+
+// MaxTimeLimit bounds a quiz's time limit, in seconds
+const MaxTimeLimit = 24 * 60 * 60
+
+// QuizSettings control how a quiz is delivered to learners. The study
+// service enforces them on attempts, which keep the settings they started
+// with. Zero values leave a quiz untimed, unshuffled and without limits.
+//
+// TimeLimit is in seconds. PassingScore is a percentage of the quiz's
+// credit; without one, attempts neither pass nor fail. RevealAnswers
+// defaults to revealing answers immediately.
+type QuizSettings struct {
+	TimeLimit        int        `json:"timeLimit,omitempty"`
+	ShuffleQuestions bool       `json:"shuffleQuestions,omitempty"`
+	ShuffleOptions   bool       `json:"shuffleOptions,omitempty"`
+	MaxAttempts      int        `json:"maxAttempts,omitempty"`
+	PassingScore     *float64   `json:"passingScore,omitempty"`
+	RevealAnswers    RevealMode `json:"revealAnswers,omitempty"`
+}
+
+// Valid reports whether m is a known reveal mode
+func (m RevealMode) Valid() bool {
+	return m == RevealImmediately || m == RevealAfterSubmission || m == RevealNever
+}
+
+// Validate checks a quiz's settings
+func (s *QuizSettings) Validate() []ValidationError {
+	var errs []ValidationError
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Field: "settings." + field, Error: fmt.Sprintf(format, args...)})
+	}
+
+	if s.TimeLimit < 0 || s.TimeLimit > MaxTimeLimit {
+		fail("timeLimit", "Must be between 0 and %d seconds", MaxTimeLimit)
+	}
+	if s.MaxAttempts < 0 {
+		fail("maxAttempts", "Must not be negative")
+	}
+	if s.PassingScore != nil && (*s.PassingScore < 0 || *s.PassingScore > 100) {
+		fail("passingScore", "Must be between 0 and 100")
+	}
+	if s.RevealAnswers != "" && !s.RevealAnswers.Valid() {
+		fail("revealAnswers", "Must be %s, %s or %s", RevealImmediately, RevealAfterSubmission, RevealNever)
+	}
+	return errs
+}
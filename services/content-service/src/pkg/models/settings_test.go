@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizSettingsValidate(t *testing.T) {
+	score := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name     string
+		settings QuizSettings
+		want     []string
+	}{
+		{"empty", QuizSettings{}, nil},
+		{"valid", QuizSettings{TimeLimit: 600, ShuffleQuestions: true, MaxAttempts: 3, PassingScore: score(70), RevealAnswers: RevealAfterSubmission}, nil},
+		{"negative", QuizSettings{TimeLimit: -1, MaxAttempts: -1}, []string{"settings.timeLimit", "settings.maxAttempts"}},
+		{"too long", QuizSettings{TimeLimit: MaxTimeLimit + 1}, []string{"settings.timeLimit"}},
+		{"bad passing score", QuizSettings{PassingScore: score(101)}, []string{"settings.passingScore"}},
+		{"bad reveal mode", QuizSettings{RevealAnswers: "later"}, []string{"settings.revealAnswers"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, err := range tt.settings.Validate() {
+				fields = append(fields, err.Field)
+			}
+			assert.Equal(t, tt.want, fields)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Topic is a node in the quiz taxonomy. Topics form a tree through ParentID.
+type Topic struct {
+	ID          uuid.UUID  `json:"id"`
+	ParentID    *uuid.UUID `json:"parentId,omitempty"`
+	Slug        string     `json:"slug"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	// CreatedBy is the user who created the topic; topics created before
+	// creators were recorded have none
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty"`
+	// QuizCount counts quizzes filed directly under the topic and
+	// TotalQuizCount also includes those in its subtopics
+	QuizCount      int       `json:"quizCount"`
+	TotalQuizCount int       `json:"totalQuizCount"`
+	Children       []*Topic  `json:"children,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+var (
+	slugPattern  = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	slugSeparate = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// NewTopic creates a new topic, deriving the slug from the name when none is given
+func NewTopic(name, slug, description string, parentID *uuid.UUID) *Topic {
+	if slug == "" {
+		slug = Slugify(name)
+	}
+	now := time.Now().UTC()
+	return &Topic{
+		ID:          uuid.New(),
+		ParentID:    parentID,
+		Slug:        slug,
+		Name:        name,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Slugify turns a name into a URL-friendly slug, e.g. "Linear Algebra" -> "linear-algebra"
+func Slugify(name string) string {
+	return strings.Trim(slugSeparate.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// ValidSlug reports whether slug is lowercase words joined by single hyphens
+func ValidSlug(slug string) bool {
+	return slugPattern.MatchString(slug)
+}
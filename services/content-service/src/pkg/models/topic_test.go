@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Linear Algebra", "linear-algebra"},
+		{"  C++ & Go!  ", "c-go"},
+		{"World War II", "world-war-ii"},
+		{"---", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Slugify(tt.name)
+			assert.Equal(t, tt.want, got)
+			if got != "" {
+				assert.True(t, ValidSlug(got))
+			}
+		})
+	}
+}
+
+func TestValidSlug(t *testing.T) {
+	assert.True(t, ValidSlug("biology"))
+	assert.True(t, ValidSlug("cell-biology-101"))
+	assert.False(t, ValidSlug(""))
+	assert.False(t, ValidSlug("Biology"))
+	assert.False(t, ValidSlug("cell--biology"))
+	assert.False(t, ValidSlug("-biology"))
+}
@@ -0,0 +1,409 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidationError reports a field that breaks a model rule. It has the same
+// shape as the ai-service API's validation errors.
+type ValidationError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
+
+// Validate checks the quiz and everything in it: a title, a known
+// visibility if one is set, each question's invariants for its type, the
+// draw rules and the settings. Errors in questions name the question, as in
+// questions[2].correctAnswer. Whether the pools the quiz draws from exist is
+// left to callers.
+func (q *Quiz) Validate() []ValidationError {
+	var errs []ValidationError
+	if strings.TrimSpace(q.Title) == "" {
+		errs = append(errs, ValidationError{Field: "title", Error: "This field is required"})
+	}
+	if q.Visibility != "" && !q.Visibility.Valid() {
+		errs = append(errs, ValidationError{Field: "visibility", Error: fmt.Sprintf("Must be %s, %s or %s", VisibilityPrivate, VisibilityPublic, VisibilityShared)})
+	}
+	for i, question := range q.Questions {
+		for _, err := range question.Validate() {
+			err.Field = fmt.Sprintf("questions[%d].%s", i, err.Field)
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, ValidateDraws(q.Draws)...)
+	errs = append(errs, q.Settings.Validate()...)
+	return errs
+}
+
+// Validate checks the question's invariants for its type: multiple choice
+// questions need at least two distinct options and an answer key among them,
+// multiple select questions the same with a set of correct options instead,
+// true/false questions a true or false answer key, and open-ended questions an
+// answer key or accepted answers, unless graded manually, and no options.
+// Numeric questions need a numeric answer key. Matching questions need at least
+// two distinct prompts each matched to an option, and ordering questions a
+// correct order using every option once. Cloze questions need blanks, each
+// placed once in the text and accepting at least one answer. Only multiple
+// select, matching, ordering and cloze questions, which can earn part of the
+// credit, may set a scoring mode.
+func (q *Question) Validate() []ValidationError {
+	var errs []ValidationError
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Field: field, Error: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(q.Text) == "" {
+		fail("text", "This field is required")
+	}
+
+	switch q.Type {
+	case "":
+		fail("type", "This field is required")
+	case QuestionTypeMultipleChoice:
+		seen := q.validateOptions(fail)
+		if strings.TrimSpace(q.CorrectAnswer) == "" {
+			fail("correctAnswer", "This field is required")
+		} else if _, ok := seen[normalizeAnswer(q.CorrectAnswer)]; !ok && len(q.Options) > 0 {
+			fail("correctAnswer", "Must be one of the options")
+		}
+	case QuestionTypeMultipleSelect:
+		seen := q.validateOptions(fail)
+		if len(q.CorrectAnswers) == 0 {
+			fail("correctAnswers", "Select at least one correct option")
+		}
+		selected := make(map[string]bool, len(q.CorrectAnswers))
+		for i, answer := range q.CorrectAnswers {
+			key := normalizeAnswer(answer)
+			field := fmt.Sprintf("correctAnswers[%d]", i)
+			if _, ok := seen[key]; !ok {
+				fail(field, "Must be one of the options")
+			} else if selected[key] {
+				fail(field, "Option is already selected")
+			}
+			selected[key] = true
+		}
+		if q.CorrectAnswer != "" {
+			fail("correctAnswer", "Multiple select questions use correctAnswers")
+		}
+	case QuestionTypeTrueFalse:
+		switch strings.ToLower(strings.TrimSpace(q.CorrectAnswer)) {
+		case "true", "false":
+		case "":
+			fail("correctAnswer", "This field is required")
+		default:
+			fail("correctAnswer", "Must be true or false")
+		}
+		if len(q.Options) != 0 && !trueFalseOptions(q.Options) {
+			fail("options", "True/false questions may only have the options true and false")
+		}
+	case QuestionTypeOpenEnded:
+		rubric := q.Grading == GradingAI && strings.TrimSpace(q.Rubric) != ""
+		if strings.TrimSpace(q.CorrectAnswer) == "" && len(q.AcceptedAnswers) == 0 && q.Grading != GradingManual && !rubric {
+			fail("correctAnswer", "Required unless accepted answers are listed, answers are graded manually or AI grading has a rubric")
+		}
+		q.validateAcceptedAnswers(fail)
+		if len(q.Options) != 0 {
+			fail("options", "Open-ended questions have no options")
+		}
+	case QuestionTypeNumeric:
+		if q.Numeric == nil {
+			fail("numeric", "This field is required")
+		} else {
+			q.Numeric.validate(fail)
+		}
+		if q.CorrectAnswer != "" {
+			fail("correctAnswer", "Numeric questions use numeric")
+		}
+		if len(q.Options) != 0 {
+			fail("options", "Numeric questions have no options")
+		}
+	case QuestionTypeMatching:
+		seen := q.validateOptions(fail)
+		q.validatePrompts(fail)
+		if len(q.Matches) != len(q.Prompts) {
+			fail("matches", "Match each of the %d prompts to an option", len(q.Prompts))
+		}
+		for i, match := range q.Matches {
+			if _, ok := seen[normalizeAnswer(match)]; !ok {
+				fail(fmt.Sprintf("matches[%d]", i), "Must be one of the options")
+			}
+		}
+		if q.CorrectAnswer != "" {
+			fail("correctAnswer", "Matching questions use matches")
+		}
+	case QuestionTypeOrdering:
+		seen := q.validateOptions(fail)
+		if len(q.CorrectOrder) != len(q.Options) {
+			fail("correctOrder", "Must list each of the %d options once", len(q.Options))
+		}
+		placed := make(map[string]bool, len(q.CorrectOrder))
+		for i, item := range q.CorrectOrder {
+			key := normalizeAnswer(item)
+			field := fmt.Sprintf("correctOrder[%d]", i)
+			if _, ok := seen[key]; !ok {
+				fail(field, "Must be one of the options")
+			} else if placed[key] {
+				fail(field, "Option is already placed")
+			}
+			placed[key] = true
+		}
+		if q.CorrectAnswer != "" {
+			fail("correctAnswer", "Ordering questions use correctOrder")
+		}
+	case QuestionTypeCloze:
+		q.validateBlanks(fail)
+		if q.CorrectAnswer != "" {
+			fail("correctAnswer", "Cloze questions use blanks")
+		}
+		if len(q.Options) != 0 {
+			fail("options", "Cloze questions keep their options in blanks")
+		}
+	default:
+		fail("type", "Must be one of %s", strings.Join(questionTypeNames(), ", "))
+	}
+
+	if q.Type != QuestionTypeNumeric && q.Numeric != nil {
+		fail("numeric", "Only numeric questions have a numeric answer")
+	}
+
+	if q.Type != QuestionTypeMultipleSelect && len(q.CorrectAnswers) > 0 {
+		fail("correctAnswers", "Only multiple select questions have several correct answers")
+	}
+	if q.Type != QuestionTypeMatching && (len(q.Prompts) > 0 || len(q.Matches) > 0) {
+		fail("prompts", "Only matching questions have prompts")
+	}
+	if q.Type != QuestionTypeOrdering && len(q.CorrectOrder) > 0 {
+		fail("correctOrder", "Only ordering questions have a correct order")
+	}
+	if q.Type != QuestionTypeCloze && len(q.Blanks) > 0 {
+		fail("blanks", "Only cloze questions have blanks")
+	}
+	if q.Type != QuestionTypeOpenEnded && len(q.AcceptedAnswers) > 0 {
+		fail("acceptedAnswers", "Only open-ended questions have accepted answers")
+	}
+	switch {
+	case q.Scoring == "":
+	case !q.Scoring.Valid():
+		fail("scoring", "Must be %s or %s", ScoringAllOrNothing, ScoringPartialCredit)
+	case q.Type != QuestionTypeMultipleSelect && q.Type != QuestionTypeMatching &&
+		q.Type != QuestionTypeOrdering && q.Type != QuestionTypeCloze:
+		fail("scoring", "Only multiple select, matching, ordering and cloze questions have a scoring mode")
+	}
+
+	switch {
+	case q.Grading == "":
+	case !q.Grading.Valid():
+		fail("grading", "Must be %s, %s or %s", GradingAuto, GradingManual, GradingAI)
+	case q.Type != QuestionTypeOpenEnded && q.Grading != GradingAuto:
+		fail("grading", "Only open-ended questions can be graded manually or by AI")
+	}
+	if strings.TrimSpace(q.Rubric) != "" && q.Type != QuestionTypeOpenEnded {
+		fail("rubric", "Only open-ended questions have a rubric")
+	}
+	if q.Difficulty != "" && !q.Difficulty.Valid() {
+		fail("difficulty", "Must be %s, %s or %s", DifficultyEasy, DifficultyMedium, DifficultyHard)
+	}
+
+	q.validateMedia(fail)
+
+	return errs
+}
+
+// validate checks the tolerance and units of a numeric answer key
+func (n *NumericAnswer) validate(fail func(field, format string, args ...interface{})) {
+	if n.Tolerance < 0 {
+		fail("numeric.tolerance", "Must not be negative")
+	}
+	if n.ToleranceType != "" && !n.ToleranceType.Valid() {
+		fail("numeric.toleranceType", "Must be %s or %s", ToleranceAbsolute, ToleranceRelative)
+	}
+	if strings.TrimSpace(n.Unit) == "" && (len(n.Units) > 0 || n.UnitRequired) {
+		fail("numeric.unit", "Required when units are accepted or required")
+	}
+
+	seen := map[string]bool{strings.TrimSpace(n.Unit): true}
+	for i, unit := range n.Units {
+		field := fmt.Sprintf("numeric.units[%d]", i)
+		name := strings.TrimSpace(unit.Unit)
+		switch {
+		case name == "":
+			fail(field+".unit", "This field is required")
+		case seen[name]:
+			fail(field+".unit", "Unit %q is already accepted", name)
+		}
+		seen[name] = true
+		if unit.Multiplier <= 0 {
+			fail(field+".multiplier", "Must be greater than zero")
+		}
+	}
+}
+
+// validateOptions checks a choice question's options and returns the
+// normalized options mapped to their index
+func (q *Question) validateOptions(fail func(field, format string, args ...interface{})) map[string]int {
+	if len(q.Options) < 2 {
+		fail("options", "%s questions need at least two options", typeLabel(q.Type))
+	}
+	seen := make(map[string]int, len(q.Options))
+	for i, option := range q.Options {
+		key := normalizeAnswer(option)
+		if key == "" {
+			fail(fmt.Sprintf("options[%d]", i), "Option must not be empty")
+			continue
+		}
+		if first, ok := seen[key]; ok {
+			fail(fmt.Sprintf("options[%d]", i), "Option duplicates option %d", first+1)
+			continue
+		}
+		seen[key] = i
+	}
+	return seen
+}
+
+// validatePrompts checks a matching question's prompts
+func (q *Question) validatePrompts(fail func(field, format string, args ...interface{})) {
+	if len(q.Prompts) < 2 {
+		fail("prompts", "Matching questions need at least two prompts")
+	}
+	seen := make(map[string]int, len(q.Prompts))
+	for i, prompt := range q.Prompts {
+		key := normalizeAnswer(prompt)
+		if key == "" {
+			fail(fmt.Sprintf("prompts[%d]", i), "Prompt must not be empty")
+			continue
+		}
+		if first, ok := seen[key]; ok {
+			fail(fmt.Sprintf("prompts[%d]", i), "Prompt duplicates prompt %d", first+1)
+			continue
+		}
+		seen[key] = i
+	}
+}
+
+// validateBlanks checks that every blank of a cloze question is placed in
+// the text exactly once and accepts at least one answer
+func (q *Question) validateBlanks(fail func(field, format string, args ...interface{})) {
+	if len(q.Blanks) == 0 {
+		fail("blanks", "Cloze questions need at least one blank")
+	}
+
+	placed := make(map[int]bool, len(q.Blanks))
+	for _, match := range ClozeBlankMarkup.FindAllStringSubmatch(q.Text, -1) {
+		n, _ := strconv.Atoi(match[1])
+		switch {
+		case n < 1 || n > len(q.Blanks):
+			fail("text", "Blank {{%s}} is not defined", match[1])
+		case placed[n]:
+			fail("text", "Blank {{%d}} appears more than once", n)
+		}
+		placed[n] = true
+	}
+
+	for i, blank := range q.Blanks {
+		field := fmt.Sprintf("blanks[%d]", i)
+		if !placed[i+1] {
+			fail(field, "Not placed in the text; write {{%d}} where it goes", i+1)
+		}
+		if !blank.Kind.Valid() {
+			fail(field+".kind", "Must be %s or %s", BlankText, BlankDropdown)
+		}
+
+		options := make(map[string]bool, len(blank.Options))
+		switch blank.Kind {
+		case BlankDropdown:
+			if len(blank.Options) < 2 {
+				fail(field+".options", "Dropdown blanks need at least two options")
+			}
+			for j, option := range blank.Options {
+				key := normalizeAnswer(option)
+				if key == "" || options[key] {
+					fail(fmt.Sprintf("%s.options[%d]", field, j), "Option must not be empty or repeated")
+				}
+				options[key] = true
+			}
+		case BlankText:
+			if len(blank.Options) != 0 {
+				fail(field+".options", "Text blanks have no options")
+			}
+		}
+
+		if len(blank.Answers) == 0 {
+			fail(field+".answers", "Accept at least one answer")
+		}
+		for j, answer := range blank.Answers {
+			answerField := fmt.Sprintf("%s.answers[%d]", field, j)
+			switch {
+			case strings.TrimSpace(answer) == "":
+				fail(answerField, "Answer must not be empty")
+			case blank.Kind == BlankDropdown && !options[normalizeAnswer(answer)]:
+				fail(answerField, "Must be one of the options")
+			}
+		}
+	}
+}
+
+// validateAcceptedAnswers checks the match rules of an open-ended
+// question's accepted answers
+func (q *Question) validateAcceptedAnswers(fail func(field, format string, args ...interface{})) {
+	for i, accepted := range q.AcceptedAnswers {
+		field := fmt.Sprintf("acceptedAnswers[%d]", i)
+		if strings.TrimSpace(accepted.Answer) == "" {
+			fail(field+".answer", "Answer must not be empty")
+		}
+		if !accepted.Match.Valid() {
+			fail(field+".match", "Must be one of %s", strings.Join(matchRuleNames(), ", "))
+		}
+		if accepted.Match == MatchRegex {
+			if _, err := regexp.Compile(accepted.Answer); err != nil {
+				fail(field+".answer", "Invalid regular expression: %v", err)
+			}
+		}
+		switch {
+		case accepted.Match == MatchLevenshtein && accepted.MaxDistance < 1:
+			fail(field+".maxDistance", "Must be at least 1")
+		case accepted.Match != MatchLevenshtein && accepted.MaxDistance != 0:
+			fail(field+".maxDistance", "Only levenshtein matches have a maximum distance")
+		}
+	}
+}
+
+// typeLabel names a question type for messages, e.g. "Multiple choice"
+func typeLabel(t QuestionType) string {
+	label := strings.ReplaceAll(string(t), "_", " ")
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// normalizeAnswer folds case and whitespace the way answers are graded
+func normalizeAnswer(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// trueFalseOptions reports whether options are exactly true and false
+func trueFalseOptions(options []string) bool {
+	if len(options) != 2 {
+		return false
+	}
+	a, b := normalizeAnswer(options[0]), normalizeAnswer(options[1])
+	return (a == "true" && b == "false") || (a == "false" && b == "true")
+}
+
+// matchRuleNames lists the match rules of accepted answers
+func matchRuleNames() []string {
+	names := make([]string, len(MatchRules))
+	for i, r := range MatchRules {
+		names[i] = string(r)
+	}
+	return names
+}
+
+// questionTypeNames lists the supported question types
+func questionTypeNames() []string {
+	names := make([]string, len(QuestionTypes))
+	for i, t := range QuestionTypes {
+		names[i] = string(t)
+	}
+	return names
+}
@@ -0,0 +1,227 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuestionValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		question *Question
+		want     []ValidationError
+	}{
+		{
+			name:     "valid multiple choice",
+			question: NewQuestion(uuid.Nil, "2+2?", QuestionTypeMultipleChoice, []string{"3", "4"}, " 4 ", ""),
+		},
+		{
+			name:     "answer not in options",
+			question: NewQuestion(uuid.Nil, "2+2?", QuestionTypeMultipleChoice, []string{"3", "5", "3 "}, "4", ""),
+			want: []ValidationError{
+				{Field: "options[2]", Error: "Option duplicates option 1"},
+				{Field: "correctAnswer", Error: "Must be one of the options"},
+			},
+		},
+		{
+			name:     "true/false with extra options",
+			question: NewQuestion(uuid.Nil, "Sky is blue", QuestionTypeTrueFalse, []string{"true", "false", "maybe"}, "TRUE", ""),
+			want:     []ValidationError{{Field: "options", Error: "True/false questions may only have the options true and false"}},
+		},
+		{
+			name:     "missing text and type",
+			question: NewQuestion(uuid.Nil, " ", "", nil, "", ""),
+			want: []ValidationError{
+				{Field: "text", Error: "This field is required"},
+				{Field: "type", Error: "This field is required"},
+			},
+		},
+		{
+			name: "multiple select answers not in options",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "Primes?", QuestionTypeMultipleSelect, []string{"2", "3", "4"}, "", "")
+				q.CorrectAnswers = []string{"2", "9", "2"}
+				q.Scoring = "some"
+				return q
+			}(),
+			want: []ValidationError{
+				{Field: "correctAnswers[1]", Error: "Must be one of the options"},
+				{Field: "correctAnswers[2]", Error: "Option is already selected"},
+				{Field: "scoring", Error: "Must be all_or_nothing or partial_credit"},
+			},
+		},
+		{
+			name: "numeric units without a base unit",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "Length?", QuestionTypeNumeric, []string{}, "", "")
+				q.Numeric = &NumericAnswer{
+					Value:     2,
+					Tolerance: -1,
+					Units:     []NumericUnit{{Unit: "cm", Multiplier: 0.01}, {Unit: "cm", Multiplier: 0}},
+				}
+				return q
+			}(),
+			want: []ValidationError{
+				{Field: "numeric.tolerance", Error: "Must not be negative"},
+				{Field: "numeric.unit", Error: "Required when units are accepted or required"},
+				{Field: "numeric.units[1].unit", Error: `Unit "cm" is already accepted`},
+				{Field: "numeric.units[1].multiplier", Error: "Must be greater than zero"},
+			},
+		},
+		{
+			name: "matching prompts without matches",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "Match", QuestionTypeMatching, []string{"meow", "woof"}, "", "")
+				q.Prompts = []string{"cat", "Cat", "dog"}
+				q.Matches = []string{"meow", "moo"}
+				return q
+			}(),
+			want: []ValidationError{
+				{Field: "prompts[1]", Error: "Prompt duplicates prompt 1"},
+				{Field: "matches", Error: "Match each of the 3 prompts to an option"},
+				{Field: "matches[1]", Error: "Must be one of the options"},
+			},
+		},
+		{
+			name: "ordering missing an option",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "Order", QuestionTypeOrdering, []string{"a", "b", "c"}, "", "")
+				q.CorrectOrder = []string{"c", "C"}
+				q.Scoring = ScoringPartialCredit
+				return q
+			}(),
+			want: []ValidationError{
+				{Field: "correctOrder", Error: "Must list each of the 3 options once"},
+				{Field: "correctOrder[1]", Error: "Option is already placed"},
+			},
+		},
+		{
+			name: "cloze blanks out of step with the text",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "{{1}} and {{1}} or {{3}}", QuestionTypeCloze, []string{}, "", "")
+				q.Blanks = []ClozeBlank{
+					{Kind: BlankDropdown, Options: []string{"a", "b"}, Answers: []string{"c"}},
+					{Kind: BlankText, Options: []string{"x"}},
+				}
+				return q
+			}(),
+			want: []ValidationError{
+				{Field: "text", Error: "Blank {{1}} appears more than once"},
+				{Field: "text", Error: "Blank {{3}} is not defined"},
+				{Field: "blanks[0].answers[0]", Error: "Must be one of the options"},
+				{Field: "blanks[1]", Error: "Not placed in the text; write {{2}} where it goes"},
+				{Field: "blanks[1].options", Error: "Text blanks have no options"},
+				{Field: "blanks[1].answers", Error: "Accept at least one answer"},
+			},
+		},
+		{
+			name: "open ended accepted answers with broken rules",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "Colour?", QuestionTypeOpenEnded, []string{}, "", "")
+				q.AcceptedAnswers = []AcceptedAnswer{
+					{Answer: "colou?r", Match: MatchRegex},
+					{Answer: "colo(u", Match: MatchRegex},
+					{Answer: "color", Match: MatchLevenshtein},
+					{Answer: "color", Match: "fuzzy", MaxDistance: 2},
+				}
+				return q
+			}(),
+			want: []ValidationError{
+				{Field: "acceptedAnswers[1].answer", Error: "Invalid regular expression: error parsing regexp: missing closing ): `colo(u`"},
+				{Field: "acceptedAnswers[2].maxDistance", Error: "Must be at least 1"},
+				{Field: "acceptedAnswers[3].match", Error: "Must be one of exact, case_insensitive, whitespace_normalized, regex, levenshtein"},
+				{Field: "acceptedAnswers[3].maxDistance", Error: "Only levenshtein matches have a maximum distance"},
+			},
+		},
+		{
+			name: "manually graded open ended needs no answer key",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "Discuss.", QuestionTypeOpenEnded, []string{}, "", "")
+				q.Grading = GradingManual
+				return q
+			}(),
+		},
+		{
+			name: "only open ended questions are graded manually",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "2+2?", QuestionTypeMultipleChoice, []string{"3", "4"}, "4", "")
+				q.Grading = GradingManual
+				return q
+			}(),
+			want: []ValidationError{{Field: "grading", Error: "Only open-ended questions can be graded manually or by AI"}},
+		},
+		{
+			name: "AI graded open ended with a rubric needs no answer key",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "Why is the sky blue?", QuestionTypeOpenEnded, []string{}, "", "")
+				q.Grading = GradingAI
+				q.Rubric = "Full credit for naming Rayleigh scattering"
+				return q
+			}(),
+		},
+		{
+			name: "AI graded open ended without key or rubric",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "Why is the sky blue?", QuestionTypeOpenEnded, []string{}, "", "")
+				q.Grading = GradingAI
+				return q
+			}(),
+			want: []ValidationError{{Field: "correctAnswer", Error: "Required unless accepted answers are listed, answers are graded manually or AI grading has a rubric"}},
+		},
+		{
+			name: "only open ended questions have a rubric",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "Sky is blue", QuestionTypeTrueFalse, []string{}, "true", "")
+				q.Rubric = "Be generous"
+				return q
+			}(),
+			want: []ValidationError{{Field: "rubric", Error: "Only open-ended questions have a rubric"}},
+		},
+		{
+			name: "media needs alt text and option media matches options",
+			question: func() *Question {
+				q := NewQuestion(uuid.Nil, "Which bird sings this?", QuestionTypeMultipleChoice, []string{"Robin", "Wren"}, "Wren", "")
+				q.Media = []Attachment{{MediaID: uuid.New(), Kind: MediaAudio}}
+				q.OptionMedia = []*Attachment{nil, {MediaID: uuid.New(), Kind: MediaImage}, {}}
+				return q
+			}(),
+			want: []ValidationError{
+				{Field: "optionMedia", Error: "Has 3 entries but the question has 2 options"},
+				{Field: "optionMedia[1].altText", Error: "Describe the image for learners who cannot see it"},
+				{Field: "optionMedia[2].mediaId", Error: "This field is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.question.Validate())
+		})
+	}
+}
+
+func TestQuizValidate(t *testing.T) {
+	quiz := &Quiz{
+		Title:      " ",
+		Visibility: "secret",
+		Questions: []*Question{
+			NewQuestion(uuid.Nil, "2+2?", QuestionTypeMultipleChoice, []string{"3", "4"}, "4", ""),
+			NewQuestion(uuid.Nil, "Sky is blue", QuestionTypeTrueFalse, []string{"true", "false", "yes", "no", "maybe"}, "true", ""),
+		},
+		Draws:    []PoolDraw{{Count: 1}},
+		Settings: QuizSettings{MaxAttempts: -1},
+	}
+
+	assert.Equal(t, []ValidationError{
+		{Field: "title", Error: "This field is required"},
+		{Field: "visibility", Error: "Must be private, public or shared"},
+		{Field: "questions[1].options", Error: "True/false questions may only have the options true and false"},
+		{Field: "draws[0].poolId", Error: "This field is required"},
+		{Field: "settings.maxAttempts", Error: "Must not be negative"},
+	}, quiz.Validate())
+
+	valid := &Quiz{Title: "Arithmetic", Questions: quiz.Questions[:1]}
+	assert.Empty(t, valid.Validate())
+}
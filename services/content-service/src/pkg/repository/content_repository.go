@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,20 +13,47 @@ import (
 	"QuizApp/services/content-service/src/pkg/models"
 )
 
-// ContentRepository defines the interface for quiz content operations
+// ContentRepository defines the interface for quiz content operations.
+//
+// A quiz and its questions form one aggregate: CreateQuiz and
+// UpdateQuizWithQuestions write both in a single transaction. Updates are
+// guarded by optimistic concurrency; quiz.Version must hold the version the
+// caller last read, and is set to the new version on success. Any change to
+// a quiz's questions also bumps its version.
+//
+// Questions are ordered by a 1-based Position. Question IDs are stable:
+// edits update questions in place so attempt answers keep pointing at them.
+//
+// Every write records an immutable revision of the whole aggregate in the
+// same transaction; quiz.RevisionID names the revision currently live.
+//
+// Listings only return quizzes the Viewer may read. Single-quiz reads and
+// writes are not filtered; callers authorize them with GetQuizAccess.
 type ContentRepository interface {
 	CreateQuiz(ctx context.Context, quiz *models.Quiz) error
 	GetQuiz(ctx context.Context, id uuid.UUID) (*models.Quiz, error)
-	ListQuizzes(ctx context.Context, page, pageSize int) ([]*models.Quiz, error)
+	ListQuizzes(ctx context.Context, viewer Viewer, page QuizPage) ([]*models.Quiz, int, *QuizCursor, error)
 	UpdateQuiz(ctx context.Context, quiz *models.Quiz) error
+	UpdateQuizWithQuestions(ctx context.Context, quiz *models.Quiz) error
+	ReorderQuestions(ctx context.Context, quizID uuid.UUID, questionIDs []uuid.UUID, expectedVersion int) (int, error)
+	ListRevisions(ctx context.Context, quizID uuid.UUID) ([]*models.QuizRevision, error)
+	GetRevision(ctx context.Context, quizID, revisionID uuid.UUID) (*models.QuizRevision, error)
+	RestoreRevision(ctx context.Context, quizID, revisionID uuid.UUID, expectedVersion int) error
 	DeleteQuiz(ctx context.Context, id uuid.UUID) error
-	AddQuestion(ctx context.Context, question *models.Question) error
+	RecordAttempt(ctx context.Context, quizID uuid.UUID) (int, error)
+	AddQuestion(ctx context.Context, question *models.Question, expectedVersion int) (int, error)
 	GetQuestion(ctx context.Context, id uuid.UUID) (*models.Question, error)
 	UpdateQuestion(ctx context.Context, question *models.Question) error
 	DeleteQuestion(ctx context.Context, id uuid.UUID) error
 	ListQuizQuestions(ctx context.Context, quizID uuid.UUID) ([]*models.Question, error)
-	ListUserQuizzes(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]*models.Quiz, error)
-	SearchQuizzes(ctx context.Context, query string, page, pageSize int) ([]*models.Quiz, error)
+	ListQuestionsByQuiz(ctx context.Context, quizIDs []uuid.UUID) (map[uuid.UUID][]*models.Question, error)
+	ListUserQuizzes(ctx context.Context, viewer Viewer, userID uuid.UUID, page QuizPage) ([]*models.Quiz, int, *QuizCursor, error)
+	ListTopicQuizzes(ctx context.Context, viewer Viewer, topicID uuid.UUID, page, pageSize int) ([]*models.Quiz, error)
+	SearchQuizzes(ctx context.Context, viewer Viewer, search QuizSearch, page QuizPage) ([]*models.QuizSearchHit, int, *QuizCursor, error)
+	ShareQuiz(ctx context.Context, access *models.QuizAccess) error
+	RevokeQuizAccess(ctx context.Context, quizID, userID uuid.UUID) error
+	GetQuizAccess(ctx context.Context, quizID, userID uuid.UUID) (*models.QuizAccess, error)
+	ListQuizAccess(ctx context.Context, quizID uuid.UUID) ([]*models.QuizAccess, error)
 }
 
 // PostgresContentRepository implements ContentRepository for PostgreSQL
@@ -32,6 +61,182 @@ type PostgresContentRepository struct {
 	db *sql.DB
 }
 
+// dbtx is satisfied by both *sql.DB and *sql.Tx so helpers can run inside or outside a transaction
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+const (
+	quizColumns     = `id, title, description, topic_id, creator_id, visibility, version, revision_id, draw_rules, settings, created_at, updated_at`
+	questionColumns = `id, quiz_id, position, text, type, difficulty, options, correct_answer, correct_answers, scoring, grading, answer_spec, explanation, created_at, updated_at`
+)
+
+// scanQuiz scans a row selected with quizColumns
+func scanQuiz(row rowScanner) (*models.Quiz, error) {
+	quiz := &models.Quiz{}
+	var draws, settings []byte
+	err := row.Scan(
+		&quiz.ID,
+		&quiz.Title,
+		&quiz.Description,
+		&quiz.TopicID,
+		&quiz.CreatorID,
+		&quiz.Visibility,
+		&quiz.Version,
+		&quiz.RevisionID,
+		&draws,
+		&settings,
+		&quiz.CreatedAt,
+		&quiz.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(draws) > 0 {
+		if err := json.Unmarshal(draws, &quiz.Draws); err != nil {
+			return nil, fmt.Errorf("invalid draw rules for quiz %s: %v", quiz.ID, err)
+		}
+	}
+	if len(settings) > 0 {
+		if err := json.Unmarshal(settings, &quiz.Settings); err != nil {
+			return nil, fmt.Errorf("invalid settings for quiz %s: %v", quiz.ID, err)
+		}
+	}
+	return quiz, nil
+}
+
+// scanQuestion scans a row selected with questionColumns
+func scanQuestion(row rowScanner) (*models.Question, error) {
+	question := &models.Question{}
+	var options, correctAnswers pq.StringArray
+	var difficulty, scoring, grading, explanation sql.NullString
+	var spec []byte
+	err := row.Scan(
+		&question.ID,
+		&question.QuizID,
+		&question.Position,
+		&question.Text,
+		&question.Type,
+		&difficulty,
+		&options,
+		&question.CorrectAnswer,
+		&correctAnswers,
+		&scoring,
+		&grading,
+		&spec,
+		&explanation,
+		&question.CreatedAt,
+		&question.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	question.Options = []string(options)
+	if len(correctAnswers) > 0 {
+		question.CorrectAnswers = []string(correctAnswers)
+	}
+	question.Difficulty = models.Difficulty(difficulty.String)
+	question.Scoring = models.ScoringMode(scoring.String)
+	question.Grading = models.GradingMode(grading.String)
+	question.Explanation = explanation.String
+	if err := readAnswerSpec(spec, question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// answerSpec holds the structured parts of a question, such as matching
+// prompts, answer keys and attachments, stored as JSON in questions.answer_spec
+type answerSpec struct {
+	Numeric         *models.NumericAnswer   `json:"numeric,omitempty"`
+	Prompts         []string                `json:"prompts,omitempty"`
+	Matches         []string                `json:"matches,omitempty"`
+	CorrectOrder    []string                `json:"correctOrder,omitempty"`
+	Blanks          []models.ClozeBlank     `json:"blanks,omitempty"`
+	AcceptedAnswers []models.AcceptedAnswer `json:"acceptedAnswers,omitempty"`
+	Rubric          string                  `json:"rubric,omitempty"`
+	Media           []models.Attachment     `json:"media,omitempty"`
+	OptionMedia     []*models.Attachment    `json:"optionMedia,omitempty"`
+}
+
+// writeAnswerSpec encodes a question's structured parts, or nil if it has none
+func writeAnswerSpec(question *models.Question) ([]byte, error) {
+	spec := answerSpec{
+		Numeric:         question.Numeric,
+		Prompts:         question.Prompts,
+		Matches:         question.Matches,
+		CorrectOrder:    question.CorrectOrder,
+		Blanks:          question.Blanks,
+		AcceptedAnswers: question.AcceptedAnswers,
+		Rubric:          question.Rubric,
+		Media:           question.Media,
+		OptionMedia:     question.OptionMedia,
+	}
+	if spec.Numeric == nil && len(spec.Prompts) == 0 && len(spec.Matches) == 0 &&
+		len(spec.CorrectOrder) == 0 && len(spec.Blanks) == 0 && len(spec.AcceptedAnswers) == 0 &&
+		spec.Rubric == "" && len(spec.Media) == 0 && len(spec.OptionMedia) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(spec)
+}
+
+// readAnswerSpec decodes the structured parts written by writeAnswerSpec
+func readAnswerSpec(data []byte, question *models.Question) error {
+	if len(data) == 0 {
+		return nil
+	}
+	var spec answerSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return fmt.Errorf("invalid answer spec for question %s: %v", question.ID, err)
+	}
+	question.Numeric = spec.Numeric
+	question.Prompts = spec.Prompts
+	question.Matches = spec.Matches
+	question.CorrectOrder = spec.CorrectOrder
+	question.Blanks = spec.Blanks
+	question.AcceptedAnswers = spec.AcceptedAnswers
+	question.Rubric = spec.Rubric
+	question.Media = spec.Media
+	question.OptionMedia = spec.OptionMedia
+	return nil
+}
+
+// orNoAnswers keeps a missing answer set from being written as NULL
+func orNoAnswers(answers []string) []string {
+	if answers == nil {
+		return []string{}
+	}
+	return answers
+}
+
+// writeDraws encodes a quiz's draw rules, or nil if it has none
+func writeDraws(quiz *models.Quiz) ([]byte, error) {
+	if len(quiz.Draws) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(quiz.Draws)
+}
+
+// scanQuizzes scans all rows selected with quizColumns
+func scanQuizzes(rows *sql.Rows) ([]*models.Quiz, error) {
+	var quizzes []*models.Quiz
+	for rows.Next() {
+		quiz, err := scanQuiz(rows)
+		if err != nil {
+			return nil, err
+		}
+		quizzes = append(quizzes, quiz)
+	}
+	return quizzes, rows.Err()
+}
+
 // NewPostgresContentRepository creates a new PostgreSQL content repository
 func NewPostgresContentRepository(db *sql.DB) *PostgresContentRepository {
 	return &PostgresContentRepository{db: db}
@@ -48,37 +253,56 @@ func (r *PostgresContentRepository) CreateQuiz(ctx context.Context, quiz *models
 	now := time.Now().UTC()
 	quiz.CreatedAt = now
 	quiz.UpdatedAt = now
+	quiz.Version = 1
 
+	draws, err := writeDraws(quiz)
+	if err != nil {
+		return err
+	}
+	settings, err := json.Marshal(quiz.Settings)
+	if err != nil {
+		return err
+	}
 	_, err = tx.ExecContext(ctx, `
-		INSERT INTO quizzes (id, title, description, topic_id, creator_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`, quiz.ID, quiz.Title, quiz.Description, quiz.TopicID, quiz.CreatorID, quiz.CreatedAt, quiz.UpdatedAt)
+		INSERT INTO quizzes (id, title, description, topic_id, creator_id, visibility, version, draw_rules, settings, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, quiz.ID, quiz.Title, quiz.Description, quiz.TopicID, quiz.CreatorID, quiz.Visibility, quiz.Version, draws, settings, quiz.CreatedAt, quiz.UpdatedAt)
 
+	if isForeignKeyViolation(err) {
+		return ErrTopicNotFound
+	}
 	if err != nil {
 		return err
 	}
 
+	for i, question := range quiz.Questions {
+		question.QuizID = quiz.ID
+		question.Position = i + 1
+		if err := insertQuestion(ctx, tx, question); err != nil {
+			return err
+		}
+	}
+
+	if quiz.RevisionID, err = quizChanged(ctx, tx, quiz.ID); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
 // GetQuiz gets a quiz by ID
 func (r *PostgresContentRepository) GetQuiz(ctx context.Context, id uuid.UUID) (*models.Quiz, error) {
+	return getQuiz(ctx, r.db, id)
+}
+
+// getQuiz loads a quiz and its questions
+func getQuiz(ctx context.Context, db dbtx, id uuid.UUID) (*models.Quiz, error) {
 	query := `
-		SELECT id, title, description, topic_id, creator_id, created_at, updated_at
+		SELECT ` + quizColumns + `
 		FROM quizzes
 		WHERE id = $1`
 
-	quiz := &models.Quiz{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&quiz.ID,
-		&quiz.Title,
-		&quiz.Description,
-		&quiz.TopicID,
-		&quiz.CreatorID,
-		&quiz.CreatedAt,
-		&quiz.UpdatedAt,
-	)
-
+	quiz, err := scanQuiz(db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, ErrQuizNotFound
 	}
@@ -87,7 +311,7 @@ func (r *PostgresContentRepository) GetQuiz(ctx context.Context, id uuid.UUID) (
 	}
 
 	// Get questions for this quiz
-	questions, err := r.ListQuizQuestions(ctx, id)
+	questions, err := listQuizQuestions(ctx, db, id)
 	if err != nil {
 		return nil, err
 	}
@@ -96,33 +320,156 @@ func (r *PostgresContentRepository) GetQuiz(ctx context.Context, id uuid.UUID) (
 	return quiz, nil
 }
 
-// UpdateQuiz updates an existing quiz
+// UpdateQuiz updates an existing quiz's metadata if quiz.Version is still current
 func (r *PostgresContentRepository) UpdateQuiz(ctx context.Context, quiz *models.Quiz) error {
-	quiz.UpdatedAt = time.Now().UTC()
-	result, err := r.db.ExecContext(ctx, `
-		UPDATE quizzes
-		SET title = $1, description = $2, topic_id = $3, updated_at = $4
-		WHERE id = $5
-	`, quiz.Title, quiz.Description, quiz.TopicID, quiz.UpdatedAt, quiz.ID)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
+	if err := updateQuizRow(ctx, tx, quiz); err != nil {
+		return err
+	}
+	if quiz.RevisionID, err = quizChanged(ctx, tx, quiz.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateQuizWithQuestions updates a quiz and replaces its question list in one
+// transaction. Questions whose ID already belongs to the quiz are updated in
+// place, questions without an ID are inserted, and questions left out are
+// deleted. List order becomes question order.
+func (r *PostgresContentRepository) UpdateQuizWithQuestions(ctx context.Context, quiz *models.Quiz) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	if err := updateQuizRow(ctx, tx, quiz); err != nil {
+		return err
+	}
+	if err := replaceQuestions(ctx, tx, quiz.ID, quiz.Questions, false); err != nil {
+		return err
+	}
+	if quiz.RevisionID, err = quizChanged(ctx, tx, quiz.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// replaceQuestions makes questions the quiz's full question list, in order.
+// Questions whose ID belongs to the quiz are updated in place and questions
+// without an ID are inserted. Other IDs are rejected unless revive is set, in
+// which case they are re-inserted under their original ID (used to restore
+// questions from a revision).
+func replaceQuestions(ctx context.Context, db dbtx, quizID uuid.UUID, questions []*models.Question, revive bool) error {
+	existing, err := quizQuestionIDs(ctx, db, quizID)
 	if err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return ErrQuizNotFound
+
+	kept := make(map[uuid.UUID]bool, len(questions))
+	keptIDs := make([]string, 0, len(questions))
+	for i, question := range questions {
+		question.QuizID = quizID
+		question.Position = i + 1
+
+		switch {
+		case kept[question.ID]:
+			return ErrInvalidInput
+		case question.ID == uuid.Nil:
+			question.ID = uuid.New()
+			err = insertQuestion(ctx, db, question)
+		case existing[question.ID]:
+			err = updateQuestionRow(ctx, db, question)
+		case revive:
+			err = insertQuestion(ctx, db, question)
+		default:
+			// Unknown IDs must not be grafted onto this quiz
+			return ErrInvalidInput
+		}
+		if err != nil {
+			return err
+		}
+
+		kept[question.ID] = true
+		keptIDs = append(keptIDs, question.ID.String())
 	}
 
-	return nil
+	_, err = db.ExecContext(ctx, `
+		DELETE FROM questions
+		WHERE quiz_id = $1 AND NOT (id = ANY($2::uuid[]))
+	`, quizID, pq.Array(keptIDs))
+	return err
 }
 
-// DeleteQuiz deletes a quiz by ID
-func (r *PostgresContentRepository) DeleteQuiz(ctx context.Context, id uuid.UUID) error {
-	result, err := r.db.ExecContext(ctx, "DELETE FROM quizzes WHERE id = $1", id)
+// updateQuizRow writes the quiz row, bumping its version, only if quiz.Version matches
+func updateQuizRow(ctx context.Context, db dbtx, quiz *models.Quiz) error {
+	quiz.UpdatedAt = time.Now().UTC()
+	draws, err := writeDraws(quiz)
+	if err != nil {
+		return err
+	}
+	settings, err := json.Marshal(quiz.Settings)
+	if err != nil {
+		return err
+	}
+	err = db.QueryRowContext(ctx, `
+		UPDATE quizzes
+		SET title = $1, description = $2, topic_id = $3, visibility = $4, draw_rules = $5, settings = $6,
+			updated_at = $7, version = version + 1
+		WHERE id = $8 AND version = $9
+		RETURNING version
+	`, quiz.Title, quiz.Description, quiz.TopicID, quiz.Visibility, draws, settings, quiz.UpdatedAt, quiz.ID, quiz.Version).Scan(&quiz.Version)
+
+	if err == sql.ErrNoRows {
+		return versionError(ctx, db, quiz.ID)
+	}
+	if isForeignKeyViolation(err) {
+		return ErrTopicNotFound
+	}
+	return err
+}
+
+// quizChanged does the bookkeeping every quiz write needs before it commits:
+// refreshing the quiz's search index and recording a revision
+func quizChanged(ctx context.Context, db dbtx, quizID uuid.UUID) (*uuid.UUID, error) {
+	if err := indexQuiz(ctx, db, quizID); err != nil {
+		return nil, err
+	}
+	return recordRevision(ctx, db, quizID)
+}
+
+// touchQuiz bumps a quiz's version after a change to its questions. The
+// change is rejected unless expectedVersion is still current.
+func touchQuiz(ctx context.Context, db dbtx, quizID uuid.UUID, expectedVersion int) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, `
+		UPDATE quizzes
+		SET version = version + 1, updated_at = $1
+		WHERE id = $2 AND version = $3
+		RETURNING version
+	`, time.Now().UTC(), quizID, expectedVersion).Scan(&version)
+
+	if err == sql.ErrNoRows {
+		return 0, versionError(ctx, db, quizID)
+	}
+	return version, err
+}
+
+// bumpQuizVersion bumps a quiz's version after a change to a single question,
+// which is made without a version precondition
+func bumpQuizVersion(ctx context.Context, db dbtx, quizID uuid.UUID) error {
+	result, err := db.ExecContext(ctx, `
+		UPDATE quizzes
+		SET version = version + 1, updated_at = $1
+		WHERE id = $2
+	`, time.Now().UTC(), quizID)
 	if err != nil {
 		return err
 	}
@@ -134,132 +481,148 @@ func (r *PostgresContentRepository) DeleteQuiz(ctx context.Context, id uuid.UUID
 	if rowsAffected == 0 {
 		return ErrQuizNotFound
 	}
-
 	return nil
 }
 
-// ListQuizzes lists all quizzes with pagination
-func (r *PostgresContentRepository) ListQuizzes(ctx context.Context, page, pageSize int) ([]*models.Quiz, error) {
-	query := `
-		SELECT id, title, description, topic_id, creator_id, created_at, updated_at
-		FROM quizzes
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2`
+// versionError explains why a version-guarded quiz update matched no row
+func versionError(ctx context.Context, db dbtx, quizID uuid.UUID) error {
+	var exists bool
+	if err := db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM quizzes WHERE id = $1)", quizID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrQuizNotFound
+	}
+	return ErrVersionConflict
+}
 
-	offset := (page - 1) * pageSize
-	rows, err := r.db.QueryContext(ctx, query, pageSize, offset)
+// quizQuestionIDs returns the set of question IDs that belong to a quiz
+func quizQuestionIDs(ctx context.Context, db dbtx, quizID uuid.UUID) (map[uuid.UUID]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id FROM questions WHERE quiz_id = $1", quizID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var quizzes []*models.Quiz
+	ids := make(map[uuid.UUID]bool)
 	for rows.Next() {
-		quiz := &models.Quiz{}
-		err := rows.Scan(
-			&quiz.ID,
-			&quiz.Title,
-			&quiz.Description,
-			&quiz.TopicID,
-			&quiz.CreatorID,
-			&quiz.CreatedAt,
-			&quiz.UpdatedAt,
-		)
-		if err != nil {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		quizzes = append(quizzes, quiz)
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// DeleteQuiz deletes a quiz by ID
+func (r *PostgresContentRepository) DeleteQuiz(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM quizzes WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrQuizNotFound
 	}
 
-	return quizzes, nil
+	return nil
 }
 
-// ListUserQuizzes lists all quizzes for a user with pagination
-func (r *PostgresContentRepository) ListUserQuizzes(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]*models.Quiz, error) {
+// ListTopicQuizzes lists the quizzes visible to viewer that are filed under a
+// topic or any of its subtopics, with pagination
+func (r *PostgresContentRepository) ListTopicQuizzes(ctx context.Context, viewer Viewer, topicID uuid.UUID, page, pageSize int) ([]*models.Quiz, error) {
 	query := `
-		SELECT id, title, description, topic_id, creator_id, created_at, updated_at
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM topics WHERE id = $1
+			UNION ALL
+			SELECT t.id FROM topics t JOIN subtree ON t.parent_id = subtree.id
+		)
+		SELECT ` + quizColumns + `
 		FROM quizzes
-		WHERE creator_id = $1
+		WHERE topic_id IN (SELECT id FROM subtree) AND ` + quizVisibleTo(2, 3) + `
 		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3`
+		LIMIT $4 OFFSET $5`
 
 	offset := (page - 1) * pageSize
-	rows, err := r.db.QueryContext(ctx, query, userID, pageSize, offset)
+	rows, err := r.db.QueryContext(ctx, query, topicID, viewer.Trusted, viewer.UserID, pageSize, offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var quizzes []*models.Quiz
-	for rows.Next() {
-		quiz := &models.Quiz{}
-		err := rows.Scan(
-			&quiz.ID,
-			&quiz.Title,
-			&quiz.Description,
-			&quiz.TopicID,
-			&quiz.CreatorID,
-			&quiz.CreatedAt,
-			&quiz.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		quizzes = append(quizzes, quiz)
-	}
-
-	return quizzes, nil
+	return scanQuizzes(rows)
 }
 
-// SearchQuizzes searches quizzes by title or description
-func (r *PostgresContentRepository) SearchQuizzes(ctx context.Context, query string, page, pageSize int) ([]*models.Quiz, error) {
-	searchQuery := `
-		SELECT id, title, description, topic_id, creator_id, created_at, updated_at
-		FROM quizzes
-		WHERE title ILIKE $1 OR description ILIKE $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3`
+// AddQuestion adds a new question to a quiz. A Position between 1 and the
+// current question count inserts the question there, shifting later questions
+// down; any other Position appends it. It returns the quiz's new version.
+func (r *PostgresContentRepository) AddQuestion(ctx context.Context, question *models.Question, expectedVersion int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
-	offset := (page - 1) * pageSize
-	searchPattern := "%" + query + "%"
-	rows, err := r.db.QueryContext(ctx, searchQuery, searchPattern, pageSize, offset)
+	// Touching the quiz first locks its row, serializing concurrent position shifts
+	version, err := touchQuiz(ctx, tx, question.QuizID, expectedVersion)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
 
-	var quizzes []*models.Quiz
-	for rows.Next() {
-		quiz := &models.Quiz{}
-		err := rows.Scan(
-			&quiz.ID,
-			&quiz.Title,
-			&quiz.Description,
-			&quiz.TopicID,
-			&quiz.CreatorID,
-			&quiz.CreatedAt,
-			&quiz.UpdatedAt,
-		)
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM questions WHERE quiz_id = $1", question.QuizID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	if question.Position < 1 || question.Position > count {
+		question.Position = count + 1
+	} else {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE questions SET position = position + 1
+			WHERE quiz_id = $1 AND position >= $2
+		`, question.QuizID, question.Position)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
-		quizzes = append(quizzes, quiz)
 	}
 
-	return quizzes, nil
+	if err := insertQuestion(ctx, tx, question); err != nil {
+		return 0, err
+	}
+	if _, err := quizChanged(ctx, tx, question.QuizID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return version, nil
 }
 
-// AddQuestion adds a new question to a quiz
-func (r *PostgresContentRepository) AddQuestion(ctx context.Context, question *models.Question) error {
+// insertQuestion inserts a single question row
+func insertQuestion(ctx context.Context, db dbtx, question *models.Question) error {
 	now := time.Now().UTC()
 	question.CreatedAt = now
 	question.UpdatedAt = now
 
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO questions (id, quiz_id, text, type, options, correct_answer, explanation, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`, question.ID, question.QuizID, question.Text, question.Type, pq.Array(question.Options),
-		question.CorrectAnswer, question.Explanation, question.CreatedAt, question.UpdatedAt)
+	spec, err := writeAnswerSpec(question)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO questions (
+			id, quiz_id, position, text, type, difficulty, options, correct_answer, correct_answers, scoring,
+			grading, answer_spec, explanation, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''), $12, $13, $14, $15)
+	`, question.ID, question.QuizID, question.Position, question.Text, question.Type, question.Difficulty, pq.Array(question.Options),
+		question.CorrectAnswer, pq.Array(orNoAnswers(question.CorrectAnswers)), question.Scoring,
+		question.Grading, spec, question.Explanation, question.CreatedAt, question.UpdatedAt)
 
 	return err
 }
@@ -267,24 +630,11 @@ func (r *PostgresContentRepository) AddQuestion(ctx context.Context, question *m
 // GetQuestion gets a question by ID
 func (r *PostgresContentRepository) GetQuestion(ctx context.Context, id uuid.UUID) (*models.Question, error) {
 	query := `
-		SELECT id, quiz_id, text, type, options, correct_answer, explanation, created_at, updated_at
+		SELECT ` + questionColumns + `
 		FROM questions
 		WHERE id = $1`
 
-	question := &models.Question{}
-	var options pq.StringArray
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&question.ID,
-		&question.QuizID,
-		&question.Text,
-		&question.Type,
-		&options,
-		&question.CorrectAnswer,
-		&question.Explanation,
-		&question.CreatedAt,
-		&question.UpdatedAt,
-	)
-
+	question, err := scanQuestion(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, ErrQuestionNotFound
 	}
@@ -292,19 +642,52 @@ func (r *PostgresContentRepository) GetQuestion(ctx context.Context, id uuid.UUI
 		return nil, err
 	}
 
-	question.Options = []string(options)
 	return question, nil
 }
 
-// UpdateQuestion updates an existing question
+// UpdateQuestion updates an existing question in place, keeping its ID and position
 func (r *PostgresContentRepository) UpdateQuestion(ctx context.Context, question *models.Question) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowContext(ctx, "SELECT quiz_id FROM questions WHERE id = $1", question.ID).Scan(&question.QuizID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrQuestionNotFound
+		}
+		return err
+	}
+	if err := bumpQuizVersion(ctx, tx, question.QuizID); err != nil {
+		return err
+	}
+	if err := updateQuestionRow(ctx, tx, question); err != nil {
+		return err
+	}
+	if _, err := quizChanged(ctx, tx, question.QuizID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateQuestionRow writes a question's content and position
+func updateQuestionRow(ctx context.Context, db dbtx, question *models.Question) error {
 	question.UpdatedAt = time.Now().UTC()
-	result, err := r.db.ExecContext(ctx, `
+	spec, err := writeAnswerSpec(question)
+	if err != nil {
+		return err
+	}
+	result, err := db.ExecContext(ctx, `
 		UPDATE questions
-		SET text = $1, type = $2, options = $3, correct_answer = $4, explanation = $5, updated_at = $6
-		WHERE id = $7
-	`, question.Text, question.Type, pq.Array(question.Options),
-		question.CorrectAnswer, question.Explanation, question.UpdatedAt, question.ID)
+		SET text = $1, type = $2, difficulty = NULLIF($3, ''), options = $4, correct_answer = $5, correct_answers = $6,
+			scoring = NULLIF($7, ''), grading = NULLIF($8, ''), answer_spec = $9, explanation = $10, updated_at = $11,
+			position = COALESCE(NULLIF($12, 0), position)
+		WHERE id = $13
+	`, question.Text, question.Type, question.Difficulty, pq.Array(question.Options),
+		question.CorrectAnswer, pq.Array(orNoAnswers(question.CorrectAnswers)), question.Scoring,
+		question.Grading, spec, question.Explanation, question.UpdatedAt, question.Position, question.ID)
 
 	if err != nil {
 		return err
@@ -321,31 +704,97 @@ func (r *PostgresContentRepository) UpdateQuestion(ctx context.Context, question
 	return nil
 }
 
-// DeleteQuestion deletes a question by ID
+// DeleteQuestion deletes a question by ID and closes the gap it leaves in the ordering
 func (r *PostgresContentRepository) DeleteQuestion(ctx context.Context, id uuid.UUID) error {
-	result, err := r.db.ExecContext(ctx, "DELETE FROM questions WHERE id = $1", id)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	var quizID uuid.UUID
+	var position int
+	err = tx.QueryRowContext(ctx, "DELETE FROM questions WHERE id = $1 RETURNING quiz_id, position", id).Scan(&quizID, &position)
+	if err == sql.ErrNoRows {
+		return ErrQuestionNotFound
+	}
 	if err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return ErrQuestionNotFound
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE questions SET position = position - 1
+		WHERE quiz_id = $1 AND position > $2
+	`, quizID, position)
+	if err != nil {
+		return err
+	}
+	if err := bumpQuizVersion(ctx, tx, quizID); err != nil {
+		return err
+	}
+	if _, err := quizChanged(ctx, tx, quizID); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+// ReorderQuestions sets the question order of a quiz. questionIDs must list
+// every question of the quiz exactly once. It returns the quiz's new version.
+func (r *PostgresContentRepository) ReorderQuestions(ctx context.Context, quizID uuid.UUID, questionIDs []uuid.UUID, expectedVersion int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err := touchQuiz(ctx, tx, quizID, expectedVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	existing, err := quizQuestionIDs(ctx, tx, quizID)
+	if err != nil {
+		return 0, err
+	}
+	if len(questionIDs) != len(existing) {
+		return 0, ErrInvalidInput
+	}
+
+	seen := make(map[uuid.UUID]bool, len(questionIDs))
+	for i, id := range questionIDs {
+		if !existing[id] || seen[id] {
+			return 0, ErrInvalidInput
+		}
+		seen[id] = true
+
+		if _, err := tx.ExecContext(ctx, "UPDATE questions SET position = $1 WHERE id = $2", i+1, id); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := quizChanged(ctx, tx, quizID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return version, nil
 }
 
-// ListQuizQuestions gets all questions for a quiz
+// ListQuizQuestions gets all questions for a quiz in position order
 func (r *PostgresContentRepository) ListQuizQuestions(ctx context.Context, quizID uuid.UUID) ([]*models.Question, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, quiz_id, text, type, options, correct_answer, explanation, created_at, updated_at
+	return listQuizQuestions(ctx, r.db, quizID)
+}
+
+// listQuizQuestions loads a quiz's questions in position order
+func listQuizQuestions(ctx context.Context, db dbtx, quizID uuid.UUID) ([]*models.Question, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+questionColumns+`
 		FROM questions
 		WHERE quiz_id = $1
-		ORDER BY created_at ASC
+		ORDER BY position ASC, created_at ASC
 	`, quizID)
 	if err != nil {
 		return nil, err
@@ -354,25 +803,12 @@ func (r *PostgresContentRepository) ListQuizQuestions(ctx context.Context, quizI
 
 	var questions []*models.Question
 	for rows.Next() {
-		q := &models.Question{}
-		var options pq.StringArray
-		err := rows.Scan(
-			&q.ID,
-			&q.QuizID,
-			&q.Text,
-			&q.Type,
-			&options,
-			&q.CorrectAnswer,
-			&q.Explanation,
-			&q.CreatedAt,
-			&q.UpdatedAt,
-		)
+		q, err := scanQuestion(rows)
 		if err != nil {
 			return nil, err
 		}
-		q.Options = []string(options)
 		questions = append(questions, q)
 	}
 
-	return questions, nil
-} 
\ No newline at end of file
+	return questions, rows.Err()
+}
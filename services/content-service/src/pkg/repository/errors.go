@@ -1,6 +1,10 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
 
 var (
 	// ErrQuizNotFound is returned when a quiz cannot be found
@@ -14,4 +18,55 @@ var (
 	
 	// ErrUnauthorized is returned when the user is not authorized
 	ErrUnauthorized = errors.New("unauthorized")
-) 
\ No newline at end of file
+
+	// ErrVersionConflict is returned when a quiz was modified since the caller read it
+	ErrVersionConflict = errors.New("quiz version conflict")
+
+	// ErrRevisionNotFound is returned when a quiz revision cannot be found
+	ErrRevisionNotFound = errors.New("quiz revision not found")
+
+	// ErrStudySetNotFound is returned when a study set cannot be found
+	ErrStudySetNotFound = errors.New("study set not found")
+
+	// ErrContentItemNotFound is returned when a content item cannot be found
+	ErrContentItemNotFound = errors.New("content item not found")
+
+	// ErrSharedAccessNotFound is returned when a user has no grant on a study set
+	ErrSharedAccessNotFound = errors.New("shared access not found")
+
+	// ErrTopicNotFound is returned when a topic cannot be found
+	ErrTopicNotFound = errors.New("topic not found")
+
+	// ErrTopicSlugTaken is returned when another topic already uses a slug
+	ErrTopicSlugTaken = errors.New("topic slug already in use")
+
+	// ErrTopicHasChildren is returned when deleting a topic that still has subtopics
+	ErrTopicHasChildren = errors.New("topic has subtopics")
+
+	// ErrTopicCycle is returned when a topic would become its own ancestor
+	ErrTopicCycle = errors.New("topic cannot be nested under itself")
+
+	// ErrMediaNotFound is returned when uploaded media cannot be found
+	ErrMediaNotFound = errors.New("media not found")
+
+	// ErrPoolNotFound is returned when a question pool cannot be found
+	ErrPoolNotFound = errors.New("question pool not found")
+
+	// ErrPoolInUse is returned when deleting a question pool that quizzes still draw from
+	ErrPoolInUse = errors.New("question pool is in use")
+
+	// ErrInvalidCursor is returned when a listing cursor is malformed or was made for another sort
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// isForeignKeyViolation reports whether err is a PostgreSQL foreign_key_violation
+func isForeignKeyViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23503"
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique_violation
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+} 
\ No newline at end of file
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// MediaRepository records uploaded media. The files themselves live in a
+// blob store; see the storage package.
+type MediaRepository interface {
+	CreateMedia(ctx context.Context, media *models.Media) error
+	GetMedia(ctx context.Context, id uuid.UUID) (*models.Media, error)
+	ListMedia(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Media, error)
+}
+
+// PostgresMediaRepository implements MediaRepository using PostgreSQL
+type PostgresMediaRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresMediaRepository creates a new PostgreSQL media repository
+func NewPostgresMediaRepository(db *sql.DB) *PostgresMediaRepository {
+	return &PostgresMediaRepository{db: db}
+}
+
+const mediaColumns = `id, owner_id, kind, content_type, size, width, height, has_thumbnail, created_at`
+
+// scanMedia scans a row selected with mediaColumns
+func scanMedia(row rowScanner) (*models.Media, error) {
+	media := &models.Media{}
+	var width, height sql.NullInt64
+	err := row.Scan(
+		&media.ID,
+		&media.OwnerID,
+		&media.Kind,
+		&media.ContentType,
+		&media.Size,
+		&width,
+		&height,
+		&media.HasThumbnail,
+		&media.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	media.Width = int(width.Int64)
+	media.Height = int(height.Int64)
+	return media, nil
+}
+
+// CreateMedia records an uploaded file
+func (r *PostgresMediaRepository) CreateMedia(ctx context.Context, media *models.Media) error {
+	var width, height sql.NullInt64
+	if media.Width > 0 {
+		width = sql.NullInt64{Int64: int64(media.Width), Valid: true}
+		height = sql.NullInt64{Int64: int64(media.Height), Valid: true}
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO media (`+mediaColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, media.ID, media.OwnerID, media.Kind, media.ContentType, media.Size, width, height, media.HasThumbnail, media.CreatedAt)
+	return err
+}
+
+// GetMedia gets uploaded media by ID
+func (r *PostgresMediaRepository) GetMedia(ctx context.Context, id uuid.UUID) (*models.Media, error) {
+	media, err := scanMedia(r.db.QueryRowContext(ctx, `SELECT `+mediaColumns+` FROM media WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrMediaNotFound
+	}
+	return media, err
+}
+
+// ListMedia gets the uploaded media with the given IDs; IDs with no media
+// are left out of the result
+func (r *PostgresMediaRepository) ListMedia(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Media, error) {
+	found := make(map[uuid.UUID]*models.Media, len(ids))
+	if len(ids) == 0 {
+		return found, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = id.String()
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+mediaColumns+` FROM media WHERE id = ANY($1::uuid[])`, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		media, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		found[media.ID] = media
+	}
+	return found, rows.Err()
+}
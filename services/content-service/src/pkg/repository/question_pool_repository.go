@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// QuestionPoolRepository defines the interface for question pool operations.
+//
+// A pool owns its questions; deleting the pool deletes them. Pools cannot be
+// deleted while a quiz draws from them. Authorization is left to callers.
+type QuestionPoolRepository interface {
+	CreatePool(ctx context.Context, pool *models.QuestionPool) error
+	GetPool(ctx context.Context, id uuid.UUID) (*models.QuestionPool, error)
+	ListPools(ctx context.Context, viewer Viewer, page, pageSize int) ([]*models.QuestionPool, error)
+	UpdatePool(ctx context.Context, pool *models.QuestionPool) error
+	DeletePool(ctx context.Context, id uuid.UUID) error
+	AddPoolQuestion(ctx context.Context, question *models.Question) error
+	UpdatePoolQuestion(ctx context.Context, question *models.Question) error
+	DeletePoolQuestion(ctx context.Context, poolID, questionID uuid.UUID) error
+	ListPoolQuestions(ctx context.Context, poolIDs []uuid.UUID) (map[uuid.UUID][]*models.Question, error)
+}
+
+// PostgresQuestionPoolRepository implements QuestionPoolRepository using PostgreSQL
+type PostgresQuestionPoolRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresQuestionPoolRepository creates a new PostgreSQL question pool repository
+func NewPostgresQuestionPoolRepository(db *sql.DB) *PostgresQuestionPoolRepository {
+	return &PostgresQuestionPoolRepository{db: db}
+}
+
+const (
+	poolColumns = `p.id, p.owner_id, p.title, p.description, p.visibility, p.created_at, p.updated_at,
+		(SELECT COUNT(*) FROM pool_questions q WHERE q.pool_id = p.id)`
+
+	// poolQuestionColumns matches questionColumns, with the pool in place of the quiz
+	poolQuestionColumns = `id, pool_id, position, text, type, difficulty, options, correct_answer, correct_answers, scoring, grading, answer_spec, explanation, created_at, updated_at`
+)
+
+// scanPool scans a row selected with poolColumns
+func scanPool(row rowScanner) (*models.QuestionPool, error) {
+	pool := &models.QuestionPool{}
+	var description sql.NullString
+	err := row.Scan(
+		&pool.ID,
+		&pool.OwnerID,
+		&pool.Title,
+		&description,
+		&pool.Visibility,
+		&pool.CreatedAt,
+		&pool.UpdatedAt,
+		&pool.QuestionCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	pool.Description = description.String
+	return pool, nil
+}
+
+// scanPoolQuestion scans a row selected with poolQuestionColumns
+func scanPoolQuestion(row rowScanner) (*models.Question, error) {
+	question, err := scanQuestion(row)
+	if err != nil {
+		return nil, err
+	}
+	poolID := question.QuizID
+	question.PoolID = &poolID
+	question.QuizID = uuid.Nil
+	return question, nil
+}
+
+// CreatePool creates a new, empty question pool
+func (r *PostgresQuestionPoolRepository) CreatePool(ctx context.Context, pool *models.QuestionPool) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO question_pools (id, owner_id, title, description, visibility, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, pool.ID, pool.OwnerID, pool.Title, pool.Description, pool.Visibility, pool.CreatedAt, pool.UpdatedAt)
+	return err
+}
+
+// GetPool gets a question pool by ID, without its questions
+func (r *PostgresQuestionPoolRepository) GetPool(ctx context.Context, id uuid.UUID) (*models.QuestionPool, error) {
+	pool, err := scanPool(r.db.QueryRowContext(ctx, `
+		SELECT `+poolColumns+`
+		FROM question_pools p
+		WHERE p.id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrPoolNotFound
+	}
+	return pool, err
+}
+
+// ListPools lists the pools visible to viewer, their own and public ones, newest first
+func (r *PostgresQuestionPoolRepository) ListPools(ctx context.Context, viewer Viewer, page, pageSize int) ([]*models.QuestionPool, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+poolColumns+`
+		FROM question_pools p
+		WHERE $1::boolean OR p.visibility = 'public' OR p.owner_id = $2
+		ORDER BY p.created_at DESC, p.id
+		LIMIT $3 OFFSET $4
+	`, viewer.Trusted, viewer.UserID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pools []*models.QuestionPool
+	for rows.Next() {
+		pool, err := scanPool(rows)
+		if err != nil {
+			return nil, err
+		}
+		pools = append(pools, pool)
+	}
+	return pools, rows.Err()
+}
+
+// UpdatePool updates a pool's title, description and visibility
+func (r *PostgresQuestionPoolRepository) UpdatePool(ctx context.Context, pool *models.QuestionPool) error {
+	pool.UpdatedAt = time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE question_pools
+		SET title = $1, description = $2, visibility = $3, updated_at = $4
+		WHERE id = $5
+	`, pool.Title, pool.Description, pool.Visibility, pool.UpdatedAt, pool.ID)
+	return requireRow(result, err, ErrPoolNotFound)
+}
+
+// DeletePool deletes a pool and its questions, unless a quiz draws from it
+func (r *PostgresQuestionPoolRepository) DeletePool(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM question_pools
+		WHERE id = $1 AND NOT EXISTS (
+			SELECT 1 FROM quizzes
+			WHERE draw_rules @> jsonb_build_array(jsonb_build_object('poolId', $1::text)))
+	`, id)
+	err = requireRow(result, err, ErrPoolNotFound)
+	if err != ErrPoolNotFound {
+		return err
+	}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM question_pools WHERE id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrPoolInUse
+	}
+	return ErrPoolNotFound
+}
+
+// AddPoolQuestion appends a question to the pool named by question.PoolID
+func (r *PostgresQuestionPoolRepository) AddPoolQuestion(ctx context.Context, question *models.Question) error {
+	now := time.Now().UTC()
+	question.CreatedAt = now
+	question.UpdatedAt = now
+
+	spec, err := writeAnswerSpec(question)
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO pool_questions (
+			id, pool_id, position, text, type, difficulty, options, correct_answer, correct_answers, scoring,
+			grading, answer_spec, explanation, created_at, updated_at
+		)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1, $3, $4, NULLIF($5, ''), $6, $7, $8, NULLIF($9, ''),
+			NULLIF($10, ''), $11, $12, $13, $14
+		FROM pool_questions
+		WHERE pool_id = $2
+		RETURNING position
+	`, question.ID, question.PoolID, question.Text, question.Type, question.Difficulty, pq.Array(question.Options),
+		question.CorrectAnswer, pq.Array(orNoAnswers(question.CorrectAnswers)), question.Scoring,
+		question.Grading, spec, question.Explanation, question.CreatedAt, question.UpdatedAt).Scan(&question.Position)
+
+	if isForeignKeyViolation(err) {
+		return ErrPoolNotFound
+	}
+	return err
+}
+
+// UpdatePoolQuestion updates a question of the pool named by question.PoolID in place
+func (r *PostgresQuestionPoolRepository) UpdatePoolQuestion(ctx context.Context, question *models.Question) error {
+	question.UpdatedAt = time.Now().UTC()
+	spec, err := writeAnswerSpec(question)
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRowContext(ctx, `
+		UPDATE pool_questions
+		SET text = $1, type = $2, difficulty = NULLIF($3, ''), options = $4, correct_answer = $5, correct_answers = $6,
+			scoring = NULLIF($7, ''), grading = NULLIF($8, ''), answer_spec = $9, explanation = $10, updated_at = $11
+		WHERE id = $12 AND pool_id = $13
+		RETURNING position, created_at
+	`, question.Text, question.Type, question.Difficulty, pq.Array(question.Options),
+		question.CorrectAnswer, pq.Array(orNoAnswers(question.CorrectAnswers)), question.Scoring,
+		question.Grading, spec, question.Explanation, question.UpdatedAt, question.ID, question.PoolID,
+	).Scan(&question.Position, &question.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrQuestionNotFound
+	}
+	return err
+}
+
+// DeletePoolQuestion deletes a question from a pool and closes the gap it leaves in the ordering
+func (r *PostgresQuestionPoolRepository) DeletePoolQuestion(ctx context.Context, poolID, questionID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var position int
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM pool_questions
+		WHERE id = $1 AND pool_id = $2
+		RETURNING position
+	`, questionID, poolID).Scan(&position)
+	if err == sql.ErrNoRows {
+		return ErrQuestionNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE pool_questions
+		SET position = position - 1
+		WHERE pool_id = $1 AND position > $2
+	`, poolID, position); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListPoolQuestions gets the questions of the given pools in order, keyed by
+// pool ID; pools without questions are left out of the result
+func (r *PostgresQuestionPoolRepository) ListPoolQuestions(ctx context.Context, poolIDs []uuid.UUID) (map[uuid.UUID][]*models.Question, error) {
+	questions := make(map[uuid.UUID][]*models.Question, len(poolIDs))
+	if len(poolIDs) == 0 {
+		return questions, nil
+	}
+
+	keys := make([]string, len(poolIDs))
+	for i, id := range poolIDs {
+		keys[i] = id.String()
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+poolQuestionColumns+`
+		FROM pool_questions
+		WHERE pool_id = ANY($1::uuid[])
+		ORDER BY pool_id, position
+	`, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		question, err := scanPoolQuestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		questions[*question.PoolID] = append(questions[*question.PoolID], question)
+	}
+	return questions, rows.Err()
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// QuizSort orders a quiz listing
+type QuizSort string
+
+const (
+	// QuizSortNewest lists the most recently created quizzes first
+	QuizSortNewest QuizSort = "newest"
+	// QuizSortTitle lists quizzes alphabetically by title
+	QuizSortTitle QuizSort = "title"
+	// QuizSortMostAttempted lists the quizzes learners attempted most first
+	QuizSortMostAttempted QuizSort = "most_attempted"
+	// QuizSortRelevance lists the best matches first; only searches sort by it
+	QuizSortRelevance QuizSort = "relevance"
+)
+
+// MaxQuizPageSize bounds how many quizzes one page of a listing holds
+const MaxQuizPageSize = 100
+
+// Valid reports whether s is a known sort
+func (s QuizSort) Valid() bool {
+	switch s {
+	case QuizSortNewest, QuizSortTitle, QuizSortMostAttempted, QuizSortRelevance:
+		return true
+	}
+	return false
+}
+
+// QuizPage selects a page of a quiz listing: up to Limit quizzes in Sort
+// order, following the quiz After points at, or from the start without it
+type QuizPage struct {
+	Sort  QuizSort
+	After *QuizCursor
+	Limit int
+}
+
+// QuizCursor points at the last quiz of a page by its ID and the key the
+// listing is sorted on. Listings resume after that key instead of skipping
+// rows, so quizzes added or deleted meanwhile do not shift later pages.
+// Quizzes attempted while a client pages by attempts may move past it.
+type QuizCursor struct {
+	Sort      QuizSort   `json:"s"`
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+	Title     *string    `json:"t,omitempty"`
+	Attempts  *int       `json:"a,omitempty"`
+	Rank      *float64   `json:"r,omitempty"`
+}
+
+// Encode turns the cursor into an opaque, URL-safe token
+func (c *QuizCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeQuizCursor reads a token made by Encode for a listing sorted by sort
+func DecodeQuizCursor(token string, sort QuizSort) (*QuizCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := &QuizCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.Sort != sort || cursor.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	var key bool
+	switch sort {
+	case QuizSortTitle:
+		key = cursor.Title != nil
+	case QuizSortMostAttempted:
+		key = cursor.Attempts != nil
+	case QuizSortRelevance:
+		key = cursor.Rank != nil
+	default:
+		key = cursor.CreatedAt != nil
+	}
+	if !key {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// keyset returns the ORDER BY clause of the page's sort and, with a cursor,
+// the condition selecting the quizzes after it. rank is the expression
+// search results are ranked by; arg adds a query argument.
+func (p QuizPage) keyset(rank string, arg func(interface{}) string) (order, after string) {
+	compare := func(key, op string, value interface{}) string {
+		if p.After == nil {
+			return ""
+		}
+		return fmt.Sprintf("(%s, quizzes.id) %s (%s, %s)", key, op, arg(value), arg(p.After.ID))
+	}
+
+	switch p.Sort {
+	case QuizSortTitle:
+		var title interface{}
+		if p.After != nil {
+			title = *p.After.Title
+		}
+		return "quizzes.title, quizzes.id", compare("quizzes.title", ">", title)
+	case QuizSortMostAttempted:
+		var attempts interface{}
+		if p.After != nil {
+			attempts = *p.After.Attempts
+		}
+		return "quizzes.attempt_count DESC, quizzes.id DESC", compare("quizzes.attempt_count", "<", attempts)
+	case QuizSortRelevance:
+		var score interface{}
+		if p.After != nil {
+			score = *p.After.Rank
+		}
+		return rank + " DESC, quizzes.id DESC", compare(rank, "<", score)
+	default:
+		var createdAt interface{}
+		if p.After != nil {
+			createdAt = *p.After.CreatedAt
+		}
+		return "quizzes.created_at DESC, quizzes.id DESC", compare("quizzes.created_at", "<", createdAt)
+	}
+}
+
+// cursorAt returns the cursor pointing at quiz, which search ranked rank
+func (p QuizPage) cursorAt(quiz *models.Quiz, rank float64) *QuizCursor {
+	cursor := &QuizCursor{Sort: p.Sort, ID: quiz.ID}
+	switch p.Sort {
+	case QuizSortTitle:
+		cursor.Title = &quiz.Title
+	case QuizSortMostAttempted:
+		cursor.Attempts = &quiz.AttemptCount
+	case QuizSortRelevance:
+		cursor.Rank = &rank
+	default:
+		cursor.CreatedAt = &quiz.CreatedAt
+	}
+	return cursor
+}
+
+// listingColumns follow quizColumns in listings: the number of questions and attempts
+const listingColumns = `(SELECT COUNT(*) FROM questions WHERE questions.quiz_id = quizzes.id), quizzes.attempt_count`
+
+// scanListedQuiz scans a row selected with quizColumns and listingColumns,
+// followed by any extra columns
+func scanListedQuiz(row rowScanner, extra ...interface{}) (*models.Quiz, error) {
+	var questionCount, attemptCount int
+	quiz, err := scanQuiz(withExtraColumns(row, append([]interface{}{&questionCount, &attemptCount}, extra...)...))
+	if err != nil {
+		return nil, err
+	}
+	quiz.QuestionCount = questionCount
+	quiz.AttemptCount = attemptCount
+	return quiz, nil
+}
+
+// ListQuizzes lists a page of the quizzes visible to viewer. It also returns
+// how many quizzes the listing holds and the cursor of the next page, which
+// is nil on the last page.
+func (r *PostgresContentRepository) ListQuizzes(ctx context.Context, viewer Viewer, page QuizPage) ([]*models.Quiz, int, *QuizCursor, error) {
+	return r.listQuizzes(ctx, page, []interface{}{viewer.Trusted, viewer.UserID}, quizVisibleTo(1, 2))
+}
+
+// ListUserQuizzes lists a page of a user's quizzes that are visible to viewer, as ListQuizzes does
+func (r *PostgresContentRepository) ListUserQuizzes(ctx context.Context, viewer Viewer, userID uuid.UUID, page QuizPage) ([]*models.Quiz, int, *QuizCursor, error) {
+	return r.listQuizzes(ctx, page, []interface{}{viewer.Trusted, viewer.UserID, userID},
+		quizVisibleTo(1, 2), "quizzes.creator_id = $3")
+}
+
+// listQuizzes lists a page of the quizzes meeting every condition, which
+// refer to args by position
+func (r *PostgresContentRepository) listQuizzes(ctx context.Context, page QuizPage, args []interface{}, conditions ...string) ([]*models.Quiz, int, *QuizCursor, error) {
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := strings.Join(conditions, "\n\t\t\tAND ")
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM quizzes WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, nil, err
+	}
+
+	order, after := page.keyset("", arg)
+	if after != "" {
+		where += "\n\t\t\tAND " + after
+	}
+	query := `
+		SELECT ` + prefixColumns("quizzes", quizColumns) + `, ` + listingColumns + `
+		FROM quizzes
+		WHERE ` + where + `
+		ORDER BY ` + order + `
+		LIMIT ` + arg(page.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer rows.Close()
+
+	quizzes := []*models.Quiz{}
+	for rows.Next() {
+		quiz, err := scanListedQuiz(rows)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		quizzes = append(quizzes, quiz)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, nil, err
+	}
+
+	// One quiz more than the page holds is fetched to tell whether another page follows
+	var next *QuizCursor
+	if len(quizzes) > page.Limit {
+		quizzes = quizzes[:page.Limit]
+		next = page.cursorAt(quizzes[len(quizzes)-1], 0)
+	}
+	return quizzes, total, next, nil
+}
+
+// ListQuestionsByQuiz gets the questions of the given quizzes in one query,
+// in position order and keyed by quiz ID; quizzes without questions are
+// left out of the result
+func (r *PostgresContentRepository) ListQuestionsByQuiz(ctx context.Context, quizIDs []uuid.UUID) (map[uuid.UUID][]*models.Question, error) {
+	questions := make(map[uuid.UUID][]*models.Question, len(quizIDs))
+	if len(quizIDs) == 0 {
+		return questions, nil
+	}
+
+	keys := make([]string, len(quizIDs))
+	for i, id := range quizIDs {
+		keys[i] = id.String()
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+questionColumns+`
+		FROM questions
+		WHERE quiz_id = ANY($1::uuid[])
+		ORDER BY quiz_id, position ASC, created_at ASC
+	`, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		question, err := scanQuestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		questions[question.QuizID] = append(questions[question.QuizID], question)
+	}
+	return questions, rows.Err()
+}
+
+// RecordAttempt counts an attempt a learner started at a quiz and returns
+// the quiz's new attempt count. It does not change the quiz's version.
+func (r *PostgresContentRepository) RecordAttempt(ctx context.Context, quizID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE quizzes
+		SET attempt_count = attempt_count + 1
+		WHERE id = $1
+		RETURNING attempt_count
+	`, quizID).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, ErrQuizNotFound
+	}
+	return count, err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+func TestQuizCursor(t *testing.T) {
+	quiz := &models.Quiz{ID: uuid.New(), Title: "Algebra", AttemptCount: 7, CreatedAt: time.Now().UTC()}
+
+	for _, sort := range []QuizSort{QuizSortNewest, QuizSortTitle, QuizSortMostAttempted, QuizSortRelevance} {
+		t.Run(string(sort), func(t *testing.T) {
+			cursor := QuizPage{Sort: sort}.cursorAt(quiz, 0.25)
+			decoded, err := DecodeQuizCursor(cursor.Encode(), sort)
+			require.NoError(t, err)
+			assert.Equal(t, quiz.ID, decoded.ID)
+
+			var args []interface{}
+			_, after := QuizPage{Sort: sort, After: decoded}.keyset("rank", func(value interface{}) string {
+				args = append(args, value)
+				return "?"
+			})
+			assert.NotEmpty(t, after)
+			require.Len(t, args, 2)
+			assert.Equal(t, quiz.ID, args[1])
+		})
+	}
+
+	token := QuizPage{Sort: QuizSortTitle}.cursorAt(quiz, 0).Encode()
+	_, err := DecodeQuizCursor(token, QuizSortNewest)
+	assert.ErrorIs(t, err, ErrInvalidCursor, "cursor made for another sort")
+	_, err = DecodeQuizCursor("not a cursor", QuizSortNewest)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// recordRevision snapshots the quiz as it stands inside the transaction,
+// stores it as an immutable revision and marks it as the live revision
+func recordRevision(ctx context.Context, db dbtx, quizID uuid.UUID) (*uuid.UUID, error) {
+	quiz, err := getQuiz(ctx, db, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisionID := uuid.New()
+	quiz.RevisionID = &revisionID
+
+	snapshot, err := json.Marshal(quiz)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO quiz_revisions (id, quiz_id, version, snapshot, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, revisionID, quizID, quiz.Version, snapshot, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.ExecContext(ctx, "UPDATE quizzes SET revision_id = $1 WHERE id = $2", revisionID, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &revisionID, nil
+}
+
+// ListRevisions lists a quiz's revisions, newest first, without their snapshots
+func (r *PostgresContentRepository) ListRevisions(ctx context.Context, quizID uuid.UUID) ([]*models.QuizRevision, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, quiz_id, version, created_at
+		FROM quiz_revisions
+		WHERE quiz_id = $1
+		ORDER BY version DESC
+	`, quizID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*models.QuizRevision
+	for rows.Next() {
+		revision := &models.QuizRevision{}
+		if err := rows.Scan(&revision.ID, &revision.QuizID, &revision.Version, &revision.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+// GetRevision gets a single revision of a quiz, including its snapshot
+func (r *PostgresContentRepository) GetRevision(ctx context.Context, quizID, revisionID uuid.UUID) (*models.QuizRevision, error) {
+	return getRevision(ctx, r.db, quizID, revisionID)
+}
+
+// getRevision loads a revision and decodes its snapshot
+func getRevision(ctx context.Context, db dbtx, quizID, revisionID uuid.UUID) (*models.QuizRevision, error) {
+	revision := &models.QuizRevision{}
+	var snapshot []byte
+	err := db.QueryRowContext(ctx, `
+		SELECT id, quiz_id, version, snapshot, created_at
+		FROM quiz_revisions
+		WHERE id = $1 AND quiz_id = $2
+	`, revisionID, quizID).Scan(&revision.ID, &revision.QuizID, &revision.Version, &snapshot, &revision.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(snapshot, &revision.Quiz); err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// RestoreRevision makes an old revision's content live again. The restore is
+// itself a change: it bumps the quiz version and records a new revision, so
+// history is never rewritten. Questions keep the IDs they had in the revision.
+// The restore is rejected unless expectedVersion is still current.
+func (r *PostgresContentRepository) RestoreRevision(ctx context.Context, quizID, revisionID uuid.UUID, expectedVersion int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	revision, err := getRevision(ctx, tx, quizID, revisionID)
+	if err != nil {
+		return err
+	}
+
+	// Visibility is access control, not content, so a restore keeps the current one
+	quiz := revision.Quiz
+	err = tx.QueryRowContext(ctx, "SELECT visibility FROM quizzes WHERE id = $1", quizID).Scan(&quiz.Visibility)
+	if err == sql.ErrNoRows {
+		return ErrQuizNotFound
+	}
+	if err != nil {
+		return err
+	}
+	quiz.Version = expectedVersion
+
+	if err := updateQuizRow(ctx, tx, quiz); err != nil {
+		return err
+	}
+	if err := replaceQuestions(ctx, tx, quizID, quiz.Questions, true); err != nil {
+		return err
+	}
+	if _, err := quizChanged(ctx, tx, quizID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// searchConfig is the text search configuration used for indexing and queries
+const searchConfig = "english"
+
+// Snippet highlights are delimited by control characters rather than HTML,
+// so the text around them can be escaped before the <mark> tags go in
+const (
+	snippetStart = "\x02"
+	snippetStop  = "\x03"
+)
+
+// QuizSearch is a full-text search over quiz titles, descriptions and
+// question text. Query uses web search syntax: quoted phrases, "or" and
+// -excluded terms. Zero-valued filters are ignored.
+type QuizSearch struct {
+	Query         string
+	TopicID       *uuid.UUID
+	CreatorID     *uuid.UUID
+	QuestionType  models.QuestionType
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// indexQuiz refreshes a quiz's search vector from its title (weight A),
+// description (B) and question text (C)
+func indexQuiz(ctx context.Context, db dbtx, quizID uuid.UUID) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE quizzes SET search_vector =
+			setweight(to_tsvector('`+searchConfig+`', title), 'A') ||
+			setweight(to_tsvector('`+searchConfig+`', COALESCE(description, '')), 'B') ||
+			setweight(to_tsvector('`+searchConfig+`', COALESCE(
+				(SELECT string_agg(text, ' ' ORDER BY position) FROM questions WHERE quiz_id = quizzes.id), '')), 'C')
+		WHERE id = $1
+	`, quizID)
+	return err
+}
+
+// SearchQuizzes searches a page of the quizzes visible to viewer, as
+// ListQuizzes lists them; page.Sort may also rank the best matches first.
+// It also returns the total number of matches across all pages.
+func (r *PostgresContentRepository) SearchQuizzes(ctx context.Context, viewer Viewer, search QuizSearch, page QuizPage) ([]*models.QuizSearchHit, int, *QuizCursor, error) {
+	args := []interface{}{viewer.Trusted, viewer.UserID, search.Query}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{
+		quizVisibleTo(1, 2),
+		"quizzes.search_vector @@ search.query",
+	}
+	if search.TopicID != nil {
+		conditions = append(conditions, "quizzes.topic_id = "+arg(*search.TopicID))
+	}
+	if search.CreatorID != nil {
+		conditions = append(conditions, "quizzes.creator_id = "+arg(*search.CreatorID))
+	}
+	if search.QuestionType != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM questions WHERE questions.quiz_id = quizzes.id AND questions.type = `+arg(search.QuestionType)+`)`)
+	}
+	if search.CreatedAfter != nil {
+		conditions = append(conditions, "quizzes.created_at >= "+arg(*search.CreatedAfter))
+	}
+	if search.CreatedBefore != nil {
+		conditions = append(conditions, "quizzes.created_at < "+arg(*search.CreatedBefore))
+	}
+
+	from := `
+		FROM quizzes, (SELECT websearch_to_tsquery('` + searchConfig + `', $3) AS query) search
+		WHERE `
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*)"+from+strings.Join(conditions, "\n\t\t\tAND "), args...).Scan(&total); err != nil {
+		return nil, 0, nil, err
+	}
+	if total == 0 {
+		return []*models.QuizSearchHit{}, 0, nil, nil
+	}
+
+	// Ranks are compared with cursors at double precision, so they are
+	// selected that way too
+	rank := "ts_rank_cd(quizzes.search_vector, search.query)::float8"
+	order, after := page.keyset(rank, arg)
+	if after != "" {
+		conditions = append(conditions, after)
+	}
+
+	headline := func(text string) string {
+		return `ts_headline('` + searchConfig + `', ` + text + `, search.query, 'StartSel=` + snippetStart + `, StopSel=` + snippetStop + `, MaxFragments=1, MaxWords=20, MinWords=5')`
+	}
+	query := `
+		SELECT ` + prefixColumns("quizzes", quizColumns) + `, ` + listingColumns + `,
+			` + rank + `,
+			CASE WHEN to_tsvector('` + searchConfig + `', quizzes.title) @@ search.query
+				THEN ` + headline("quizzes.title") + ` ELSE '' END,
+			CASE WHEN to_tsvector('` + searchConfig + `', COALESCE(quizzes.description, '')) @@ search.query
+				THEN ` + headline("COALESCE(quizzes.description, '')") + ` ELSE '' END,
+			COALESCE((
+				SELECT ` + headline("questions.text") + `
+				FROM questions
+				WHERE questions.quiz_id = quizzes.id
+					AND to_tsvector('` + searchConfig + `', questions.text) @@ search.query
+				ORDER BY ts_rank(to_tsvector('` + searchConfig + `', questions.text), search.query) DESC, questions.position
+				LIMIT 1
+			), '')` + from + strings.Join(conditions, "\n\t\t\tAND ") + `
+		ORDER BY ` + order + `
+		LIMIT ` + arg(page.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer rows.Close()
+
+	hits := []*models.QuizSearchHit{}
+	for rows.Next() {
+		hit := &models.QuizSearchHit{}
+		hit.Quiz, err = scanListedQuiz(rows, &hit.Rank, &hit.Snippets.Title, &hit.Snippets.Description, &hit.Snippets.Question)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		hit.Snippets.Title = markSnippet(hit.Snippets.Title)
+		hit.Snippets.Description = markSnippet(hit.Snippets.Description)
+		hit.Snippets.Question = markSnippet(hit.Snippets.Question)
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, nil, err
+	}
+
+	var next *QuizCursor
+	if len(hits) > page.Limit {
+		hits = hits[:page.Limit]
+		last := hits[len(hits)-1]
+		next = page.cursorAt(last.Quiz, last.Rank)
+	}
+	return hits, total, next, nil
+}
+
+// markSnippet HTML-escapes a ts_headline fragment, whose text is written by
+// quiz authors, and then turns its highlight delimiters into <mark> tags
+func markSnippet(snippet string) string {
+	return strings.NewReplacer(snippetStart, "<mark>", snippetStop, "</mark>").Replace(html.EscapeString(snippet))
+}
+
+// prefixColumns qualifies each column in a comma-separated list with table
+func prefixColumns(table, columns string) string {
+	parts := strings.Split(columns, ",")
+	for i, column := range parts {
+		parts[i] = table + "." + strings.TrimSpace(column)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// extraColumns lets a scan helper read a row that has additional trailing columns
+type extraColumns struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+// Scan scans the row into dest followed by the extra destinations
+func (e extraColumns) Scan(dest ...interface{}) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+// withExtraColumns scans the given destinations after the helper's own columns
+func withExtraColumns(row rowScanner, extra ...interface{}) rowScanner {
+	return extraColumns{row: row, extra: extra}
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkSnippet(t *testing.T) {
+	snippet := `<img src=x onerror=alert(1)> ` + snippetStart + `Capitals` + snippetStop + ` & "rivers"`
+
+	assert.Equal(t,
+		`&lt;img src=x onerror=alert(1)&gt; <mark>Capitals</mark> &amp; &#34;rivers&#34;`,
+		markSnippet(snippet))
+	assert.Equal(t, "", markSnippet(""))
+}
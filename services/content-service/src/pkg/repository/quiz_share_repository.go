@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// Viewer identifies who a listing is for. Trusted viewers (internal
+// services) see every quiz; a zero UserID sees public quizzes only.
+type Viewer struct {
+	UserID  uuid.UUID
+	Trusted bool
+}
+
+// quizVisibleTo returns a WHERE condition limiting quizzes to those a viewer
+// may read, given the placeholders holding Viewer.Trusted and Viewer.UserID
+func quizVisibleTo(trustedArg, userArg int) string {
+	return fmt.Sprintf(`($%[1]d::boolean
+			OR quizzes.visibility = 'public'
+			OR quizzes.creator_id = $%[2]d
+			OR (quizzes.visibility = 'shared' AND EXISTS (
+				SELECT 1 FROM quiz_shares s WHERE s.quiz_id = quizzes.id AND s.user_id = $%[2]d)))`, trustedArg, userArg)
+}
+
+// ShareQuiz grants a user access to a quiz, replacing any earlier grant
+func (r *PostgresContentRepository) ShareQuiz(ctx context.Context, access *models.QuizAccess) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO quiz_shares (quiz_id, user_id, access_type, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (quiz_id, user_id) DO UPDATE SET access_type = EXCLUDED.access_type
+	`, access.QuizID, access.UserID, access.AccessType, access.CreatedAt)
+	if isForeignKeyViolation(err) {
+		// The quiz does not exist
+		return ErrQuizNotFound
+	}
+	return err
+}
+
+// RevokeQuizAccess removes a user's grant on a quiz
+func (r *PostgresContentRepository) RevokeQuizAccess(ctx context.Context, quizID, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM quiz_shares WHERE quiz_id = $1 AND user_id = $2", quizID, userID)
+	return requireRow(result, err, ErrSharedAccessNotFound)
+}
+
+// GetQuizAccess gets a user's grant on a quiz
+func (r *PostgresContentRepository) GetQuizAccess(ctx context.Context, quizID, userID uuid.UUID) (*models.QuizAccess, error) {
+	access := &models.QuizAccess{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT quiz_id, user_id, access_type, created_at
+		FROM quiz_shares
+		WHERE quiz_id = $1 AND user_id = $2
+	`, quizID, userID).Scan(&access.QuizID, &access.UserID, &access.AccessType, &access.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrSharedAccessNotFound
+	}
+	return access, err
+}
+
+// ListQuizAccess lists the grants on a quiz
+func (r *PostgresContentRepository) ListQuizAccess(ctx context.Context, quizID uuid.UUID) ([]*models.QuizAccess, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT quiz_id, user_id, access_type, created_at
+		FROM quiz_shares
+		WHERE quiz_id = $1
+		ORDER BY created_at ASC
+	`, quizID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []*models.QuizAccess
+	for rows.Next() {
+		access := &models.QuizAccess{}
+		if err := rows.Scan(&access.QuizID, &access.UserID, &access.AccessType, &access.CreatedAt); err != nil {
+			return nil, err
+		}
+		grants = append(grants, access)
+	}
+	return grants, rows.Err()
+}
@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// StudySetFilter narrows the study sets returned by ListStudySets
+type StudySetFilter struct {
+	// ViewerID limits results to sets the viewer may read: public sets, their
+	// own sets and sets shared with them. uuid.Nil sees public sets only.
+	ViewerID uuid.UUID
+	// AllVisible skips the viewer check (trusted internal callers)
+	AllVisible bool
+	OwnerID    *uuid.UUID
+	Visibility models.VisibilityType
+	// Tags a set must all carry
+	Tags []string
+}
+
+// StudySetRepository defines the interface for study set operations.
+//
+// A study set owns its content items and share grants; deleting the set
+// deletes both. Authorization is left to callers, which can use GetAccess
+// to look up a user's grant on a set.
+type StudySetRepository interface {
+	CreateStudySet(ctx context.Context, set *models.StudySet) error
+	GetStudySet(ctx context.Context, id uuid.UUID) (*models.StudySet, error)
+	ListStudySets(ctx context.Context, filter StudySetFilter, page, pageSize int) ([]*models.StudySet, error)
+	UpdateStudySet(ctx context.Context, set *models.StudySet) error
+	DeleteStudySet(ctx context.Context, id uuid.UUID) error
+	AddContentItem(ctx context.Context, item *models.ContentItem) error
+	GetContentItem(ctx context.Context, id uuid.UUID) (*models.ContentItem, error)
+	UpdateContentItem(ctx context.Context, item *models.ContentItem) error
+	DeleteContentItem(ctx context.Context, id uuid.UUID) error
+	ListContentItems(ctx context.Context, studySetID uuid.UUID) ([]*models.ContentItem, error)
+	ShareStudySet(ctx context.Context, access *models.SharedAccess) error
+	RevokeAccess(ctx context.Context, studySetID, userID uuid.UUID) error
+	GetAccess(ctx context.Context, studySetID, userID uuid.UUID) (*models.SharedAccess, error)
+	ListSharedAccess(ctx context.Context, studySetID uuid.UUID) ([]*models.SharedAccess, error)
+}
+
+// PostgresStudySetRepository implements StudySetRepository using PostgreSQL
+type PostgresStudySetRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresStudySetRepository creates a new PostgreSQL study set repository
+func NewPostgresStudySetRepository(db *sql.DB) *PostgresStudySetRepository {
+	return &PostgresStudySetRepository{db: db}
+}
+
+const (
+	studySetColumns    = `id, title, description, owner_id, visibility, tags, created_at, updated_at`
+	contentItemColumns = `id, study_set_id, content_type, question, answer, hints, created_at, updated_at`
+)
+
+// scanStudySet scans a row selected with studySetColumns
+func scanStudySet(row rowScanner) (*models.StudySet, error) {
+	set := &models.StudySet{}
+	var description sql.NullString
+	var tags pq.StringArray
+	err := row.Scan(
+		&set.ID,
+		&set.Title,
+		&description,
+		&set.OwnerID,
+		&set.Visibility,
+		&tags,
+		&set.CreatedAt,
+		&set.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	set.Description = description.String
+	set.Tags = []string(tags)
+	return set, nil
+}
+
+// scanContentItem scans a row selected with contentItemColumns
+func scanContentItem(row rowScanner) (*models.ContentItem, error) {
+	item := &models.ContentItem{}
+	var hints pq.StringArray
+	err := row.Scan(
+		&item.ID,
+		&item.StudySetID,
+		&item.ContentType,
+		&item.Question,
+		&item.Answer,
+		&hints,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	item.Hints = []string(hints)
+	return item, nil
+}
+
+// CreateStudySet creates a new study set
+func (r *PostgresStudySetRepository) CreateStudySet(ctx context.Context, set *models.StudySet) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO study_sets (`+studySetColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, set.ID, set.Title, set.Description, set.OwnerID, set.Visibility, pq.Array(orEmpty(set.Tags)), set.CreatedAt, set.UpdatedAt)
+	return err
+}
+
+// GetStudySet gets a study set by ID
+func (r *PostgresStudySetRepository) GetStudySet(ctx context.Context, id uuid.UUID) (*models.StudySet, error) {
+	set, err := scanStudySet(r.db.QueryRowContext(ctx, `
+		SELECT `+studySetColumns+`
+		FROM study_sets
+		WHERE id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrStudySetNotFound
+	}
+	return set, err
+}
+
+// ListStudySets lists the study sets matching filter, newest first
+func (r *PostgresStudySetRepository) ListStudySets(ctx context.Context, filter StudySetFilter, page, pageSize int) ([]*models.StudySet, error) {
+	query := `
+		SELECT ` + studySetColumns + `
+		FROM study_sets s
+		WHERE 1 = 1`
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !filter.AllVisible {
+		viewer := arg(filter.ViewerID)
+		query += `
+		AND (s.visibility = 'public' OR s.owner_id = ` + viewer + `
+			OR (s.visibility = 'shared' AND EXISTS (
+				SELECT 1 FROM shared_access a WHERE a.study_set_id = s.id AND a.user_id = ` + viewer + `)))`
+	}
+	if filter.OwnerID != nil {
+		query += ` AND s.owner_id = ` + arg(*filter.OwnerID)
+	}
+	if filter.Visibility != "" {
+		query += ` AND s.visibility = ` + arg(filter.Visibility)
+	}
+	if len(filter.Tags) > 0 {
+		query += ` AND s.tags @> ` + arg(pq.Array(filter.Tags))
+	}
+
+	query += `
+		ORDER BY s.created_at DESC
+		LIMIT ` + arg(pageSize) + ` OFFSET ` + arg((page-1)*pageSize)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sets []*models.StudySet
+	for rows.Next() {
+		set, err := scanStudySet(rows)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	return sets, rows.Err()
+}
+
+// UpdateStudySet updates a study set's title, description, visibility and tags
+func (r *PostgresStudySetRepository) UpdateStudySet(ctx context.Context, set *models.StudySet) error {
+	set.UpdatedAt = time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE study_sets
+		SET title = $1, description = $2, visibility = $3, tags = $4, updated_at = $5
+		WHERE id = $6
+	`, set.Title, set.Description, set.Visibility, pq.Array(orEmpty(set.Tags)), set.UpdatedAt, set.ID)
+	return requireRow(result, err, ErrStudySetNotFound)
+}
+
+// DeleteStudySet deletes a study set along with its items and share grants
+func (r *PostgresStudySetRepository) DeleteStudySet(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM study_sets WHERE id = $1", id)
+	return requireRow(result, err, ErrStudySetNotFound)
+}
+
+// AddContentItem adds an item to a study set
+func (r *PostgresStudySetRepository) AddContentItem(ctx context.Context, item *models.ContentItem) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO content_items (`+contentItemColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, item.ID, item.StudySetID, item.ContentType, item.Question, item.Answer, pq.Array(orEmpty(item.Hints)), item.CreatedAt, item.UpdatedAt)
+	if isForeignKeyViolation(err) {
+		// The study set does not exist
+		return ErrStudySetNotFound
+	}
+	return err
+}
+
+// GetContentItem gets a content item by ID
+func (r *PostgresStudySetRepository) GetContentItem(ctx context.Context, id uuid.UUID) (*models.ContentItem, error) {
+	item, err := scanContentItem(r.db.QueryRowContext(ctx, `
+		SELECT `+contentItemColumns+`
+		FROM content_items
+		WHERE id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrContentItemNotFound
+	}
+	return item, err
+}
+
+// UpdateContentItem updates a content item
+func (r *PostgresStudySetRepository) UpdateContentItem(ctx context.Context, item *models.ContentItem) error {
+	item.UpdatedAt = time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE content_items
+		SET content_type = $1, question = $2, answer = $3, hints = $4, updated_at = $5
+		WHERE id = $6
+	`, item.ContentType, item.Question, item.Answer, pq.Array(orEmpty(item.Hints)), item.UpdatedAt, item.ID)
+	return requireRow(result, err, ErrContentItemNotFound)
+}
+
+// DeleteContentItem deletes a content item
+func (r *PostgresStudySetRepository) DeleteContentItem(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM content_items WHERE id = $1", id)
+	return requireRow(result, err, ErrContentItemNotFound)
+}
+
+// ListContentItems lists the items of a study set in creation order
+func (r *PostgresStudySetRepository) ListContentItems(ctx context.Context, studySetID uuid.UUID) ([]*models.ContentItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+contentItemColumns+`
+		FROM content_items
+		WHERE study_set_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, studySetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.ContentItem
+	for rows.Next() {
+		item, err := scanContentItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// ShareStudySet grants a user access to a study set, replacing any earlier grant
+func (r *PostgresStudySetRepository) ShareStudySet(ctx context.Context, access *models.SharedAccess) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO shared_access (study_set_id, user_id, access_type, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (study_set_id, user_id) DO UPDATE SET access_type = EXCLUDED.access_type
+	`, access.StudySetID, access.UserID, access.AccessType, access.CreatedAt)
+	if isForeignKeyViolation(err) {
+		return ErrStudySetNotFound
+	}
+	return err
+}
+
+// RevokeAccess removes a user's grant on a study set
+func (r *PostgresStudySetRepository) RevokeAccess(ctx context.Context, studySetID, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM shared_access WHERE study_set_id = $1 AND user_id = $2", studySetID, userID)
+	return requireRow(result, err, ErrSharedAccessNotFound)
+}
+
+// GetAccess gets a user's grant on a study set
+func (r *PostgresStudySetRepository) GetAccess(ctx context.Context, studySetID, userID uuid.UUID) (*models.SharedAccess, error) {
+	access := &models.SharedAccess{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT study_set_id, user_id, access_type, created_at
+		FROM shared_access
+		WHERE study_set_id = $1 AND user_id = $2
+	`, studySetID, userID).Scan(&access.StudySetID, &access.UserID, &access.AccessType, &access.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrSharedAccessNotFound
+	}
+	return access, err
+}
+
+// ListSharedAccess lists the grants on a study set
+func (r *PostgresStudySetRepository) ListSharedAccess(ctx context.Context, studySetID uuid.UUID) ([]*models.SharedAccess, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT study_set_id, user_id, access_type, created_at
+		FROM shared_access
+		WHERE study_set_id = $1
+		ORDER BY created_at ASC
+	`, studySetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []*models.SharedAccess
+	for rows.Next() {
+		access := &models.SharedAccess{}
+		if err := rows.Scan(&access.StudySetID, &access.UserID, &access.AccessType, &access.CreatedAt); err != nil {
+			return nil, err
+		}
+		grants = append(grants, access)
+	}
+	return grants, rows.Err()
+}
+
+// requireRow maps an UPDATE or DELETE that touched no rows to notFound
+func requireRow(result sql.Result, err error, notFound error) error {
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// orEmpty stores nil slices as empty arrays so NOT NULL array columns accept them
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
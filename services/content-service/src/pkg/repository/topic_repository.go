@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"QuizApp/services/content-service/src/pkg/models"
+)
+
+// TopicRepository defines the interface for the topic taxonomy.
+//
+// Topics form a tree. Quiz counts are computed for a Viewer so that private
+// quizzes do not show up in other users' counts.
+type TopicRepository interface {
+	CreateTopic(ctx context.Context, topic *models.Topic) error
+	GetTopic(ctx context.Context, viewer Viewer, id uuid.UUID) (*models.Topic, error)
+	GetTopicBySlug(ctx context.Context, viewer Viewer, slug string) (*models.Topic, error)
+	ListTopics(ctx context.Context, viewer Viewer) ([]*models.Topic, error)
+	UpdateTopic(ctx context.Context, topic *models.Topic) error
+	DeleteTopic(ctx context.Context, id uuid.UUID) error
+}
+
+// PostgresTopicRepository implements TopicRepository using PostgreSQL
+type PostgresTopicRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresTopicRepository creates a new PostgreSQL topic repository
+func NewPostgresTopicRepository(db *sql.DB) *PostgresTopicRepository {
+	return &PostgresTopicRepository{db: db}
+}
+
+// topicSelect selects topicColumns plus the viewer's direct and subtree quiz
+// counts; $1 and $2 hold Viewer.Trusted and Viewer.UserID
+const topicSelect = `
+	WITH RECURSIVE tree AS (
+		SELECT id AS root_id, id FROM topics
+		UNION ALL
+		SELECT tree.root_id, t.id FROM topics t JOIN tree ON t.parent_id = tree.id
+	), counts AS (
+		SELECT topic_id, COUNT(*) AS n
+		FROM quizzes
+		WHERE topic_id IS NOT NULL AND %s
+		GROUP BY topic_id
+	)
+	SELECT topics.id, topics.parent_id, topics.slug, topics.name, topics.description,
+		topics.created_by, topics.created_at, topics.updated_at,
+		COALESCE((SELECT n FROM counts WHERE counts.topic_id = topics.id), 0),
+		COALESCE((SELECT SUM(counts.n) FROM tree JOIN counts ON counts.topic_id = tree.id WHERE tree.root_id = topics.id), 0)
+	FROM topics`
+
+// scanTopic scans a row selected with topicSelect
+func scanTopic(row rowScanner) (*models.Topic, error) {
+	topic := &models.Topic{}
+	var description sql.NullString
+	err := row.Scan(
+		&topic.ID,
+		&topic.ParentID,
+		&topic.Slug,
+		&topic.Name,
+		&description,
+		&topic.CreatedBy,
+		&topic.CreatedAt,
+		&topic.UpdatedAt,
+		&topic.QuizCount,
+		&topic.TotalQuizCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	topic.Description = description.String
+	return topic, nil
+}
+
+// selectTopics builds a topicSelect query for the given trailing clause
+func selectTopics(clause string) string {
+	return fmt.Sprintf(topicSelect, quizVisibleTo(1, 2)) + "\n\t" + clause
+}
+
+// CreateTopic creates a new topic; a missing parent is reported as ErrInvalidInput
+func (r *PostgresTopicRepository) CreateTopic(ctx context.Context, topic *models.Topic) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO topics (id, parent_id, slug, name, description, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, topic.ID, topic.ParentID, topic.Slug, topic.Name, topic.Description, topic.CreatedBy, topic.CreatedAt, topic.UpdatedAt)
+	return topicWriteError(err)
+}
+
+// GetTopic gets a topic by ID along with its direct children
+func (r *PostgresTopicRepository) GetTopic(ctx context.Context, viewer Viewer, id uuid.UUID) (*models.Topic, error) {
+	return r.getTopic(ctx, viewer, "WHERE topics.id = $3", id)
+}
+
+// GetTopicBySlug gets a topic by slug along with its direct children
+func (r *PostgresTopicRepository) GetTopicBySlug(ctx context.Context, viewer Viewer, slug string) (*models.Topic, error) {
+	return r.getTopic(ctx, viewer, "WHERE topics.slug = $3", slug)
+}
+
+// getTopic loads the topic matched by where and its direct children
+func (r *PostgresTopicRepository) getTopic(ctx context.Context, viewer Viewer, where string, key interface{}) (*models.Topic, error) {
+	topic, err := scanTopic(r.db.QueryRowContext(ctx, selectTopics(where), viewer.Trusted, viewer.UserID, key))
+	if err == sql.ErrNoRows {
+		return nil, ErrTopicNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	topic.Children, err = r.queryTopics(ctx, selectTopics("WHERE topics.parent_id = $3 ORDER BY topics.name"), viewer.Trusted, viewer.UserID, topic.ID)
+	if err != nil {
+		return nil, err
+	}
+	return topic, nil
+}
+
+// ListTopics lists every topic as a flat list ordered by name; use ParentID to build the tree
+func (r *PostgresTopicRepository) ListTopics(ctx context.Context, viewer Viewer) ([]*models.Topic, error) {
+	return r.queryTopics(ctx, selectTopics("ORDER BY topics.name"), viewer.Trusted, viewer.UserID)
+}
+
+// queryTopics runs a topicSelect query and scans every row
+func (r *PostgresTopicRepository) queryTopics(ctx context.Context, query string, args ...interface{}) ([]*models.Topic, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var topics []*models.Topic
+	for rows.Next() {
+		topic, err := scanTopic(rows)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, topic)
+	}
+	return topics, rows.Err()
+}
+
+// UpdateTopic updates a topic. Moving a topic under itself or one of its
+// descendants is rejected with ErrTopicCycle, and under a missing parent
+// with ErrInvalidInput.
+func (r *PostgresTopicRepository) UpdateTopic(ctx context.Context, topic *models.Topic) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if topic.ParentID != nil {
+		var cycle bool
+		err := tx.QueryRowContext(ctx, `
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM topics WHERE id = $1
+				UNION ALL
+				SELECT t.id, t.parent_id FROM topics t JOIN ancestors a ON t.id = a.parent_id
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)
+		`, *topic.ParentID, topic.ID).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrTopicCycle
+		}
+	}
+
+	topic.UpdatedAt = time.Now().UTC()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE topics
+		SET parent_id = $1, slug = $2, name = $3, description = $4, updated_at = $5
+		WHERE id = $6
+	`, topic.ParentID, topic.Slug, topic.Name, topic.Description, topic.UpdatedAt, topic.ID)
+	if err := requireRow(result, topicWriteError(err), ErrTopicNotFound); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTopic deletes a topic without subtopics. Its quizzes become uncategorized.
+func (r *PostgresTopicRepository) DeleteTopic(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM topics WHERE id = $1", id)
+	if isForeignKeyViolation(err) {
+		return ErrTopicHasChildren
+	}
+	return requireRow(result, err, ErrTopicNotFound)
+}
+
+// topicWriteError maps constraint violations on topic writes to repository errors
+func topicWriteError(err error) error {
+	switch {
+	case isUniqueViolation(err):
+		return ErrTopicSlugTaken
+	case isForeignKeyViolation(err):
+		// The parent topic does not exist
+		return ErrInvalidInput
+	default:
+		return err
+	}
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalFilesPath is where the service serves blobs kept by a LocalStore
+const LocalFilesPath = "/media/files/"
+
+// LocalStore keeps blobs in a directory on the local filesystem. Its signed
+// URLs point at LocalFilesPath and carry an expiry and an HMAC signature,
+// which the serving route checks with Verify.
+type LocalStore struct {
+	dir        string
+	baseURL    string
+	signingKey []byte
+	now        func() time.Time
+}
+
+// NewLocalStore creates a store in dir, creating the directory if needed.
+// baseURL is the address clients reach the service at.
+func NewLocalStore(dir, baseURL string, signingKey []byte) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating media directory: %v", err)
+	}
+	return &LocalStore{
+		dir:        dir,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		signingKey: signingKey,
+		now:        time.Now,
+	}, nil
+}
+
+// Put writes a blob, replacing any blob with the same key
+func (s *LocalStore) Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// Open opens a blob for reading
+func (s *LocalStore) Open(key string) (*os.File, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	file, err := os.Open(target)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete removes a blob; deleting a missing blob is not an error
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL returns a URL that serves the blob until ttl has passed
+func (s *LocalStore) SignedURL(key string, ttl time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(s.now().Add(ttl).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+	return s.baseURL + LocalFilesPath + key + "?" + query.Encode(), nil
+}
+
+// Verify reports whether a signature from SignedURL is genuine and unexpired
+func (s *LocalStore) Verify(key, expires, signature string) bool {
+	deadline, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.now().Unix() > deadline {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, expires)))
+}
+
+// sign computes the signature for a key and expiry
+func (s *LocalStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path maps a key to a file in the store's directory, rejecting keys that
+// would escape it
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "..") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
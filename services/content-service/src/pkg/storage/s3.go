@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3Store. Endpoint is the service address, such as
+// https://s3.eu-west-1.amazonaws.com or a MinIO server. PathStyle puts the
+// bucket in the path instead of the host name, as most S3-compatible
+// servers expect.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool
+}
+
+// S3Store keeps blobs in a bucket of an S3-compatible object store. Requests
+// and URLs are signed with AWS Signature Version 4.
+type S3Store struct {
+	cfg      S3Config
+	endpoint *url.URL
+	http     *http.Client
+	now      func() time.Time
+}
+
+// unsignedPayload stands in for the body hash when the body is not signed
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// NewS3Store creates a store for the configured bucket
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 endpoint, bucket and credentials are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	return &S3Store{
+		cfg:      cfg,
+		endpoint: endpoint,
+		http:     &http.Client{Timeout: 60 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+// Put uploads a blob, replacing any blob with the same key
+func (s *S3Store) Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	return s.do(req, http.StatusOK)
+}
+
+// Delete removes a blob; deleting a missing blob is not an error
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	return s.do(req, http.StatusNoContent, http.StatusOK)
+}
+
+// SignedURL returns a presigned GET URL for the blob, valid for ttl
+func (s *S3Store) SignedURL(key string, ttl time.Duration) (string, error) {
+	if ttl <= 0 || ttl > 7*24*time.Hour {
+		return "", fmt.Errorf("presigned URLs must expire within a week")
+	}
+	t := s.now().UTC()
+	target := s.objectURL(key)
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    s.cfg.AccessKeyID + "/" + s.scope(t),
+		"X-Amz-Date":          t.Format("20060102T150405Z"),
+		"X-Amz-Expires":       strconv.Itoa(int(ttl.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+	canonicalQuery := canonicalQueryString(query)
+	request := strings.Join([]string{
+		http.MethodGet,
+		target.EscapedPath(),
+		canonicalQuery,
+		"host:" + target.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	target.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s.signature(t, request)
+	return target.String(), nil
+}
+
+// do signs and sends a request, failing unless it gets one of the expected statuses
+func (s *S3Store) do(req *http.Request, expected ...int) error {
+	s.signRequest(req)
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach object store: %v", err)
+	}
+	defer resp.Body.Close()
+
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("object store returned status %d: %s", resp.StatusCode, string(body))
+}
+
+// signRequest adds the Authorization header to a request. The body is not
+// signed, which S3 allows over HTTPS.
+func (s *S3Store) signRequest(req *http.Request) {
+	t := s.now().UTC()
+	req.Header.Set("X-Amz-Date", t.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	headers := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + unsignedPayload + "\n" +
+		"x-amz-date:" + req.Header.Get("X-Amz-Date") + "\n"
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		signed = []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+		headers = "content-type:" + contentType + "\n" + headers
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	request := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		headers,
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, s.scope(t), signedHeaders, s.signature(t, request)))
+}
+
+// signature signs a canonical request made at t
+func (s *S3Store) signature(t time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		t.Format("20060102T150405Z"),
+		s.scope(t),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), t.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// scope is the credential scope for requests made at t
+func (s *S3Store) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+// objectURL is the address of a blob, with the bucket in the host name
+// unless the store uses path-style addressing
+func (s *S3Store) objectURL(key string) *url.URL {
+	target := *s.endpoint
+	objectPath := "/" + key
+	if s.cfg.PathStyle {
+		objectPath = "/" + s.cfg.Bucket + objectPath
+	} else {
+		target.Host = s.cfg.Bucket + "." + target.Host
+	}
+	target.Path = strings.TrimSuffix(s.endpoint.Path, "/") + objectPath
+	target.RawPath = uriEncode(target.Path, false)
+	return &target
+}
+
+// canonicalQueryString encodes query parameters sorted by name, as
+// Signature Version 4 requires
+func canonicalQueryString(query map[string]string) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = uriEncode(name, true) + "=" + uriEncode(query[name], true)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, and
+// slashes too when encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// hmacSHA256 computes HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,62 @@
+// Package storage keeps uploaded media in a blob store and hands out
+// time-limited URLs to read it
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// BlobStore stores blobs by key. Blobs are never read through the service
+// API; clients fetch them from the signed URLs the store hands out.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	Delete(ctx context.Context, key string) error
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
+
+// ErrNotFound is returned when a blob does not exist
+var ErrNotFound = errors.New("blob not found")
+
+// NewFromEnv creates the blob store selected by MEDIA_STORE: "local" (the
+// default) keeps blobs under MEDIA_DIR, "s3" in an S3-compatible bucket
+func NewFromEnv() (BlobStore, error) {
+	switch store := os.Getenv("MEDIA_STORE"); store {
+	case "", "local":
+		dir := os.Getenv("MEDIA_DIR")
+		if dir == "" {
+			dir = "media"
+		}
+		baseURL := os.Getenv("MEDIA_PUBLIC_URL")
+		if baseURL == "" {
+			baseURL = "http://localhost:8081"
+		}
+		key := []byte(os.Getenv("MEDIA_SIGNING_KEY"))
+		if len(key) == 0 {
+			// Without a fixed key, URLs stop working when the service restarts
+			log.Printf("MEDIA_SIGNING_KEY is not set; using a random key")
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, fmt.Errorf("error generating media signing key: %v", err)
+			}
+		}
+		return NewLocalStore(dir, baseURL, key)
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("unknown MEDIA_STORE %q", store)
+	}
+}
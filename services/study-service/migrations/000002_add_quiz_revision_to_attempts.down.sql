@@ -0,0 +1,2 @@
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS quiz_revision_id;
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS quiz_version;
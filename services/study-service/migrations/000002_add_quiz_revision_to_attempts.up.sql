@@ -0,0 +1,3 @@
+-- Pin each attempt to the quiz revision it is graded against
+ALTER TABLE quiz_attempts ADD COLUMN IF NOT EXISTS quiz_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quiz_attempts ADD COLUMN IF NOT EXISTS quiz_revision_id UUID;
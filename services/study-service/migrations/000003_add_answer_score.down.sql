@@ -0,0 +1 @@
+ALTER TABLE quiz_answers DROP COLUMN IF EXISTS score;
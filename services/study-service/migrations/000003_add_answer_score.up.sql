@@ -0,0 +1,3 @@
+-- Record the share of credit each answer earned, for partially correct answers
+ALTER TABLE quiz_answers ADD COLUMN IF NOT EXISTS score DOUBLE PRECISION NOT NULL DEFAULT 0;
+UPDATE quiz_answers SET score = 1 WHERE is_correct;
@@ -0,0 +1,2 @@
+ALTER TABLE quiz_answers DROP COLUMN IF EXISTS matched_answer;
+ALTER TABLE quiz_answers DROP COLUMN IF EXISTS matched_rule;
@@ -0,0 +1,3 @@
+-- Record which accepted answer of an open-ended question an answer matched
+ALTER TABLE quiz_answers ADD COLUMN IF NOT EXISTS matched_rule VARCHAR(30);
+ALTER TABLE quiz_answers ADD COLUMN IF NOT EXISTS matched_answer INTEGER;
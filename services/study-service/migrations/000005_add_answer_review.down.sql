@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_quiz_answers_needs_review;
+
+ALTER TABLE quiz_answers DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE quiz_answers DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE quiz_answers DROP COLUMN IF EXISTS review_comment;
+ALTER TABLE quiz_answers DROP COLUMN IF EXISTS needs_review;
@@ -0,0 +1,7 @@
+-- Answers that could not be graded automatically wait for the quiz creator
+ALTER TABLE quiz_answers ADD COLUMN IF NOT EXISTS needs_review BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE quiz_answers ADD COLUMN IF NOT EXISTS review_comment TEXT;
+ALTER TABLE quiz_answers ADD COLUMN IF NOT EXISTS reviewed_by UUID;
+ALTER TABLE quiz_answers ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_quiz_answers_needs_review ON quiz_answers(attempt_id) WHERE needs_review;
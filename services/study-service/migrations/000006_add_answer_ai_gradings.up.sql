@@ -0,0 +1,17 @@
+-- Every AI grading decision, kept so it can be audited
+CREATE TABLE IF NOT EXISTS answer_ai_gradings (
+    id UUID PRIMARY KEY,
+    answer_id UUID NOT NULL REFERENCES quiz_answers(id) ON DELETE CASCADE,
+    generation_id UUID,
+    model_used VARCHAR(255),
+    score DOUBLE PRECISION,
+    confidence DOUBLE PRECISION,
+    rationale TEXT,
+    threshold DOUBLE PRECISION NOT NULL,
+    decision VARCHAR(20) NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT answer_ai_gradings_decision_check CHECK (decision IN ('graded', 'review', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_answer_ai_gradings_answer_id ON answer_ai_gradings(answer_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS attempt_questions;
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS draw_seed;
@@ -0,0 +1,12 @@
+-- The questions each attempt was given, fixed when it starts: the quiz's
+-- own questions and those drawn from its question pools with draw_seed
+ALTER TABLE quiz_attempts ADD COLUMN IF NOT EXISTS draw_seed BIGINT;
+
+CREATE TABLE IF NOT EXISTS attempt_questions (
+    attempt_id UUID NOT NULL REFERENCES quiz_attempts(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    pool_id UUID,
+    question JSONB NOT NULL,
+    PRIMARY KEY (attempt_id, question_id)
+);
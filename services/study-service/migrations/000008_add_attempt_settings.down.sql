@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_quiz_attempts_user_quiz;
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS settings;
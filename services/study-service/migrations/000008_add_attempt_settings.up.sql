@@ -0,0 +1,5 @@
+-- The quiz's delivery settings when each attempt started, so that later
+-- changes to the quiz do not change the rules of attempts in progress
+ALTER TABLE quiz_attempts ADD COLUMN IF NOT EXISTS settings JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_quiz_attempts_user_quiz ON quiz_attempts(user_id, quiz_id);
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
 
+	"QuizApp/services/study-service/src/pkg/ai"
 	"QuizApp/services/study-service/src/pkg/handlers"
 	"QuizApp/services/study-service/src/pkg/repository"
 )
@@ -89,8 +91,18 @@ func main() {
 	// Initialize repository
 	quizAttemptRepo := repository.NewPostgresQuizAttemptRepository(db)
 
+	// AI-graded answers stand when the model is at least this confident
+	aiThreshold := 0.8
+	if thresholdStr := os.Getenv("AI_GRADING_MIN_CONFIDENCE"); thresholdStr != "" {
+		threshold, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			log.Fatalf("AI_GRADING_MIN_CONFIDENCE must be a number from 0 to 1, got %q", thresholdStr)
+		}
+		aiThreshold = threshold
+	}
+
 	// Initialize handlers
-	quizAttemptHandler := handlers.NewQuizAttemptHandler(quizAttemptRepo)
+	quizAttemptHandler := handlers.NewQuizAttemptHandler(quizAttemptRepo, ai.NewClientFromEnv(), aiThreshold)
 
 	// Initialize Gin router
 	r := gin.Default()
@@ -99,7 +111,7 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001", "http://localhost:3002", "http://localhost:3003", "http://localhost:3004", "http://localhost:3005", "http://localhost:3006", "http://localhost:3007"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Cache-Control"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Cache-Control", "X-User-ID"},
 		ExposeHeaders:    []string{"Content-Length", "Content-Type"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -127,6 +139,9 @@ func main() {
 	r.POST("/attempts/:id/answers", quizAttemptHandler.SubmitAnswer)
 	r.POST("/attempts/:id/complete", quizAttemptHandler.CompleteAttempt)
 	r.GET("/users/:id/attempts", quizAttemptHandler.ListUserAttempts)
+	r.GET("/quizzes/:id/reviews", quizAttemptHandler.ListReviews)
+	r.POST("/answers/:id/review", quizAttemptHandler.ReviewAnswer)
+	r.GET("/answers/:id/ai-gradings", quizAttemptHandler.ListAIGradings)
 
 	// Get port from environment variable
 	port := os.Getenv("PORT")
@@ -0,0 +1,84 @@
+// Package ai calls the AI service to grade open-ended answers
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+
+	"QuizApp/services/study-service/src/pkg/grading"
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+// Client grades answers with the AI service's grading endpoint
+type Client struct {
+	baseURL string
+	modelID string
+	http    *http.Client
+}
+
+// NewClient creates a client for the AI service at baseURL. An empty
+// modelID leaves the choice of model to the AI service.
+func NewClient(baseURL, modelID string) *Client {
+	return &Client{
+		baseURL: baseURL,
+		modelID: modelID,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewClientFromEnv creates a client from AI_SERVICE_URL and
+// AI_GRADING_MODEL_ID
+func NewClientFromEnv() *Client {
+	baseURL := os.Getenv("AI_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://ai-service:8083"
+	}
+	return NewClient(baseURL, os.Getenv("AI_GRADING_MODEL_ID"))
+}
+
+// GradeAnswer asks the AI service to grade an answer against the question's
+// answer key and rubric. Usage counts against the learner's AI quota.
+func (c *Client) GradeAnswer(ctx context.Context, userID uuid.UUID, question *repository.Question, answer string) (*grading.AIGrade, error) {
+	body, err := json.Marshal(map[string]string{
+		"userId":    userID.String(),
+		"modelId":   c.modelID,
+		"question":  question.Text,
+		"answerKey": question.CorrectAnswer,
+		"rubric":    question.Rubric,
+		"answer":    answer,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode grading request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/ai/grade", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build grading request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach AI service: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("AI service returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var grade grading.AIGrade
+	if err := json.Unmarshal(respBody, &grade); err != nil {
+		return nil, fmt.Errorf("failed to decode grade: %v", err)
+	}
+	return &grade, nil
+}
@@ -0,0 +1,39 @@
+package grading
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+// AIGrade is an AI model's grade for an open-ended answer: the share of the
+// question's credit it earned and the model's confidence, both from 0 to 1
+type AIGrade struct {
+	GenerationID string  `json:"generationId"`
+	ModelUsed    string  `json:"modelUsed"`
+	Score        float64 `json:"score"`
+	Confidence   float64 `json:"confidence"`
+	Rationale    string  `json:"rationale"`
+}
+
+// AIGrader grades open-ended answers with an AI model on behalf of a learner
+type AIGrader interface {
+	GradeAnswer(ctx context.Context, userID uuid.UUID, question *repository.Question, answer string) (*AIGrade, error)
+}
+
+// FromAI turns an AI grade into a result. Grades the model is at least
+// threshold confident in stand; the rest leave the answer for review.
+func FromAI(grade *AIGrade, threshold float64) Result {
+	switch {
+	case grade.Confidence < threshold:
+		return Result{NeedsReview: true}
+	case grade.Score >= 1:
+		return Result{Correct: true, Score: 1}
+	case grade.Score <= 0:
+		return Result{}
+	default:
+		return Result{Score: grade.Score}
+	}
+}
@@ -0,0 +1,96 @@
+package grading
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+// gradeCloze checks each blank of a cloze question on its own. Every blank
+// filled in correctly earns an equal share of the credit.
+func gradeCloze(question *repository.Question, answer string) Result {
+	filled := filledBlanks(len(question.Blanks), answer)
+	blanks := make([]bool, len(question.Blanks))
+	hits := 0
+	for i, blank := range question.Blanks {
+		blanks[i] = blankAccepts(blank, filled[i])
+		if blanks[i] {
+			hits++
+		}
+	}
+	result := partialCredit(question, float64(hits)/float64(len(blanks)))
+	result.Blanks = blanks
+	return result
+}
+
+// filledBlanks reads a cloze answer as the text of each blank: a JSON array
+// in blank order, or a JSON object keyed by blank number ("1", "2", ...). A
+// question with a single blank also takes plain text.
+func filledBlanks(count int, answer string) []string {
+	filled := make([]string, count)
+	trimmed := strings.TrimSpace(answer)
+
+	var inOrder []string
+	if err := json.Unmarshal([]byte(trimmed), &inOrder); err == nil {
+		copy(filled, inOrder)
+		return filled
+	}
+
+	var byNumber map[string]string
+	if err := json.Unmarshal([]byte(trimmed), &byNumber); err == nil {
+		for key, text := range byNumber {
+			if n, err := strconv.Atoi(strings.TrimSpace(key)); err == nil && n >= 1 && n <= count {
+				filled[n-1] = text
+			}
+		}
+		return filled
+	}
+
+	if count == 1 {
+		filled[0] = answer
+	}
+	return filled
+}
+
+// blankAccepts reports whether text is one of a blank's answers. Surrounding
+// and repeated whitespace never matter; case and accents only when the
+// blank says so.
+func blankAccepts(blank repository.ClozeBlank, text string) bool {
+	fold := func(s string) string {
+		s = normalize(s)
+		if blank.IgnoreAccents {
+			s = stripAccents(s)
+		}
+		if blank.IgnoreCase {
+			s = strings.ToLower(s)
+		}
+		return s
+	}
+
+	given := fold(text)
+	if given == "" {
+		return false
+	}
+	for _, accepted := range blank.Answers {
+		if fold(accepted) == given {
+			return true
+		}
+	}
+	return false
+}
+
+// stripAccents removes diacritics, so "Éléphant" becomes "Elephant"
+func stripAccents(s string) string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		return s
+	}
+	return stripped
+}
@@ -0,0 +1,207 @@
+package grading
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"QuizApp/services/study-service/src/pkg/models"
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+var (
+	// ErrUnsupportedQuestionType is returned when a question type has no grader
+	ErrUnsupportedQuestionType = errors.New("unsupported question type")
+
+	// ErrMissingAnswerKey is returned when a question has no answer key to grade against
+	ErrMissingAnswerKey = errors.New("question has no answer key")
+)
+
+// Result is the server-side verdict for a single answer. Score is the share
+// of the question's credit the answer earned, from 0 to 1; only answers that
+// earn all of it are Correct. Blanks tells which blanks of a cloze question
+// were filled in correctly, and Match which accepted answer of an open-ended
+// question the answer matched. Answers that NeedsReview earn nothing until
+// the quiz creator grades them.
+type Result struct {
+	Correct     bool    `json:"isCorrect"`
+	Score       float64 `json:"score"`
+	Blanks      []bool  `json:"blanks,omitempty"`
+	Match       *Match  `json:"match,omitempty"`
+	NeedsReview bool    `json:"needsReview,omitempty"`
+}
+
+// Grade checks a learner's answer against the question's answer key
+func Grade(question *repository.Question, answer string) (Result, error) {
+	switch models.QuestionType(question.Type) {
+	case models.QuestionTypeMultipleSelect:
+		if len(question.CorrectAnswers) == 0 {
+			return Result{}, ErrMissingAnswerKey
+		}
+		return gradeMultipleSelect(question, answer), nil
+	case models.QuestionTypeNumeric:
+		if question.Numeric == nil {
+			return Result{}, ErrMissingAnswerKey
+		}
+		return verdict(gradeNumeric(question.Numeric, answer)), nil
+	case models.QuestionTypeMatching:
+		if len(question.Prompts) == 0 || len(question.Matches) != len(question.Prompts) {
+			return Result{}, ErrMissingAnswerKey
+		}
+		return gradeMatching(question, answer), nil
+	case models.QuestionTypeOrdering:
+		if len(question.CorrectOrder) == 0 {
+			return Result{}, ErrMissingAnswerKey
+		}
+		return gradeOrdering(question, answer), nil
+	case models.QuestionTypeCloze:
+		if len(question.Blanks) == 0 {
+			return Result{}, ErrMissingAnswerKey
+		}
+		return gradeCloze(question, answer), nil
+	case models.QuestionTypeOpenEnded:
+		switch models.GradingMode(question.Grading) {
+		case models.GradingManual, models.GradingAI:
+			return gradeManually(question, answer), nil
+		}
+		if len(question.AcceptedAnswers) > 0 {
+			return gradeOpenEnded(question, answer), nil
+		}
+	}
+
+	if strings.TrimSpace(question.CorrectAnswer) == "" {
+		return Result{}, ErrMissingAnswerKey
+	}
+
+	switch models.QuestionType(question.Type) {
+	case models.QuestionTypeMultipleChoice:
+		return verdict(gradeMultipleChoice(question, answer)), nil
+	case models.QuestionTypeTrueFalse:
+		return verdict(gradeTrueFalse(question, answer)), nil
+	case models.QuestionTypeOpenEnded:
+		return gradeOpenEnded(question, answer), nil
+	default:
+		return Result{}, ErrUnsupportedQuestionType
+	}
+}
+
+// verdict is the result of an answer that is either right or wrong
+func verdict(correct bool) Result {
+	if correct {
+		return Result{Correct: true, Score: 1}
+	}
+	return Result{}
+}
+
+// partialCredit is the result of an answer earning score, the share of the
+// question's parts it got right. Unless the question gives partial credit,
+// anything short of all of them earns nothing. Scores below zero count as zero.
+func partialCredit(question *repository.Question, score float64) Result {
+	if score >= 1 {
+		return Result{Correct: true, Score: 1}
+	}
+	if models.ScoringMode(question.Scoring) != models.ScoringPartialCredit || score < 0 {
+		return Result{}
+	}
+	return Result{Score: score}
+}
+
+// gradeMultipleChoice accepts the answer only if it names the correct option
+func gradeMultipleChoice(question *repository.Question, answer string) bool {
+	if !equalNormalized(question.CorrectAnswer, answer) {
+		return false
+	}
+	// Guard against answer keys that drifted out of the option list
+	if len(question.Options) == 0 {
+		return true
+	}
+	for _, option := range question.Options {
+		if equalNormalized(option, answer) {
+			return true
+		}
+	}
+	return false
+}
+
+// gradeMultipleSelect compares the selected options with the correct set.
+// With partial credit each correct option selected earns an equal share and
+// each wrong one selected takes a share off, never going below zero, so
+// selecting every option earns nothing for free. Otherwise the selection
+// must match the correct set exactly.
+func gradeMultipleSelect(question *repository.Question, answer string) Result {
+	correct := make(map[string]bool, len(question.CorrectAnswers))
+	for _, option := range question.CorrectAnswers {
+		correct[answerKey(option)] = true
+	}
+
+	hits, misses := 0, 0
+	for selection := range selections(answer) {
+		if correct[selection] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	return partialCredit(question, float64(hits-misses)/float64(len(correct)))
+}
+
+// selections reads the options chosen in a multiple select answer: a JSON
+// array of strings, or a single option as plain text. Options are
+// normalized for comparison and duplicates count once.
+func selections(answer string) map[string]bool {
+	var chosen []string
+	trimmed := strings.TrimSpace(answer)
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &chosen); err != nil {
+			return nil
+		}
+	} else if trimmed != "" {
+		chosen = []string{trimmed}
+	}
+
+	selected := make(map[string]bool, len(chosen))
+	for _, option := range chosen {
+		if key := answerKey(option); key != "" {
+			selected[key] = true
+		}
+	}
+	return selected
+}
+
+// gradeTrueFalse compares boolean answers, tolerating common spellings
+func gradeTrueFalse(question *repository.Question, answer string) bool {
+	expected, okExpected := parseBool(question.CorrectAnswer)
+	actual, okActual := parseBool(answer)
+	if okExpected && okActual {
+		return expected == actual
+	}
+	return equalNormalized(question.CorrectAnswer, answer)
+}
+
+// parseBool parses the spellings learners and authors use for true/false
+func parseBool(s string) (bool, bool) {
+	switch strings.ToLower(normalize(s)) {
+	case "true", "t", "yes", "y", "1":
+		return true, true
+	case "false", "f", "no", "n", "0":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// answerKey folds an answer for lookups the way equalNormalized compares it
+func answerKey(s string) string {
+	return strings.ToLower(normalize(s))
+}
+
+// equalNormalized compares two strings ignoring case and surrounding/repeated whitespace
+func equalNormalized(a, b string) bool {
+	return strings.EqualFold(normalize(a), normalize(b))
+}
+
+// normalize trims the string and collapses internal whitespace
+func normalize(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
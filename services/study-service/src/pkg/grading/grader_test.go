@@ -0,0 +1,396 @@
+package grading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+func TestGrade(t *testing.T) {
+	tests := []struct {
+		name     string
+		question *repository.Question
+		answer   string
+		want     bool
+	}{
+		{
+			name:     "multiple choice correct option",
+			question: &repository.Question{Type: "multiple_choice", Options: []string{"Paris", "Rome"}, CorrectAnswer: "Paris"},
+			answer:   "Paris",
+			want:     true,
+		},
+		{
+			name:     "multiple choice ignores case and whitespace",
+			question: &repository.Question{Type: "multiple_choice", Options: []string{"Paris", "Rome"}, CorrectAnswer: "Paris"},
+			answer:   "  paris ",
+			want:     true,
+		},
+		{
+			name:     "multiple choice wrong option",
+			question: &repository.Question{Type: "multiple_choice", Options: []string{"Paris", "Rome"}, CorrectAnswer: "Paris"},
+			answer:   "Rome",
+			want:     false,
+		},
+		{
+			name:     "true false accepts alternate spellings",
+			question: &repository.Question{Type: "true_false", Options: []string{"True", "False"}, CorrectAnswer: "True"},
+			answer:   "yes",
+			want:     true,
+		},
+		{
+			name:     "true false wrong answer",
+			question: &repository.Question{Type: "true_false", Options: []string{"True", "False"}, CorrectAnswer: "false"},
+			answer:   "True",
+			want:     false,
+		},
+		{
+			name:     "open ended normalized match",
+			question: &repository.Question{Type: "open_ended", CorrectAnswer: "Mitochondria"},
+			answer:   "mitochondria",
+			want:     true,
+		},
+		{
+			name:     "open ended mismatch",
+			question: &repository.Question{Type: "open_ended", CorrectAnswer: "Mitochondria"},
+			answer:   "Nucleus",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Grade(tt.question, tt.answer)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Correct)
+		})
+	}
+}
+
+func TestGradeMultipleSelect(t *testing.T) {
+	primes := func(scoring string) *repository.Question {
+		return &repository.Question{
+			Type:           "multiple_select",
+			Options:        []string{"2", "3", "4", "5"},
+			CorrectAnswers: []string{"2", "3", "5"},
+			Scoring:        scoring,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		scoring     string
+		answer      string
+		wantCorrect bool
+		wantScore   float64
+	}{
+		{"exact set", "all_or_nothing", `["5", "2", "3"]`, true, 1},
+		{"missing option earns nothing", "all_or_nothing", `["2", "3"]`, false, 0},
+		{"default mode is all or nothing", "", `["2", "3"]`, false, 0},
+		{"partial credit per option", "partial_credit", `["2", "3"]`, false, 2.0 / 3},
+		{"wrong option takes credit off", "partial_credit", `["2", "3", "4"]`, false, 1.0 / 3},
+		{"selecting everything", "partial_credit", `["2", "3", "4", "5"]`, false, 2.0 / 3},
+		{"never below zero", "partial_credit", `["4"]`, false, 0},
+		{"duplicates count once", "partial_credit", `["2", " 2 "]`, false, 1.0 / 3},
+		{"single option as text", "partial_credit", "3", false, 1.0 / 3},
+		{"malformed array", "partial_credit", `["2"`, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Grade(primes(tt.scoring), tt.answer)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCorrect, got.Correct)
+			assert.InDelta(t, tt.wantScore, got.Score, 1e-9)
+		})
+	}
+}
+
+func TestGradeMatching(t *testing.T) {
+	sounds := func(scoring string) *repository.Question {
+		return &repository.Question{
+			Type:    "matching",
+			Options: []string{"meow", "woof", "moo"},
+			Prompts: []string{"Cat", "Dog", "Kitten", "Cow"},
+			Matches: []string{"meow", "woof", "meow", "moo"},
+			Scoring: scoring,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		scoring     string
+		answer      string
+		wantCorrect bool
+		wantScore   float64
+	}{
+		{"all pairs by prompt", "all_or_nothing", `{"cat": "Meow", "dog": "woof", "kitten": "meow", "cow": "moo"}`, true, 1},
+		{"all pairs in prompt order", "all_or_nothing", `["meow", "woof", "meow", "moo"]`, true, 1},
+		{"one wrong pair earns nothing", "all_or_nothing", `{"Cat": "meow", "Dog": "moo", "Kitten": "meow", "Cow": "moo"}`, false, 0},
+		{"partial credit per pair", "partial_credit", `{"Cat": "meow", "Dog": "moo", "Kitten": "meow", "Cow": "moo"}`, false, 3.0 / 4},
+		{"unanswered prompts earn nothing", "partial_credit", `{"Cat": "meow"}`, false, 1.0 / 4},
+		{"unknown prompts ignored", "partial_credit", `{"Horse": "neigh", "Dog": "woof"}`, false, 1.0 / 4},
+		{"plain text", "partial_credit", "meow", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Grade(sounds(tt.scoring), tt.answer)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCorrect, got.Correct)
+			assert.InDelta(t, tt.wantScore, got.Score, 1e-9)
+		})
+	}
+}
+
+func TestGradeOrdering(t *testing.T) {
+	steps := func(scoring string) *repository.Question {
+		return &repository.Question{
+			Type:         "ordering",
+			Options:      []string{"Rinse", "Wash", "Dry", "Soak"},
+			CorrectOrder: []string{"Soak", "Wash", "Rinse", "Dry"},
+			Scoring:      scoring,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		scoring     string
+		answer      string
+		wantCorrect bool
+		wantScore   float64
+	}{
+		{"correct order", "all_or_nothing", `["soak", "wash", "rinse", "dry"]`, true, 1},
+		{"one swap earns nothing", "all_or_nothing", `["Soak", "Rinse", "Wash", "Dry"]`, false, 0},
+		{"one swap loses one pair", "partial_credit", `["Soak", "Rinse", "Wash", "Dry"]`, false, 5.0 / 6},
+		{"reversed", "partial_credit", `["Dry", "Rinse", "Wash", "Soak"]`, false, 0},
+		{"missing item loses its pairs", "partial_credit", `["Soak", "Wash", "Rinse"]`, false, 3.0 / 6},
+		{"repeated and unknown items ignored", "partial_credit", `["Soak", "Soak", "Scrub", "Wash", "Rinse", "Dry"]`, true, 1},
+		{"not an array", "partial_credit", "Soak, Wash, Rinse, Dry", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Grade(steps(tt.scoring), tt.answer)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCorrect, got.Correct)
+			assert.InDelta(t, tt.wantScore, got.Score, 1e-9)
+		})
+	}
+}
+
+func TestGradeCloze(t *testing.T) {
+	passage := func(scoring string) *repository.Question {
+		return &repository.Question{
+			Type: "cloze",
+			Blanks: []repository.ClozeBlank{
+				{Kind: "text", Answers: []string{"été"}},
+				{Kind: "text", Answers: []string{"Paris"}, IgnoreCase: true, IgnoreAccents: true},
+				{Kind: "dropdown", Options: []string{"le", "la"}, Answers: []string{"la"}},
+			},
+			Scoring: scoring,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		scoring     string
+		answer      string
+		wantCorrect bool
+		wantScore   float64
+		wantBlanks  []bool
+	}{
+		{"all blanks", "all_or_nothing", `["été", " paris ", "la"]`, true, 1, []bool{true, true, true}},
+		{"blanks by number", "all_or_nothing", `{"3": "la", "1": "été", "2": "PÂRIS"}`, true, 1, []bool{true, true, true}},
+		{"accents matter unless ignored", "all_or_nothing", `["ete", "Paris", "la"]`, false, 0, []bool{false, true, true}},
+		{"case matters unless ignored", "partial_credit", `["Été", "Paris", "la"]`, false, 2.0 / 3, []bool{false, true, true}},
+		{"partial credit per blank", "partial_credit", `["été", "Lyon"]`, false, 1.0 / 3, []bool{true, false, false}},
+		{"plain text fills nothing", "partial_credit", "été", false, 0, []bool{false, false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Grade(passage(tt.scoring), tt.answer)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCorrect, got.Correct)
+			assert.InDelta(t, tt.wantScore, got.Score, 1e-9)
+			assert.Equal(t, tt.wantBlanks, got.Blanks)
+		})
+	}
+
+	t.Run("single blank takes plain text", func(t *testing.T) {
+		question := &repository.Question{Type: "cloze", Blanks: []repository.ClozeBlank{{Kind: "text", Answers: []string{"Mars"}}}}
+		got, err := Grade(question, " Mars")
+		assert.NoError(t, err)
+		assert.True(t, got.Correct)
+	})
+}
+
+func TestGradeOpenEndedAcceptedAnswers(t *testing.T) {
+	question := &repository.Question{
+		Type:          "open_ended",
+		CorrectAnswer: "Colour",
+		AcceptedAnswers: []repository.AcceptedAnswer{
+			{Answer: "NaCl", Match: "exact"},
+			{Answer: "sodium  chloride", Match: "case_insensitive"},
+			{Answer: "Table Salt", Match: "whitespace_normalized"},
+			{Answer: `colou?r`, Match: "regex"},
+			{Answer: "Mississippi", Match: "levenshtein", MaxDistance: 2},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		answer    string
+		wantMatch *Match
+	}{
+		{"exact", " NaCl\n", &Match{Index: 0, Rule: "exact"}},
+		{"exact rejects other case", "nacl", nil},
+		{"case insensitive", "Sodium Chloride", &Match{Index: 1, Rule: "case_insensitive"}},
+		{"whitespace normalized", "Table   Salt", &Match{Index: 2, Rule: "whitespace_normalized"}},
+		{"whitespace normalized keeps case", "table salt", nil},
+		{"regex matches in full", "color", &Match{Index: 3, Rule: "regex"}},
+		{"regex is anchored", "colors", nil},
+		{"levenshtein within distance", "mississipi", &Match{Index: 4, Rule: "levenshtein"}},
+		{"levenshtein beyond distance", "Misisipi", nil},
+		{"answer key is not graded", "Colour ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Grade(question, tt.answer)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantMatch != nil, got.Correct)
+			assert.Equal(t, tt.wantMatch, got.Match)
+		})
+	}
+}
+
+func TestGradeManually(t *testing.T) {
+	essay := &repository.Question{Type: "open_ended", Grading: "manual", CorrectAnswer: "Mentions both causes"}
+	short := &repository.Question{
+		Type:            "open_ended",
+		Grading:         "manual",
+		AcceptedAnswers: []repository.AcceptedAnswer{{Answer: "Paris", Match: "case_insensitive"}},
+	}
+
+	tests := []struct {
+		name     string
+		question *repository.Question
+		answer   string
+		want     Result
+	}{
+		{"essay goes to review", essay, "Both causes", Result{NeedsReview: true}},
+		{"answer key is not graded", essay, "Mentions both causes", Result{NeedsReview: true}},
+		{"accepted answer is graded", short, "paris", Result{Correct: true, Score: 1, Match: &Match{Index: 0, Rule: "case_insensitive"}}},
+		{"other answers go to review", short, "Lutetia", Result{NeedsReview: true}},
+		{"AI graded answers go to review first", &repository.Question{Type: "open_ended", Grading: "ai", Rubric: "Names a cause"}, "Wind", Result{NeedsReview: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Grade(tt.question, tt.answer)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFromAI(t *testing.T) {
+	tests := []struct {
+		name  string
+		grade AIGrade
+		want  Result
+	}{
+		{"confident full credit", AIGrade{Score: 1, Confidence: 0.9}, Result{Correct: true, Score: 1}},
+		{"confident partial credit", AIGrade{Score: 0.5, Confidence: 0.8}, Result{Score: 0.5}},
+		{"confident no credit", AIGrade{Score: 0, Confidence: 0.95}, Result{}},
+		{"unsure goes to review", AIGrade{Score: 1, Confidence: 0.79}, Result{NeedsReview: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FromAI(&tt.grade, 0.8))
+		})
+	}
+}
+
+func TestGradeErrors(t *testing.T) {
+	t.Run("missing answer key", func(t *testing.T) {
+		_, err := Grade(&repository.Question{Type: "open_ended"}, "anything")
+		assert.ErrorIs(t, err, ErrMissingAnswerKey)
+	})
+
+	t.Run("multiple select without correct options", func(t *testing.T) {
+		_, err := Grade(&repository.Question{Type: "multiple_select", Options: []string{"a", "b"}}, `["a"]`)
+		assert.ErrorIs(t, err, ErrMissingAnswerKey)
+	})
+
+	t.Run("unsupported question type", func(t *testing.T) {
+		_, err := Grade(&repository.Question{Type: "essay", CorrectAnswer: "x"}, "x")
+		assert.ErrorIs(t, err, ErrUnsupportedQuestionType)
+	})
+}
+
+func TestGradeNumeric(t *testing.T) {
+	gravity := &repository.Question{
+		Type: "numeric",
+		Numeric: &repository.NumericAnswer{
+			Value:     9.81,
+			Tolerance: 0.05,
+			Unit:      "m/s²",
+			Units:     []repository.NumericUnit{{Unit: "cm/s^2", Multiplier: 0.01}},
+		},
+	}
+	population := &repository.Question{
+		Type:    "numeric",
+		Numeric: &repository.NumericAnswer{Value: 1500000, Tolerance: 0.01, ToleranceType: "relative"},
+	}
+	charge := &repository.Question{
+		Type:    "numeric",
+		Numeric: &repository.NumericAnswer{Value: -1.602e-19, Tolerance: 0.001e-19, Unit: "C", UnitRequired: true},
+	}
+
+	tests := []struct {
+		name     string
+		question *repository.Question
+		answer   string
+		want     bool
+	}{
+		{"exact value", gravity, "9.81", true},
+		{"within tolerance", gravity, "9.85", true},
+		{"outside tolerance", gravity, "9.9", false},
+		{"comma decimal separator", gravity, "9,8", true},
+		{"base unit", gravity, "9.81 m/s^2", true},
+		{"converted unit", gravity, "981 cm/s²", true},
+		{"unknown unit", gravity, "9.81 ft/s²", false},
+		{"not a number", gravity, "about ten", false},
+		{"relative tolerance", population, "1.51e6", true},
+		{"relative tolerance exceeded", population, "1.52e6", false},
+		{"grouping with commas", population, "1,500,000", true},
+		{"grouping with dots and decimal comma", population, "1.500.000,00", true},
+		{"grouping with spaces", population, "1 500 000", true},
+		{"grouping with apostrophes", population, "1'500'000", true},
+		{"ambiguous separator in neither reading", population, "1,500", false},
+		{"malformed grouping", population, "1,50,000", false},
+		{"times ten notation", charge, "−1.602 × 10⁻¹⁹ C", true},
+		{"caret notation", charge, "-1.602*10^-19 C", true},
+		{"required unit missing", charge, "-1.602e-19", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Grade(tt.question, tt.answer)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Correct)
+		})
+	}
+
+	t.Run("ambiguous separator accepts either reading", func(t *testing.T) {
+		thousand := &repository.Question{Type: "numeric", Numeric: &repository.NumericAnswer{Value: 1500}}
+		got, err := Grade(thousand, "1,500")
+		assert.NoError(t, err)
+		assert.True(t, got.Correct)
+	})
+}
@@ -0,0 +1,47 @@
+package grading
+
+import (
+	"encoding/json"
+	"strings"
+
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+// gradeMatching compares the option chosen for each prompt with its match.
+// Each prompt matched correctly earns an equal share of the credit.
+func gradeMatching(question *repository.Question, answer string) Result {
+	chosen := matchedOptions(question.Prompts, answer)
+	hits := 0
+	for i, prompt := range question.Prompts {
+		if chosen[answerKey(prompt)] == answerKey(question.Matches[i]) {
+			hits++
+		}
+	}
+	return partialCredit(question, float64(hits)/float64(len(question.Prompts)))
+}
+
+// matchedOptions reads a matching answer as the option chosen for each
+// prompt, keyed by prompt. The answer is a JSON object mapping prompts to
+// options, or a JSON array of options in the order of the prompts.
+func matchedOptions(prompts []string, answer string) map[string]string {
+	chosen := make(map[string]string, len(prompts))
+	trimmed := strings.TrimSpace(answer)
+
+	var byPrompt map[string]string
+	if err := json.Unmarshal([]byte(trimmed), &byPrompt); err == nil {
+		for prompt, option := range byPrompt {
+			chosen[answerKey(prompt)] = answerKey(option)
+		}
+		return chosen
+	}
+
+	var inOrder []string
+	if err := json.Unmarshal([]byte(trimmed), &inOrder); err == nil {
+		for i, option := range inOrder {
+			if i < len(prompts) {
+				chosen[answerKey(prompts[i])] = answerKey(option)
+			}
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,215 @@
+package grading
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"QuizApp/services/study-service/src/pkg/models"
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+// numericInput splits a learner's numeric answer into sign, digits,
+// exponent and unit. Superscripts are rewritten to "^n" first, so "10⁻³"
+// reads as "10^-3" and "m²" as "m^2".
+var numericInput = regexp.MustCompile(`^([-+]?)\s*((?:\d|[.,]\d)(?:[\d.,'’ \x{00A0}\x{202F}]*\d)?)` +
+	`(?:\s*(?:[eE]([-+]?\d+)|(?:[x×*·]\s*)?10\^([-+]?\d+)))?\s*(.*)$`)
+
+// groupSeparators are the characters learners put between digit groups
+// besides "." and ","
+const groupSeparators = "'’ \u00a0\u202f"
+
+// superscripts maps superscript characters to their plain form
+var superscripts = strings.NewReplacer(
+	"⁰", "0", "¹", "1", "²", "2", "³", "3", "⁴", "4",
+	"⁵", "5", "⁶", "6", "⁷", "7", "⁸", "8", "⁹", "9",
+	"⁻", "-", "⁺", "+",
+)
+
+// gradeNumeric accepts an answer within the key's tolerance of its value,
+// after converting the unit the answer was given in. Answers that cannot be
+// read as a number, or name a unit the key does not accept, are wrong.
+func gradeNumeric(key *repository.NumericAnswer, answer string) bool {
+	values, unit, ok := parseNumeric(answer)
+	if !ok {
+		return false
+	}
+	multiplier, ok := unitMultiplier(key, unit)
+	if !ok {
+		return false
+	}
+
+	allowed := key.Tolerance
+	if models.ToleranceType(key.ToleranceType) == models.ToleranceRelative {
+		allowed = key.Tolerance * math.Abs(key.Value)
+	}
+	// Leave room for rounding in the unit conversion and the decimal input
+	allowed += 1e-9 * math.Max(1, math.Abs(key.Value))
+
+	for _, value := range values {
+		if math.Abs(value*multiplier-key.Value) <= allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// unitMultiplier returns the factor converting an answer given in unit to
+// the key's unit. A bare number is taken to be in the key's unit unless the
+// key requires one.
+func unitMultiplier(key *repository.NumericAnswer, unit string) (float64, bool) {
+	if unit == "" {
+		return 1, !key.UnitRequired || key.Unit == ""
+	}
+	if sameUnit(unit, key.Unit) {
+		return 1, true
+	}
+	for _, accepted := range key.Units {
+		if sameUnit(unit, accepted.Unit) {
+			return accepted.Multiplier, accepted.Multiplier > 0
+		}
+	}
+	return 0, false
+}
+
+// sameUnit compares unit names, ignoring spaces and the difference between
+// superscripts and "^". Case matters: mm and Mm are different units.
+func sameUnit(a, b string) bool {
+	return a != "" && unitKey(a) == unitKey(b)
+}
+
+// unitKey folds a unit name for comparison
+func unitKey(unit string) string {
+	return strings.Join(strings.Fields(superscriptsToCarets(unit)), "")
+}
+
+// parseNumeric reads a number and optional unit from a learner's answer. It
+// accepts "." or "," as the decimal separator, digit grouping with ".", ",",
+// spaces or apostrophes, a Unicode minus sign, and scientific notation as
+// 1.5e-3 or 1.5 × 10^-3. A lone separator followed by three digits, as in
+// "1,500", is ambiguous between locales, so both readings are returned.
+func parseNumeric(answer string) ([]float64, string, bool) {
+	answer = strings.ReplaceAll(strings.TrimSpace(answer), "−", "-")
+	match := numericInput.FindStringSubmatch(superscriptsToCarets(answer))
+	if match == nil {
+		return nil, "", false
+	}
+	sign, digits, unit := match[1], match[2], strings.TrimSpace(match[5])
+	exponent := match[3]
+	if exponent == "" {
+		exponent = match[4]
+	}
+
+	var values []float64
+	for _, reading := range decimalReadings(digits) {
+		literal := sign + reading
+		if exponent != "" {
+			literal += "e" + exponent
+		}
+		value, err := strconv.ParseFloat(literal, 64)
+		if err != nil || math.IsInf(value, 0) {
+			continue
+		}
+		values = append(values, value)
+	}
+	return values, unit, len(values) > 0
+}
+
+// decimalReadings rewrites the digits of a number with "." as the decimal
+// separator and no grouping. When "." and "," both appear the last one is
+// the decimal separator; one that appears several times groups digits.
+// Groups after the first must have three digits, so "1.2.3" is no number.
+func decimalReadings(digits string) []string {
+	if strings.ContainsAny(digits, groupSeparators) {
+		if !grouped(digits, groupSeparators, ".,") {
+			return nil
+		}
+		digits = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(groupSeparators, r) {
+				return -1
+			}
+			return r
+		}, digits)
+		return []string{strings.ReplaceAll(digits, ",", ".")}
+	}
+
+	dots, commas := strings.Count(digits, "."), strings.Count(digits, ",")
+	switch {
+	case dots > 0 && commas > 0:
+		decimal := ","
+		if strings.LastIndex(digits, ".") > strings.LastIndex(digits, ",") {
+			decimal = "."
+		}
+		group := strings.Trim(".,", decimal)
+		if strings.Count(digits, decimal) > 1 || !grouped(digits, group, decimal) {
+			return nil
+		}
+		digits = strings.ReplaceAll(digits, group, "")
+		return []string{strings.ReplaceAll(digits, decimal, ".")}
+	case dots > 1 || commas > 1:
+		group := "."
+		if commas > 1 {
+			group = ","
+		}
+		if !grouped(digits, group, "") {
+			return nil
+		}
+		return []string{strings.ReplaceAll(digits, group, "")}
+	case dots+commas == 1:
+		separator := "."
+		if commas == 1 {
+			separator = ","
+		}
+		decimal := strings.ReplaceAll(digits, separator, ".")
+		if grouped(digits, separator, "") && !strings.HasPrefix(digits, "0") {
+			return []string{decimal, strings.ReplaceAll(digits, separator, "")}
+		}
+		return []string{decimal}
+	default:
+		return []string{digits}
+	}
+}
+
+// grouped reports whether the integer part of digits, up to the first of
+// decimals, is split by group into a leading group of one to three digits
+// followed by groups of exactly three
+func grouped(digits, group, decimals string) bool {
+	if i := strings.IndexAny(digits, decimals); decimals != "" && i >= 0 {
+		digits = digits[:i]
+	}
+	parts := strings.FieldsFunc(digits, func(r rune) bool { return strings.ContainsRune(group, r) })
+	if len(parts) < 2 {
+		return false
+	}
+	length := 0
+	for i, part := range parts {
+		if strings.Trim(part, "0123456789") != "" {
+			return false
+		}
+		if (i == 0 && len(part) > 3) || (i > 0 && len(part) != 3) {
+			return false
+		}
+		length += len(part)
+	}
+	// Every separator sits between two groups
+	return utf8.RuneCountInString(digits) == length+len(parts)-1
+}
+
+// superscriptsToCarets rewrites each run of superscript characters as "^"
+// followed by its plain form
+func superscriptsToCarets(s string) string {
+	var b strings.Builder
+	inRun := false
+	for _, r := range s {
+		plain := superscripts.Replace(string(r))
+		isSuperscript := plain != string(r)
+		if isSuperscript && !inRun {
+			b.WriteByte('^')
+		}
+		inRun = isSuperscript
+		b.WriteString(plain)
+	}
+	return b.String()
+}
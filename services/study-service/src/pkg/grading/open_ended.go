@@ -0,0 +1,102 @@
+package grading
+
+import (
+	"regexp"
+	"strings"
+
+	"QuizApp/services/study-service/src/pkg/models"
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+// Match identifies the accepted answer of an open-ended question that an
+// answer matched: its position among the accepted answers and its rule
+type Match struct {
+	Index int    `json:"index"`
+	Rule  string `json:"rule"`
+}
+
+// gradeOpenEnded tries the question's accepted answers in order and
+// accepts the answer on the first one it matches. Questions without
+// accepted answers compare it with the answer key, ignoring case and
+// repeated whitespace.
+func gradeOpenEnded(question *repository.Question, answer string) Result {
+	if len(question.AcceptedAnswers) == 0 {
+		return verdict(equalNormalized(question.CorrectAnswer, answer))
+	}
+	for i, accepted := range question.AcceptedAnswers {
+		if matchesAccepted(accepted, answer) {
+			result := verdict(true)
+			result.Match = &Match{Index: i, Rule: accepted.Match}
+			return result
+		}
+	}
+	return Result{}
+}
+
+// gradeManually accepts answers that match one of the question's accepted
+// answers and leaves the rest for review, by an AI model first if the
+// question is graded by AI. The answer key then only guides the reviewer.
+func gradeManually(question *repository.Question, answer string) Result {
+	if len(question.AcceptedAnswers) > 0 {
+		if result := gradeOpenEnded(question, answer); result.Correct {
+			return result
+		}
+	}
+	return Result{NeedsReview: true}
+}
+
+// matchesAccepted compares an answer with one accepted answer by its rule.
+// Surrounding whitespace never matters; unknown rules match nothing.
+func matchesAccepted(accepted repository.AcceptedAnswer, answer string) bool {
+	answer = strings.TrimSpace(answer)
+	switch models.MatchRule(accepted.Match) {
+	case models.MatchExact:
+		return answer == strings.TrimSpace(accepted.Answer)
+	case models.MatchCaseInsensitive:
+		return equalNormalized(accepted.Answer, answer)
+	case models.MatchWhitespaceNormalized:
+		return normalize(accepted.Answer) == normalize(answer)
+	case models.MatchRegex:
+		pattern, err := regexp.Compile(`^(?:` + accepted.Answer + `)$`)
+		return err == nil && pattern.MatchString(answer)
+	case models.MatchLevenshtein:
+		return withinDistance(answerKey(accepted.Answer), answerKey(answer), accepted.MaxDistance)
+	default:
+		return false
+	}
+}
+
+// withinDistance reports whether a can be turned into b with at most max
+// single-character insertions, deletions and substitutions
+func withinDistance(a, b string, max int) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra)-len(rb) > max || len(rb)-len(ra) > max {
+		return false
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		best := current[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if current[j] < best {
+				best = current[j]
+			}
+		}
+		// Distances never shrink from one row to the next
+		if best > max {
+			return false
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)] <= max
+}
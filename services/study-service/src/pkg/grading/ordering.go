@@ -0,0 +1,53 @@
+package grading
+
+import (
+	"encoding/json"
+	"strings"
+
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+// gradeOrdering compares the learner's sequence with the correct order.
+// Partial credit is the share of item pairs the learner put in the right
+// relative order, so one item out of place costs little while a reversed
+// list earns nothing. Items left out lose every pair they are part of.
+func gradeOrdering(question *repository.Question, answer string) Result {
+	position := make(map[string]int, len(question.CorrectOrder))
+	for i, item := range question.CorrectOrder {
+		position[answerKey(item)] = i
+	}
+
+	// The learner's sequence as correct positions, skipping unknown and repeated items
+	var sequence []int
+	placed := make(map[int]bool, len(position))
+	for _, item := range orderedItems(answer) {
+		if i, ok := position[answerKey(item)]; ok && !placed[i] {
+			placed[i] = true
+			sequence = append(sequence, i)
+		}
+	}
+
+	n := len(question.CorrectOrder)
+	if n == 1 {
+		return verdict(len(sequence) == 1)
+	}
+	inOrder := 0
+	for i := range sequence {
+		for j := i + 1; j < len(sequence); j++ {
+			if sequence[i] < sequence[j] {
+				inOrder++
+			}
+		}
+	}
+	return partialCredit(question, float64(inOrder)/float64(n*(n-1)/2))
+}
+
+// orderedItems reads an ordering answer: a JSON array of the items in the
+// learner's order
+func orderedItems(answer string) []string {
+	var items []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(answer)), &items); err != nil {
+		return nil
+	}
+	return items
+}
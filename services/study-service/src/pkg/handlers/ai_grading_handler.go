@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/study-service/src/pkg/grading"
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+// gradeWithAI has the AI grader grade an answer for the learner and returns
+// the result along with the decision to store for audit. Answers the model
+// is unsure of, or that it fails to grade, are left for review.
+func (h *QuizAttemptHandler) gradeWithAI(ctx context.Context, userID uuid.UUID, question *repository.Question, answer string) (grading.Result, *repository.AIGrading) {
+	record := &repository.AIGrading{
+		ID:        uuid.New(),
+		Threshold: h.aiThreshold,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if h.aiGrader == nil {
+		message := "AI grading is not configured"
+		record.Decision = repository.AIDecisionFailed
+		record.Error = &message
+		return grading.Result{NeedsReview: true}, record
+	}
+
+	grade, err := h.aiGrader.GradeAnswer(ctx, userID, question, answer)
+	if err != nil {
+		log.Printf("ERROR: AI grading failed for question %s: %v", question.ID, err)
+		message := err.Error()
+		record.Decision = repository.AIDecisionFailed
+		record.Error = &message
+		return grading.Result{NeedsReview: true}, record
+	}
+
+	if generationID, err := uuid.Parse(grade.GenerationID); err == nil {
+		record.GenerationID = &generationID
+	}
+	record.ModelUsed = &grade.ModelUsed
+	record.Score = &grade.Score
+	record.Confidence = &grade.Confidence
+	record.Rationale = &grade.Rationale
+
+	result := grading.FromAI(grade, h.aiThreshold)
+	record.Decision = repository.AIDecisionGraded
+	if result.NeedsReview {
+		record.Decision = repository.AIDecisionReview
+	}
+	log.Printf("AI graded question %s with score %.2f at confidence %.2f: %s",
+		question.ID, grade.Score, grade.Confidence, record.Decision)
+	return result, record
+}
+
+// ListAIGradings handles GET /answers/:id/ai-gradings, the AI grading
+// decisions made for an answer, for the quiz creator to audit
+func (h *QuizAttemptHandler) ListAIGradings(c *gin.Context) {
+	answerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid answer ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if _, ok := h.requireAnswerCreator(c, answerID); !ok {
+		return
+	}
+
+	gradings, err := h.repo.ListAIGradings(c.Request.Context(), answerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to list AI gradings",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gradings,
+	})
+}
@@ -2,35 +2,49 @@ package handlers
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"math/rand"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"QuizApp/services/study-service/src/pkg/grading"
 	"QuizApp/services/study-service/src/pkg/models"
 	"QuizApp/services/study-service/src/pkg/repository"
 )
 
-// QuizAttemptHandler handles HTTP requests for quiz attempts
+// QuizAttemptHandler handles HTTP requests for quiz attempts. AI-graded
+// answers are graded by aiGrader and stand if it is at least aiThreshold
+// confident in them.
 type QuizAttemptHandler struct {
-	repo repository.QuizAttemptRepository
+	repo        repository.QuizAttemptRepository
+	aiGrader    grading.AIGrader
+	aiThreshold float64
 }
 
-// NewQuizAttemptHandler creates a new QuizAttemptHandler
-func NewQuizAttemptHandler(repo repository.QuizAttemptRepository) *QuizAttemptHandler {
-	return &QuizAttemptHandler{repo: repo}
+// NewQuizAttemptHandler creates a new QuizAttemptHandler. Without an
+// aiGrader, AI-graded answers are left for the quiz creator to review.
+func NewQuizAttemptHandler(repo repository.QuizAttemptRepository, aiGrader grading.AIGrader, aiThreshold float64) *QuizAttemptHandler {
+	return &QuizAttemptHandler{repo: repo, aiGrader: aiGrader, aiThreshold: aiThreshold}
 }
 
-// StartAttempt handles POST /attempts
+// StartAttempt handles POST /attempts for the user named by X-User-ID, who
+// must be able to view the quiz. The attempt and its limit belong to that
+// user; a body userId is optional and must name the same user.
 func (h *QuizAttemptHandler) StartAttempt(c *gin.Context) {
 	var input struct {
-		UserID         string `json:"userId" binding:"required"`
+		UserID         string `json:"userId"`
 		QuizID         string `json:"quizId" binding:"required"`
-		TotalQuestions int    `json:"totalQuestions" binding:"required"`
+		TotalQuestions int    `json:"totalQuestions"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -47,32 +61,134 @@ func (h *QuizAttemptHandler) StartAttempt(c *gin.Context) {
 	log.Printf("StartAttempt: Received request with userId: %s, quizId: %s, totalQuestions: %d", 
 		input.UserID, input.QuizID, input.TotalQuestions)
 
-	// Parse UUIDs from string
-	userID, err := uuid.Parse(input.UserID)
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	if input.UserID != "" {
+		if bodyUserID, err := uuid.Parse(input.UserID); err != nil || bodyUserID != userID {
+			log.Printf("StartAttempt: userId %s does not match caller %s", input.UserID, userID)
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Cannot start an attempt for another user",
+			})
+			return
+		}
+	}
+
+	quizID, err := uuid.Parse(input.QuizID)
 	if err != nil {
-		log.Printf("StartAttempt: Invalid userId - %v", err)
+		log.Printf("StartAttempt: Invalid quizId - %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"error":   "Invalid user ID format",
+			"error":   "Invalid quiz ID format",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	quizID, err := uuid.Parse(input.QuizID)
+	// The quiz is read with its answer keys as a trusted service, so first
+	// check that the learner may see it at all. Quizzes they cannot see are
+	// reported as not found, as the content service does.
+	canView, err := h.repo.CanViewQuiz(c.Request.Context(), quizID, userID)
+	if err != nil && err != repository.ErrQuizNotFound {
+		log.Printf("StartAttempt: Failed to check access to quiz - %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"success": false,
+			"error":   "Failed to get quiz",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !canView {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Quiz not found",
+		})
+		return
+	}
+
+	// Pin the attempt to the quiz's live revision so later edits to the quiz
+	// cannot change what this attempt is graded against
+	quiz, err := h.repo.GetQuiz(c.Request.Context(), quizID)
+	if err == repository.ErrQuizNotFound {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Quiz not found",
+		})
+		return
+	}
 	if err != nil {
-		log.Printf("StartAttempt: Invalid quizId - %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{
+		log.Printf("StartAttempt: Failed to get quiz - %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{
 			"success": false,
-			"error":   "Invalid quiz ID format",
+			"error":   "Failed to get quiz",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	// Validate totalQuestions
-	if input.TotalQuestions <= 0 {
-		log.Printf("StartAttempt: Invalid totalQuestions: %d", input.TotalQuestions)
+	if maxAttempts := quiz.Settings.MaxAttempts; maxAttempts > 0 {
+		count, err := h.repo.CountUserAttempts(c.Request.Context(), userID, quizID)
+		if err != nil {
+			log.Printf("StartAttempt: Failed to count attempts - %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to count attempts",
+				"details": err.Error(),
+			})
+			return
+		}
+		if count >= maxAttempts {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Maximum number of attempts reached",
+				"details": fmt.Sprintf("This quiz allows %d attempts", maxAttempts),
+			})
+			return
+		}
+	}
+
+	modelAttempt := models.NewQuizAttempt(userID, quizID, 0)
+	modelAttempt.Apply(quiz.Settings)
+
+	// The attempt gets the quiz's own questions and its own draw from the
+	// quiz's pools. The seed comes from the attempt ID, so learners get
+	// different questions and each draw can be reproduced.
+	questions := quiz.Questions
+	seed := int64(binary.BigEndian.Uint64(modelAttempt.ID[:8]))
+	var drawSeed *int64
+	if len(quiz.Draws) > 0 {
+		drawn, err := h.repo.DrawQuestions(c.Request.Context(), quizID, seed)
+		if errors.Is(err, repository.ErrNotEnoughQuestions) {
+			log.Printf("StartAttempt: Quiz %s cannot draw its questions - %v", quizID, err)
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"error":   "Quiz's question pools do not hold enough questions",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			log.Printf("StartAttempt: Failed to draw questions - %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{
+				"success": false,
+				"error":   "Failed to draw questions",
+				"details": err.Error(),
+			})
+			return
+		}
+		questions = append(append([]*repository.Question{}, quiz.Questions...), drawn...)
+		drawSeed = &seed
+	}
+
+	// Shuffled questions are stored in their shuffled order, so the learner
+	// sees the same order for the whole attempt
+	questions = shuffleQuestions(questions, quiz.Settings, rand.New(rand.NewSource(seed)))
+
+	// The question count comes from the pinned quiz, not the client
+	if len(questions) == 0 {
+		log.Printf("StartAttempt: Quiz %s has no questions", quizID)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   "Total questions must be greater than 0",
@@ -80,11 +196,15 @@ func (h *QuizAttemptHandler) StartAttempt(c *gin.Context) {
 		return
 	}
 
-	modelAttempt := models.NewQuizAttempt(userID, quizID, input.TotalQuestions)
+	modelAttempt.TotalQuestions = len(questions)
+	modelAttempt.QuizVersion = quiz.Version
+	modelAttempt.QuizRevisionID = quiz.RevisionID
 	attempt := &repository.QuizAttempt{
 		ID:             modelAttempt.ID,
 		UserID:         modelAttempt.UserID,
 		QuizID:         modelAttempt.QuizID,
+		QuizVersion:    modelAttempt.QuizVersion,
+		QuizRevisionID: modelAttempt.QuizRevisionID,
 		Status:         string(modelAttempt.Status),
 		TotalQuestions: modelAttempt.TotalQuestions,
 		CorrectAnswers: 0, // Start with 0 correct answers
@@ -93,6 +213,9 @@ func (h *QuizAttemptHandler) StartAttempt(c *gin.Context) {
 		CompletedAt:    modelAttempt.CompletedAt,
 		CreatedAt:      modelAttempt.CreatedAt,
 		UpdatedAt:      modelAttempt.UpdatedAt,
+		DrawSeed:       drawSeed,
+		Questions:      questions,
+		Settings:       modelAttempt.Settings,
 		
 		// Set the derived field
 		CurrentQuestionIndex: 0,
@@ -112,6 +235,12 @@ func (h *QuizAttemptHandler) StartAttempt(c *gin.Context) {
 
 	log.Printf("StartAttempt: Successfully created attempt with ID: %s", attempt.ID)
 
+	// The attempt count only orders quiz listings, so failing to report it
+	// does not fail the attempt
+	if err := h.repo.RecordQuizAttempt(c.Request.Context(), attempt.QuizID); err != nil {
+		log.Printf("StartAttempt: Failed to record attempt at quiz %s - %v", attempt.QuizID, err)
+	}
+
 	// Set the current question index in the response model
 	modelAttempt.CurrentQuestionIndex = 0
 
@@ -149,21 +278,12 @@ func (h *QuizAttemptHandler) GetAttempt(c *gin.Context) {
 		})
 		return
 	}
-
-	modelAttempt := &models.QuizAttempt{
-		ID:                  attempt.ID,
-		UserID:             attempt.UserID,
-		QuizID:             attempt.QuizID,
-		Status:             models.AttemptStatus(attempt.Status),
-		CurrentQuestionIndex: attempt.CurrentQuestionIndex,
-		TotalQuestions:     attempt.TotalQuestions,
-		Score:              attempt.Score,
-		StartedAt:          attempt.StartedAt,
-		CompletedAt:        attempt.CompletedAt,
-		CreatedAt:          attempt.CreatedAt,
-		UpdatedAt:          attempt.UpdatedAt,
+	if !requireAttemptOwner(c, attempt) {
+		return
 	}
 
+	modelAttempt := toModelAttempt(attempt)
+	modelAttempt.HideAnswers()
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    modelAttempt,
@@ -182,10 +302,8 @@ func (h *QuizAttemptHandler) GetQuestions(c *gin.Context) {
 		return
 	}
 
-	log.Printf("DEBUG: GetQuestions handler called for attempt ID: %s", attemptID.String())
 	attempt, err := h.repo.GetAttempt(c.Request.Context(), attemptID)
 	if err == repository.ErrAttemptNotFound {
-		log.Printf("DEBUG: Attempt not found: %s", attemptID.String())
 		c.JSON(http.StatusNotFound, gin.H{
 			"success": false,
 			"error":   "Attempt not found",
@@ -193,7 +311,7 @@ func (h *QuizAttemptHandler) GetQuestions(c *gin.Context) {
 		return
 	}
 	if err != nil {
-		log.Printf("DEBUG: Error getting attempt: %v", err)
+		log.Printf("GetQuestions: Failed to get attempt - %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to get attempt",
@@ -201,15 +319,13 @@ func (h *QuizAttemptHandler) GetQuestions(c *gin.Context) {
 		})
 		return
 	}
+	if !requireAttemptOwner(c, attempt) {
+		return
+	}
 
-	log.Printf("DEBUG: Retrieved attempt for quiz ID: %s", attempt.QuizID.String())
-	log.Printf("DEBUG: Current question index: %d, Total questions: %d, Correct answers: %d", 
-		attempt.CurrentQuestionIndex, attempt.TotalQuestions, attempt.CorrectAnswers)
-
-	// Let's check if the actual questions from content service match our hardcoded ones
-	questions, err := h.repo.GetQuestions(c.Request.Context(), attempt.QuizID)
+	questions, err := h.attemptQuestions(c.Request.Context(), attempt)
 	if err != nil {
-		log.Printf("DEBUG: Error getting questions from content service: %v", err)
+		log.Printf("GetQuestions: Failed to get questions - %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to get questions",
@@ -218,14 +334,17 @@ func (h *QuizAttemptHandler) GetQuestions(c *gin.Context) {
 		return
 	}
 
-	log.Printf("DEBUG: Content service returned %d questions", len(questions))
+	h.refreshMedia(c.Request.Context(), questions)
+
+	// The questions carry answer keys for grading; never send those to the learner
+	takerQuestions := make([]*repository.Question, len(questions))
 	for i, q := range questions {
-		log.Printf("DEBUG: Question %d: %s", i+1, q.Text)
+		takerQuestions[i] = q.ForTaker()
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    questions,
+		"data":    takerQuestions,
 	})
 }
 
@@ -242,27 +361,21 @@ func (h *QuizAttemptHandler) SubmitAnswer(c *gin.Context) {
 		return
 	}
 
-	// Log the raw request body for debugging
-	var rawData interface{}
+	// Read the raw request body; it holds the learner's answer, so it is not logged
 	rawBody, err := c.GetRawData()
 	if err != nil {
 		log.Printf("ERROR: Failed to read raw request body: %v", err)
 	} else {
-		log.Printf("DEBUG: Raw request body: %s", string(rawBody))
-		if err := json.Unmarshal(rawBody, &rawData); err != nil {
-			log.Printf("ERROR: Failed to parse raw request body as JSON: %v", err)
-		} else {
-			log.Printf("DEBUG: Parsed request body: %+v", rawData)
-		}
 		// Restore the request body for binding
 		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(rawBody))
 	}
 
-	// Create a more flexible input struct for parsing
+	// Create a more flexible input struct for parsing.
+	// Any client-supplied isCorrect flag is ignored; answers are graded server-side.
+	// Multiple select answers are sent as an array of the chosen options.
 	var jsonInput struct {
-		QuestionID interface{} `json:"questionId"`
-		Answer     string      `json:"answer"`
-		IsCorrect  bool        `json:"isCorrect"`
+		QuestionID interface{}     `json:"questionId"`
+		Answer     json.RawMessage `json:"answer"`
 	}
 
 	if err := json.Unmarshal(rawBody, &jsonInput); err != nil {
@@ -281,7 +394,6 @@ func (h *QuizAttemptHandler) SubmitAnswer(c *gin.Context) {
 	switch qid := jsonInput.QuestionID.(type) {
 	case string:
 		// If questionId is a string, try to parse it as UUID
-		log.Printf("DEBUG: QuestionID is a string: %s", qid)
 		parsedUUID, err := uuid.Parse(qid)
 		if err != nil {
 			log.Printf("ERROR: Failed to parse questionId as UUID: %v", err)
@@ -312,19 +424,30 @@ func (h *QuizAttemptHandler) SubmitAnswer(c *gin.Context) {
 		return
 	}
 
+	answerText, ok := answerString(jsonInput.Answer)
+	if !ok {
+		// The answer itself is the learner's, so only its shape is logged
+		log.Printf("ERROR: Answer is not a string, an array of strings or an object of strings: got %s of %d bytes",
+			jsonKind(jsonInput.Answer), len(jsonInput.Answer))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid answer format",
+			"details": "Answer must be a string, an array of strings or an object mapping prompts to options",
+		})
+		return
+	}
+
 	// Now use the parsed UUID and other values from jsonInput
 	input := struct {
 		QuestionID uuid.UUID
 		Answer     string
-		IsCorrect  bool
 	}{
 		QuestionID: questionIDUUID,
-		Answer:     jsonInput.Answer,
-		IsCorrect:  jsonInput.IsCorrect,
+		Answer:     answerText,
 	}
 
-	log.Printf("DEBUG: Submitting answer for attempt ID: %s, question ID: %s, answer: %s, isCorrect: %v", 
-		attemptID, input.QuestionID, input.Answer, input.IsCorrect)
+	log.Printf("SubmitAnswer: Submitting answer for attempt ID: %s, question ID: %s",
+		attemptID, input.QuestionID)
 
 	attempt, err := h.repo.GetAttempt(c.Request.Context(), attemptID)
 	if err == repository.ErrAttemptNotFound {
@@ -344,6 +467,9 @@ func (h *QuizAttemptHandler) SubmitAnswer(c *gin.Context) {
 		})
 		return
 	}
+	if !requireAttemptOwner(c, attempt) {
+		return
+	}
 
 	if attempt.Status != string(models.AttemptStatusInProgress) {
 		log.Printf("ERROR: Attempt is not in progress: %s", attemptID)
@@ -354,42 +480,100 @@ func (h *QuizAttemptHandler) SubmitAnswer(c *gin.Context) {
 		return
 	}
 
-	log.Printf("DEBUG: Current attempt state: ID: %s, quiz ID: %s, correctAnswers: %d, score: %f", 
-		attempt.ID, attempt.QuizID, attempt.CorrectAnswers, attempt.Score)
+	// Once time is up the attempt is completed with the answers given in time
+	if toModelAttempt(attempt).Expired(time.Now().UTC()) {
+		log.Printf("ERROR: Attempt %s is out of time", attemptID)
+		if _, err := h.complete(c.Request.Context(), attempt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to complete attempt",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "Time limit has expired",
+			"details": "The attempt was completed with the answers given in time",
+		})
+		return
+	}
 
-	modelAttempt := &models.QuizAttempt{
-		ID:                  attempt.ID,
-		UserID:             attempt.UserID,
-		QuizID:             attempt.QuizID,
-		Status:             models.AttemptStatus(attempt.Status),
-		CurrentQuestionIndex: attempt.CurrentQuestionIndex,
-		TotalQuestions:     attempt.TotalQuestions,
-		Score:              attempt.Score,
-		StartedAt:          attempt.StartedAt,
-		CompletedAt:        attempt.CompletedAt,
-		CreatedAt:          attempt.CreatedAt,
-		UpdatedAt:          attempt.UpdatedAt,
+	for _, existing := range attempt.Answers {
+		if existing.QuestionID == input.QuestionID {
+			log.Printf("ERROR: Question %s already answered in attempt %s", input.QuestionID, attemptID)
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"error":   "Question has already been answered",
+			})
+			return
+		}
 	}
 
-	answer := modelAttempt.Submit(input.QuestionID, input.Answer, input.IsCorrect)
+	// Grade against the answer keys of the questions the attempt was given
+	questions, err := h.attemptQuestions(c.Request.Context(), attempt)
+	if err != nil {
+		log.Printf("ERROR: Failed to get questions for grading: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get questions",
+			"details": err.Error(),
+		})
+		return
+	}
 
-	// Update repository attempt with model changes
-	if input.IsCorrect {
-		attempt.CorrectAnswers++
+	question := findQuestion(questions, input.QuestionID)
+	if question == nil {
+		log.Printf("ERROR: Question %s does not belong to quiz %s", input.QuestionID, attempt.QuizID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Question does not belong to this quiz",
+		})
+		return
 	}
+
+	result, err := grading.Grade(question, input.Answer)
+	if err != nil {
+		log.Printf("ERROR: Failed to grade answer for question %s: %v", question.ID, err)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"success": false,
+			"error":   "Unable to grade answer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var aiGrading *repository.AIGrading
+	if result.NeedsReview && models.GradingMode(question.Grading) == models.GradingAI {
+		result, aiGrading = h.gradeWithAI(c.Request.Context(), attempt.UserID, question, input.Answer)
+	}
+
+	modelAttempt := toModelAttempt(attempt)
+	var answer models.Answer
+	if result.NeedsReview {
+		answer = modelAttempt.SubmitForReview(input.QuestionID, input.Answer)
+	} else {
+		answer = modelAttempt.Submit(input.QuestionID, input.Answer, result.Correct, result.Score)
+	}
+
+	// Update repository attempt with the server-derived score
+	attempt.CorrectAnswers = modelAttempt.CorrectAnswers
 	attempt.Score = modelAttempt.Score
 	attempt.UpdatedAt = modelAttempt.UpdatedAt
 
-	log.Printf("DEBUG: Updated attempt state: correctAnswers: %d, score: %f", 
-		attempt.CorrectAnswers, attempt.Score)
-
 	repoAnswer := &repository.Answer{
-		ID:         answer.ID,
-		AttemptID:  answer.AttemptID,
-		QuestionID: answer.QuestionID,
-		Answer:     answer.Answer,
-		IsCorrect:  answer.IsCorrect,
-		CreatedAt:  answer.CreatedAt,
+		ID:          answer.ID,
+		AttemptID:   answer.AttemptID,
+		QuestionID:  answer.QuestionID,
+		Answer:      answer.Answer,
+		IsCorrect:   answer.IsCorrect,
+		Score:       answer.Score,
+		NeedsReview: answer.NeedsReview,
+		CreatedAt:   answer.CreatedAt,
+	}
+	if result.Match != nil {
+		repoAnswer.MatchedRule = &result.Match.Rule
+		repoAnswer.MatchedAnswer = &result.Match.Index
 	}
 
 	if err := h.repo.AddAnswer(c.Request.Context(), repoAnswer); err != nil {
@@ -402,6 +586,19 @@ func (h *QuizAttemptHandler) SubmitAnswer(c *gin.Context) {
 		return
 	}
 
+	if aiGrading != nil {
+		aiGrading.AnswerID = answer.ID
+		if err := h.repo.SaveAIGrading(c.Request.Context(), aiGrading); err != nil {
+			log.Printf("ERROR: Failed to save AI grading: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to save AI grading",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
 	if err := h.repo.UpdateAttempt(c.Request.Context(), attempt); err != nil {
 		log.Printf("ERROR: Failed to update attempt: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -412,10 +609,29 @@ func (h *QuizAttemptHandler) SubmitAnswer(c *gin.Context) {
 		return
 	}
 
-	log.Printf("DEBUG: Successfully submitted answer for attempt ID: %s", attemptID)
+	log.Printf("SubmitAnswer: Submitted answer for attempt ID: %s", attemptID)
+	if !modelAttempt.RevealsAnswers() {
+		// The quiz withholds grading until the attempt is completed, or for good
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data": gin.H{
+				"answer": answer.Hidden(),
+			},
+		})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    answer,
+		"data": gin.H{
+			"answer":         answer,
+			"isCorrect":      result.Correct,
+			"answerScore":    result.Score,
+			"blanks":         result.Blanks,
+			"match":          result.Match,
+			"needsReview":    result.NeedsReview,
+			"correctAnswers": attempt.CorrectAnswers,
+			"score":          attempt.Score,
+		},
 	})
 }
 
@@ -447,6 +663,9 @@ func (h *QuizAttemptHandler) CompleteAttempt(c *gin.Context) {
 		})
 		return
 	}
+	if !requireAttemptOwner(c, attempt) {
+		return
+	}
 
 	if attempt.Status != string(models.AttemptStatusInProgress) {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -456,53 +675,8 @@ func (h *QuizAttemptHandler) CompleteAttempt(c *gin.Context) {
 		return
 	}
 
-	modelAttempt := &models.QuizAttempt{
-		ID:                  attempt.ID,
-		UserID:             attempt.UserID,
-		QuizID:             attempt.QuizID,
-		Status:             models.AttemptStatus(attempt.Status),
-		CurrentQuestionIndex: attempt.CurrentQuestionIndex,
-		TotalQuestions:     attempt.TotalQuestions,
-		Score:              attempt.Score,
-		StartedAt:          attempt.StartedAt,
-		CompletedAt:        attempt.CompletedAt,
-		CreatedAt:          attempt.CreatedAt,
-		UpdatedAt:          attempt.UpdatedAt,
-	}
-
-	modelAttempt.Complete()
-
-	// Update repository attempt with model changes
-	attempt.Status = string(modelAttempt.Status)
-	attempt.CompletedAt = modelAttempt.CompletedAt
-	attempt.UpdatedAt = modelAttempt.UpdatedAt
-	
-	// Recalculate the final score before completing
-	// Get the answers for this attempt
-	answers, err := h.repo.GetAttemptAnswers(c.Request.Context(), attemptID)
+	modelAttempt, err := h.complete(c.Request.Context(), attempt)
 	if err != nil {
-		log.Printf("Warning: Failed to get answers for score calculation: %v", err)
-	} else {
-		// Count correct answers
-		correctAnswers := 0
-		for _, ans := range answers {
-			if ans.IsCorrect {
-				correctAnswers++
-			}
-		}
-		
-		// Calculate and update the score
-		if attempt.TotalQuestions > 0 {
-			attempt.CorrectAnswers = correctAnswers
-			attempt.Score = float64(correctAnswers) / float64(attempt.TotalQuestions) * 100
-			log.Printf("CompleteAttempt: Calculated final score for attempt %s: %d/%d correct answers, score: %.2f%%", 
-				attemptID, correctAnswers, attempt.TotalQuestions, attempt.Score)
-		} else {
-			log.Printf("Warning: Cannot calculate score for attempt %s - total questions is zero", attemptID)
-		}
-	}
-
-	if err := h.repo.UpdateAttempt(c.Request.Context(), attempt); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to complete attempt",
@@ -511,13 +685,36 @@ func (h *QuizAttemptHandler) CompleteAttempt(c *gin.Context) {
 		return
 	}
 
+	modelAttempt.HideAnswers()
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    modelAttempt,
 	})
 }
 
-// ListUserAttempts handles GET /users/:id/attempts
+// complete completes an attempt, scoring it from its server-graded answers
+// and judging it against the quiz's pass mark, and saves it
+func (h *QuizAttemptHandler) complete(ctx context.Context, attempt *repository.QuizAttempt) (*models.QuizAttempt, error) {
+	modelAttempt := toModelAttempt(attempt)
+	modelAttempt.Complete()
+
+	// Update repository attempt with model changes
+	attempt.Status = string(modelAttempt.Status)
+	attempt.CompletedAt = modelAttempt.CompletedAt
+	attempt.UpdatedAt = modelAttempt.UpdatedAt
+
+	// Recalculate the final score from the server-graded answers
+	modelAttempt.Rescore()
+	attempt.CorrectAnswers = modelAttempt.CorrectAnswers
+	attempt.Score = modelAttempt.Score
+	log.Printf("CompleteAttempt: Calculated final score for attempt %s: %d/%d correct answers, score: %.2f%%",
+		attempt.ID, attempt.CorrectAnswers, attempt.TotalQuestions, attempt.Score)
+
+	return modelAttempt, h.repo.UpdateAttempt(ctx, attempt)
+}
+
+// ListUserAttempts handles GET /users/:id/attempts. Learners may only list
+// their own attempts.
 func (h *QuizAttemptHandler) ListUserAttempts(c *gin.Context) {
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -529,6 +726,18 @@ func (h *QuizAttemptHandler) ListUserAttempts(c *gin.Context) {
 		return
 	}
 
+	callerID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	if callerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "Not allowed to list another user's attempts",
+		})
+		return
+	}
+
 	limit := 10
 	offset := 0
 	if limitStr := c.Query("limit"); limitStr != "" {
@@ -555,19 +764,8 @@ func (h *QuizAttemptHandler) ListUserAttempts(c *gin.Context) {
 	// Convert repository attempts to model attempts
 	modelAttempts := make([]*models.QuizAttempt, len(attempts))
 	for i, attempt := range attempts {
-		modelAttempts[i] = &models.QuizAttempt{
-			ID:                  attempt.ID,
-			UserID:             attempt.UserID,
-			QuizID:             attempt.QuizID,
-			Status:             models.AttemptStatus(attempt.Status),
-			CurrentQuestionIndex: attempt.CurrentQuestionIndex,
-			TotalQuestions:     attempt.TotalQuestions,
-			Score:              attempt.Score,
-			StartedAt:          attempt.StartedAt,
-			CompletedAt:        attempt.CompletedAt,
-			CreatedAt:          attempt.CreatedAt,
-			UpdatedAt:          attempt.UpdatedAt,
-		}
+		modelAttempts[i] = toModelAttempt(attempt)
+		modelAttempts[i].HideAnswers()
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -609,6 +807,18 @@ func (h *QuizAttemptHandler) GetAnswers(c *gin.Context) {
 		})
 		return
 	}
+	if !requireAttemptOwner(c, attempt) {
+		return
+	}
+
+	if !toModelAttempt(attempt).RevealsAnswers() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "Answers are not revealed for this attempt",
+			"details": fmt.Sprintf("The quiz reveals answers %s", attempt.Settings.RevealAnswers),
+		})
+		return
+	}
 
 	// Get the answers for this attempt
 	answers, err := h.repo.GetAttemptAnswers(c.Request.Context(), attemptID)
@@ -623,7 +833,7 @@ func (h *QuizAttemptHandler) GetAnswers(c *gin.Context) {
 	}
 
 	// Get questions to include with answers
-	questions, err := h.repo.GetQuestions(c.Request.Context(), attempt.QuizID)
+	questions, err := h.attemptQuestions(c.Request.Context(), attempt)
 	if err != nil {
 		log.Printf("GetAnswers: Error retrieving questions: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -651,16 +861,30 @@ func (h *QuizAttemptHandler) GetAnswers(c *gin.Context) {
 		}
 
 		responseAnswer := map[string]interface{}{
-			"id":         answer.ID.String(),
-			"questionId": answer.QuestionID.String(),
-			"answer":     answer.Answer,
-			"isCorrect":  answer.IsCorrect,
+			"id":            answer.ID.String(),
+			"questionId":    answer.QuestionID.String(),
+			"answer":        answer.Answer,
+			"isCorrect":     answer.IsCorrect,
+			"score":         answer.Score,
+			"matchedRule":   answer.MatchedRule,
+			"matchedAnswer": answer.MatchedAnswer,
+			"needsReview":   answer.NeedsReview,
+			"reviewComment": answer.ReviewComment,
+			"reviewedAt":    answer.ReviewedAt,
 			"question": map[string]interface{}{
-				"text":          question.Text,
-				"options":       question.Options,
-				"correctAnswer": question.CorrectAnswer,
-				// Note: The repository.Question doesn't have an Explanation field, so we're omitting it
-				// We could fetch this from the content service if needed
+				"text":            question.Text,
+				"type":            question.Type,
+				"grading":         question.Grading,
+				"options":         question.Options,
+				"correctAnswer":   question.CorrectAnswer,
+				"correctAnswers":  question.CorrectAnswers,
+				"prompts":         question.Prompts,
+				"matches":         question.Matches,
+				"correctOrder":    question.CorrectOrder,
+				"numeric":         question.Numeric,
+				"blanks":          question.Blanks,
+				"acceptedAnswers": question.AcceptedAnswers,
+				"explanation":     question.Explanation,
 			},
 		}
 		responseAnswers = append(responseAnswers, responseAnswer)
@@ -673,4 +897,173 @@ func (h *QuizAttemptHandler) GetAnswers(c *gin.Context) {
 		"success": true,
 		"data":    responseAnswers,
 	})
-} 
\ No newline at end of file
+} 
+
+// toModelAttempt converts a stored attempt, including its graded answers, into the API model
+func toModelAttempt(attempt *repository.QuizAttempt) *models.QuizAttempt {
+	modelAttempt := &models.QuizAttempt{
+		ID:                   attempt.ID,
+		UserID:               attempt.UserID,
+		QuizID:               attempt.QuizID,
+		QuizVersion:          attempt.QuizVersion,
+		QuizRevisionID:       attempt.QuizRevisionID,
+		Status:               models.AttemptStatus(attempt.Status),
+		CurrentQuestionIndex: attempt.CurrentQuestionIndex,
+		TotalQuestions:       attempt.TotalQuestions,
+		CorrectAnswers:       attempt.CorrectAnswers,
+		Score:                attempt.Score,
+		StartedAt:            attempt.StartedAt,
+		CompletedAt:          attempt.CompletedAt,
+		CreatedAt:            attempt.CreatedAt,
+		UpdatedAt:            attempt.UpdatedAt,
+	}
+	for _, answer := range attempt.Answers {
+		modelAttempt.Answers = append(modelAttempt.Answers, toModelAnswer(answer))
+	}
+	if attempt.Settings != nil {
+		modelAttempt.Apply(*attempt.Settings)
+	}
+	return modelAttempt
+}
+
+// toModelAnswer converts a stored answer into the API model
+func toModelAnswer(answer repository.Answer) models.Answer {
+	modelAnswer := models.Answer{
+		ID:          answer.ID,
+		AttemptID:   answer.AttemptID,
+		QuestionID:  answer.QuestionID,
+		Answer:      answer.Answer,
+		IsCorrect:   answer.IsCorrect,
+		Score:       answer.Score,
+		NeedsReview: answer.NeedsReview,
+		ReviewedAt:  answer.ReviewedAt,
+		CreatedAt:   answer.CreatedAt,
+	}
+	if answer.ReviewComment != nil {
+		modelAnswer.ReviewComment = *answer.ReviewComment
+	}
+	return modelAnswer
+}
+
+// jsonKind names the kind of a JSON value from its first byte, for logging
+// a value without its content
+func jsonKind(raw json.RawMessage) string {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return "nothing"
+	}
+	switch trimmed[0] {
+	case '"':
+		return "a string"
+	case '[':
+		return "an array"
+	case '{':
+		return "an object"
+	case 't', 'f':
+		return "a boolean"
+	case 'n':
+		return "null"
+	default:
+		return "a number"
+	}
+}
+
+// answerString reads a submitted answer: text as is, or an array of chosen
+// or ordered options, or an object matching prompts to options, re-encoded
+// as JSON so it can be stored and graded as text
+func answerString(raw json.RawMessage) (string, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", true
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, true
+	}
+	var value interface{}
+	var chosen []string
+	var matched map[string]string
+	switch {
+	case json.Unmarshal(raw, &chosen) == nil:
+		if chosen == nil {
+			chosen = []string{}
+		}
+		value = chosen
+	case json.Unmarshal(raw, &matched) == nil:
+		value = matched
+	default:
+		return "", false
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
+
+// attemptQuestions returns the questions an attempt was given, with their
+// answer keys. Attempts started before questions were stored with them get
+// the questions of the revision they are pinned to.
+func (h *QuizAttemptHandler) attemptQuestions(ctx context.Context, attempt *repository.QuizAttempt) ([]*repository.Question, error) {
+	questions, err := h.repo.GetAttemptQuestions(ctx, attempt.ID)
+	if err != nil || len(questions) > 0 {
+		return questions, err
+	}
+	return h.repo.GetQuestions(ctx, attempt.QuizID, attempt.QuizRevisionID)
+}
+
+// refreshMedia gives the questions' attachments fresh links, since questions
+// stored with an attempt outlive the links they were stored with. Failures
+// are logged and leave the old links in place.
+func (h *QuizAttemptHandler) refreshMedia(ctx context.Context, questions []*repository.Question) {
+	var ids []uuid.UUID
+	for _, question := range questions {
+		for _, attachment := range question.Attachments() {
+			ids = append(ids, attachment.MediaID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	signed, err := h.repo.SignMedia(ctx, ids)
+	if err != nil {
+		log.Printf("Failed to refresh media links: %v", err)
+		return
+	}
+	for _, question := range questions {
+		for _, attachment := range question.Attachments() {
+			if fresh, ok := signed[attachment.MediaID]; ok {
+				attachment.URL = fresh.URL
+				attachment.ThumbnailURL = fresh.ThumbnailURL
+			}
+		}
+	}
+}
+
+// shuffleQuestions returns the questions in random order, or with their
+// options in random order, as the quiz's settings ask
+func shuffleQuestions(questions []*repository.Question, settings models.QuizSettings, rng *rand.Rand) []*repository.Question {
+	shuffled := make([]*repository.Question, len(questions))
+	copy(shuffled, questions)
+	if settings.ShuffleQuestions {
+		rng.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+	}
+	if settings.ShuffleOptions {
+		for i, question := range shuffled {
+			shuffled[i] = question.WithShuffledOptions(rng)
+		}
+	}
+	return shuffled
+}
+
+// findQuestion returns the question with the given ID, or nil if the quiz has no such question
+func findQuestion(questions []*repository.Question, id uuid.UUID) *repository.Question {
+	for _, question := range questions {
+		if question.ID == id {
+			return question
+		}
+	}
+	return nil
+}
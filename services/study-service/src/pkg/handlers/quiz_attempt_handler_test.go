@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"QuizApp/services/study-service/src/pkg/models"
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+// fakeAttemptRepo keeps a single attempt in memory and records whether a write reached it
+type fakeAttemptRepo struct {
+	repository.QuizAttemptRepository
+	attempt *repository.QuizAttempt
+	updated bool
+}
+
+func (r *fakeAttemptRepo) GetAttempt(ctx context.Context, id uuid.UUID) (*repository.QuizAttempt, error) {
+	if r.attempt == nil || r.attempt.ID != id {
+		return nil, repository.ErrAttemptNotFound
+	}
+	attempt := *r.attempt
+	return &attempt, nil
+}
+
+func (r *fakeAttemptRepo) UpdateAttempt(ctx context.Context, attempt *repository.QuizAttempt) error {
+	r.updated = true
+	r.attempt = attempt
+	return nil
+}
+
+func (r *fakeAttemptRepo) ListUserAttempts(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*repository.QuizAttempt, error) {
+	if r.attempt == nil || r.attempt.UserID != userID {
+		return nil, nil
+	}
+	return []*repository.QuizAttempt{r.attempt}, nil
+}
+
+func newAttemptRouter(repo *fakeAttemptRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewQuizAttemptHandler(repo, nil, 0)
+	r := gin.New()
+	r.GET("/attempts/:id", h.GetAttempt)
+	r.GET("/attempts/:id/questions", h.GetQuestions)
+	r.GET("/attempts/:id/answers", h.GetAnswers)
+	r.POST("/attempts/:id/answers", h.SubmitAnswer)
+	r.POST("/attempts/:id/complete", h.CompleteAttempt)
+	r.GET("/users/:id/attempts", h.ListUserAttempts)
+	return r
+}
+
+// send performs a request as user, or anonymously for uuid.Nil
+func send(r http.Handler, user uuid.UUID, method, path, body string) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user != uuid.Nil {
+		req.Header.Set("X-User-ID", user.String())
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func testAttempt(learner uuid.UUID) *repository.QuizAttempt {
+	now := time.Now().UTC()
+	return &repository.QuizAttempt{
+		ID:             uuid.New(),
+		UserID:         learner,
+		QuizID:         uuid.New(),
+		Status:         string(models.AttemptStatusInProgress),
+		TotalQuestions: 1,
+		StartedAt:      now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+func TestAttemptsBelongToTheirLearner(t *testing.T) {
+	learner := uuid.New()
+	answer := `{"questionId": "` + uuid.New().String() + `", "answer": "Paris"}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "get attempt", method: http.MethodGet, path: ""},
+		{name: "get questions", method: http.MethodGet, path: "/questions"},
+		{name: "get answers", method: http.MethodGet, path: "/answers"},
+		{name: "submit answer", method: http.MethodPost, path: "/answers", body: answer},
+		{name: "complete", method: http.MethodPost, path: "/complete"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAttemptRepo{attempt: testAttempt(learner)}
+			r := newAttemptRouter(repo)
+			path := "/attempts/" + repo.attempt.ID.String() + tt.path
+
+			assert.Equal(t, http.StatusNotFound, send(r, uuid.New(), tt.method, path, tt.body), "another learner's attempt")
+			assert.Equal(t, http.StatusUnauthorized, send(r, uuid.Nil, tt.method, path, tt.body))
+			assert.False(t, repo.updated)
+		})
+	}
+
+	repo := &fakeAttemptRepo{attempt: testAttempt(learner)}
+	r := newAttemptRouter(repo)
+	path := "/attempts/" + repo.attempt.ID.String()
+	assert.Equal(t, http.StatusOK, send(r, learner, http.MethodGet, path, ""))
+	assert.Equal(t, http.StatusOK, send(r, learner, http.MethodPost, path+"/complete", ""))
+	assert.True(t, repo.updated)
+}
+
+func TestListUserAttemptsNeedsTheUser(t *testing.T) {
+	learner := uuid.New()
+	r := newAttemptRouter(&fakeAttemptRepo{attempt: testAttempt(learner)})
+	path := "/users/" + learner.String() + "/attempts"
+
+	assert.Equal(t, http.StatusOK, send(r, learner, http.MethodGet, path, ""))
+	assert.Equal(t, http.StatusForbidden, send(r, uuid.New(), http.MethodGet, path, ""))
+	assert.Equal(t, http.StatusUnauthorized, send(r, uuid.Nil, http.MethodGet, path, ""))
+}
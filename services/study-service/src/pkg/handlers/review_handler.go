@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"QuizApp/services/study-service/src/pkg/repository"
+)
+
+// errAnswerReviewed is returned when an answer no longer awaits review
+var errAnswerReviewed = errors.New("answer does not need review")
+
+// ListReviews handles GET /quizzes/:id/reviews, the quiz creator's queue of
+// answers waiting to be graded, oldest first
+func (h *QuizAttemptHandler) ListReviews(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid quiz ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if _, ok := h.requireQuizCreator(c, quizID); !ok {
+		return
+	}
+
+	limit := 20
+	offset := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = min(n, 100)
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if n, err := strconv.Atoi(offsetStr); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	reviews, err := h.repo.ListPendingReviews(c.Request.Context(), quizID, limit, offset)
+	if err != nil {
+		log.Printf("ListReviews: Error listing pending reviews for quiz %s: %v", quizID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to list pending reviews",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Attempts are graded against the questions they were given, drawn from
+	// pools or pinned to the revision they started on, so questions are
+	// fetched once per attempt, and once per revision for older attempts
+	revisions := make(map[uuid.UUID][]*repository.Question)
+	responseReviews := []gin.H{}
+	for _, review := range reviews {
+		questions, err := h.repo.GetAttemptQuestions(c.Request.Context(), review.AttemptID)
+		if err == nil && len(questions) == 0 {
+			var revisionKey uuid.UUID
+			if review.QuizRevisionID != nil {
+				revisionKey = *review.QuizRevisionID
+			}
+			var fetched bool
+			if questions, fetched = revisions[revisionKey]; !fetched {
+				questions, err = h.repo.GetQuestions(c.Request.Context(), quizID, review.QuizRevisionID)
+				revisions[revisionKey] = questions
+			}
+		}
+		if err != nil {
+			log.Printf("ListReviews: Error retrieving questions: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to get questions",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		responseReview := gin.H{
+			"id":         review.ID,
+			"attemptId":  review.AttemptID,
+			"userId":     review.UserID,
+			"questionId": review.QuestionID,
+			"answer":     review.Answer.Answer,
+			"createdAt":  review.CreatedAt,
+		}
+		if question := findQuestion(questions, review.QuestionID); question != nil {
+			responseReview["question"] = gin.H{
+				"text":            question.Text,
+				"type":            question.Type,
+				"correctAnswer":   question.CorrectAnswer,
+				"acceptedAnswers": question.AcceptedAnswers,
+				"explanation":     question.Explanation,
+			}
+		}
+		responseReviews = append(responseReviews, responseReview)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    responseReviews,
+	})
+}
+
+// ReviewAnswer handles POST /answers/:id/review. The quiz creator awards
+// the answer a share of the question's credit, from 0 to 1, with an
+// optional comment; the attempt is rescored and completes once none of its
+// answers await review.
+func (h *QuizAttemptHandler) ReviewAnswer(c *gin.Context) {
+	answerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid answer ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var input struct {
+		Score   *float64 `json:"score" binding:"required"`
+		Comment string   `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid input format",
+			"details": err.Error(),
+		})
+		return
+	}
+	if *input.Score < 0 || *input.Score > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Score must be between 0 and 1",
+		})
+		return
+	}
+
+	reviewerID, ok := h.requireAnswerCreator(c, answerID)
+	if !ok {
+		return
+	}
+
+	var comment *string
+	if input.Comment != "" {
+		comment = &input.Comment
+	}
+
+	attempt, answer, err := h.repo.ReviewAnswer(c.Request.Context(), answerID, func(attempt *repository.QuizAttempt) (*repository.Answer, error) {
+		modelAttempt := toModelAttempt(attempt)
+		reviewed, ok := modelAttempt.Review(answerID, *input.Score, input.Comment)
+		if !ok {
+			return nil, errAnswerReviewed
+		}
+
+		attempt.Status = string(modelAttempt.Status)
+		attempt.CorrectAnswers = modelAttempt.CorrectAnswers
+		attempt.Score = modelAttempt.Score
+		for i := range attempt.Answers {
+			stored := &attempt.Answers[i]
+			if stored.ID != answerID {
+				continue
+			}
+			stored.IsCorrect = reviewed.IsCorrect
+			stored.Score = reviewed.Score
+			stored.NeedsReview = false
+			stored.ReviewComment = comment
+			stored.ReviewedBy = &reviewerID
+			stored.ReviewedAt = reviewed.ReviewedAt
+			return stored, nil
+		}
+		return nil, errAnswerReviewed
+	})
+	if err == errAnswerReviewed {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "Answer does not need review",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("ReviewAnswer: Error reviewing answer %s: %v", answerID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to review answer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.Printf("ReviewAnswer: Answer %s scored %.2f; attempt %s is %s with score %.2f%%",
+		answerID, answer.Score, attempt.ID, attempt.Status, attempt.Score)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"answer":  toModelAnswer(*answer),
+			"attempt": toModelAttempt(attempt),
+		},
+	})
+}
+
+// requireAnswerCreator checks that the user named by the X-User-ID header
+// created the quiz an answer was given to, like requireQuizCreator
+func (h *QuizAttemptHandler) requireAnswerCreator(c *gin.Context, answerID uuid.UUID) (uuid.UUID, bool) {
+	answer, err := h.repo.GetAnswer(c.Request.Context(), answerID)
+	if err == repository.ErrAnswerNotFound {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Answer not found",
+		})
+		return uuid.Nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get answer",
+			"details": err.Error(),
+		})
+		return uuid.Nil, false
+	}
+
+	attempt, err := h.repo.GetAttempt(c.Request.Context(), answer.AttemptID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get attempt",
+			"details": err.Error(),
+		})
+		return uuid.Nil, false
+	}
+
+	return h.requireQuizCreator(c, attempt.QuizID)
+}
+
+// requireUser returns the user named by the X-User-ID header, which the API
+// gateway sets for authenticated requests. Otherwise it responds with an
+// error and reports false.
+func requireUser(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Authentication required",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// requireAttemptOwner checks that the user named by the X-User-ID header
+// took the attempt. Attempts of other users are reported as not found, so
+// their IDs cannot be probed. Otherwise it responds with an error and
+// reports false.
+func requireAttemptOwner(c *gin.Context, attempt *repository.QuizAttempt) bool {
+	userID, ok := requireUser(c)
+	if !ok {
+		return false
+	}
+	if attempt.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Attempt not found",
+		})
+		return false
+	}
+	return true
+}
+
+// requireQuizCreator checks that the user named by the X-User-ID header
+// created the quiz and returns their ID. Otherwise it responds with an
+// error and reports false.
+func (h *QuizAttemptHandler) requireQuizCreator(c *gin.Context, quizID uuid.UUID) (uuid.UUID, bool) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	quiz, err := h.repo.GetQuiz(c.Request.Context(), quizID)
+	if err == repository.ErrQuizNotFound {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Quiz not found",
+		})
+		return uuid.Nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get quiz",
+			"details": err.Error(),
+		})
+		return uuid.Nil, false
+	}
+
+	if quiz.CreatorID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "Only the quiz creator can grade its answers",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
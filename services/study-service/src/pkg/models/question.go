@@ -9,10 +9,56 @@ import (
 // QuestionType represents the type of question
 type QuestionType string
 
+// ToleranceType selects how a numeric answer's tolerance is applied
+type ToleranceType string
+
+// MatchRule selects how an open-ended answer is compared with an accepted answer
+type MatchRule string
+
+// ScoringMode selects how answers to questions with several parts earn credit
+type ScoringMode string
+
+// GradingMode selects who grades open-ended answers
+type GradingMode string
+
 const (
 	QuestionTypeMultipleChoice QuestionType = "multiple_choice"
 	QuestionTypeTrueFalse      QuestionType = "true_false"
 	QuestionTypeOpenEnded      QuestionType = "open_ended"
+	QuestionTypeMultipleSelect QuestionType = "multiple_select"
+	QuestionTypeNumeric        QuestionType = "numeric"
+	QuestionTypeMatching       QuestionType = "matching"
+	QuestionTypeOrdering       QuestionType = "ordering"
+	QuestionTypeCloze          QuestionType = "cloze"
+
+	// ScoringAllOrNothing gives credit only for exactly the correct options
+	ScoringAllOrNothing ScoringMode = "all_or_nothing"
+	// ScoringPartialCredit gives credit for each correct option selected
+	ScoringPartialCredit ScoringMode = "partial_credit"
+
+	// ToleranceAbsolute accepts numeric answers within the tolerance of the value
+	ToleranceAbsolute ToleranceType = "absolute"
+	// ToleranceRelative accepts numeric answers within the tolerance times the value
+	ToleranceRelative ToleranceType = "relative"
+
+	// MatchExact accepts the answer character for character
+	MatchExact MatchRule = "exact"
+	// MatchCaseInsensitive ignores case and repeated whitespace
+	MatchCaseInsensitive MatchRule = "case_insensitive"
+	// MatchWhitespaceNormalized ignores repeated whitespace but not case
+	MatchWhitespaceNormalized MatchRule = "whitespace_normalized"
+	// MatchRegex accepts answers the regular expression matches in full
+	MatchRegex MatchRule = "regex"
+	// MatchLevenshtein accepts answers within the maximum edit distance
+	MatchLevenshtein MatchRule = "levenshtein"
+
+	// GradingAuto grades answers against the answer key
+	GradingAuto GradingMode = "auto"
+	// GradingManual leaves answers no accepted answer matches to the quiz creator
+	GradingManual GradingMode = "manual"
+	// GradingAI has an AI model grade those answers, leaving the ones it is
+	// unsure of to the quiz creator
+	GradingAI GradingMode = "ai"
 )
 
 // Question represents a quiz question
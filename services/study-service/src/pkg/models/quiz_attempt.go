@@ -13,32 +13,48 @@ const (
 	AttemptStatusInProgress AttemptStatus = "in_progress"
 	AttemptStatusCompleted  AttemptStatus = "completed"
 	AttemptStatusAbandoned  AttemptStatus = "abandoned"
+	// AttemptStatusPendingReview marks a finished attempt whose answers are
+	// not all graded yet; it completes once the last one is reviewed
+	AttemptStatusPendingReview AttemptStatus = "pending_review"
 )
 
-// QuizAttempt represents a quiz attempt
+// QuizAttempt represents a quiz attempt. Settings are those of the quiz when
+// the attempt started; timed attempts expire at ExpiresAt, and attempts at
+// quizzes with a pass mark have Passed set once they are completed.
 type QuizAttempt struct {
 	ID                  uuid.UUID     `json:"id"`
 	UserID             uuid.UUID     `json:"userId"`
 	QuizID             uuid.UUID     `json:"quizId"`
+	QuizVersion        int           `json:"quizVersion"`
+	QuizRevisionID     *uuid.UUID    `json:"quizRevisionId,omitempty"`
 	Status             AttemptStatus `json:"status"`
 	CurrentQuestionIndex int         `json:"currentQuestionIndex"`
 	TotalQuestions     int          `json:"totalQuestions"`
+	CorrectAnswers     int          `json:"correctAnswers"`
 	Score              float64       `json:"score"`
 	StartedAt          time.Time     `json:"startedAt"`
 	CompletedAt        *time.Time    `json:"completedAt,omitempty"`
 	CreatedAt          time.Time     `json:"createdAt"`
 	UpdatedAt          time.Time     `json:"updatedAt"`
 	Answers            []Answer      `json:"answers,omitempty"`
+	Settings           *QuizSettings `json:"settings,omitempty"`
+	ExpiresAt          *time.Time    `json:"expiresAt,omitempty"`
+	Passed             *bool         `json:"passed,omitempty"`
 }
 
-// Answer represents an answer to a quiz question
+// Answer represents an answer to a quiz question. Answers that need review
+// earn nothing until a reviewer grades them.
 type Answer struct {
-	ID         uuid.UUID `json:"id"`
-	AttemptID  uuid.UUID `json:"attemptId"`
-	QuestionID uuid.UUID `json:"questionId"`
-	Answer     string    `json:"answer"`
-	IsCorrect  bool      `json:"isCorrect"`
-	CreatedAt  time.Time `json:"createdAt"`
+	ID            uuid.UUID  `json:"id"`
+	AttemptID     uuid.UUID  `json:"attemptId"`
+	QuestionID    uuid.UUID  `json:"questionId"`
+	Answer        string     `json:"answer"`
+	IsCorrect     bool       `json:"isCorrect"`
+	Score         float64    `json:"score"` // share of the question's credit earned, from 0 to 1
+	NeedsReview   bool       `json:"needsReview,omitempty"`
+	ReviewComment string     `json:"reviewComment,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
 }
 
 // NewQuizAttempt creates a new quiz attempt
@@ -58,8 +74,9 @@ func NewQuizAttempt(userID, quizID uuid.UUID, totalQuestions int) *QuizAttempt {
 	}
 }
 
-// Submit adds an answer to the quiz attempt
-func (a *QuizAttempt) Submit(questionID uuid.UUID, answer string, isCorrect bool) Answer {
+// Submit adds a graded answer to the quiz attempt and rescores it.
+// isCorrect and score must come from server-side grading, never from the client.
+func (a *QuizAttempt) Submit(questionID uuid.UUID, answer string, isCorrect bool, score float64) Answer {
 	now := time.Now().UTC()
 	newAnswer := Answer{
 		ID:         uuid.New(),
@@ -67,6 +84,7 @@ func (a *QuizAttempt) Submit(questionID uuid.UUID, answer string, isCorrect bool
 		QuestionID: questionID,
 		Answer:     answer,
 		IsCorrect:  isCorrect,
+		Score:      score,
 		CreatedAt:  now,
 	}
 
@@ -74,24 +92,151 @@ func (a *QuizAttempt) Submit(questionID uuid.UUID, answer string, isCorrect bool
 	a.CurrentQuestionIndex++
 	a.UpdatedAt = now
 
-	// Update score
+	a.Rescore()
+
+	return newAnswer
+}
+
+// SubmitForReview adds an answer that could not be graded automatically.
+// It earns nothing until Review grades it.
+func (a *QuizAttempt) SubmitForReview(questionID uuid.UUID, answer string) Answer {
+	submitted := a.Submit(questionID, answer, false, 0)
+	submitted.NeedsReview = true
+	a.Answers[len(a.Answers)-1] = submitted
+	return submitted
+}
+
+// Review grades an answer awaiting review with the share of the question's
+// credit it earned and rescores the attempt. A finished attempt completes
+// once no answers await review. It reports false if the attempt has no such
+// answer awaiting review.
+func (a *QuizAttempt) Review(answerID uuid.UUID, score float64, comment string) (Answer, bool) {
+	for i := range a.Answers {
+		answer := &a.Answers[i]
+		if answer.ID != answerID || !answer.NeedsReview {
+			continue
+		}
+		now := time.Now().UTC()
+		answer.Score = score
+		answer.IsCorrect = score >= 1
+		answer.NeedsReview = false
+		answer.ReviewComment = comment
+		answer.ReviewedAt = &now
+
+		a.UpdatedAt = now
+		a.Rescore()
+		if a.Status == AttemptStatusPendingReview && a.PendingReviews() == 0 {
+			a.Status = AttemptStatusCompleted
+			a.Judge()
+		}
+		return *answer, true
+	}
+	return Answer{}, false
+}
+
+// PendingReviews counts the answers awaiting review
+func (a *QuizAttempt) PendingReviews() int {
+	pending := 0
+	for _, ans := range a.Answers {
+		if ans.NeedsReview {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Rescore derives CorrectAnswers and Score from the graded answers.
+// Answers with partial credit count towards the score but not as correct.
+func (a *QuizAttempt) Rescore() {
 	correctAnswers := 0
+	credit := 0.0
 	for _, ans := range a.Answers {
 		if ans.IsCorrect {
 			correctAnswers++
 		}
+		credit += ans.Score
 	}
-	a.Score = float64(correctAnswers) / float64(a.TotalQuestions) * 100
+	a.CorrectAnswers = correctAnswers
+	if a.TotalQuestions > 0 {
+		a.Score = credit / float64(a.TotalQuestions) * 100
+	} else {
+		a.Score = 0
+	}
+	a.Judge()
+}
 
-	return newAnswer
+// passTolerance absorbs rounding errors in scores, so that 29 out of 100
+// questions pass a pass mark of 29%
+const passTolerance = 1e-9
+
+// Judge decides whether a completed attempt reached its quiz's pass mark.
+// Attempts at quizzes without one neither pass nor fail.
+func (a *QuizAttempt) Judge() {
+	a.Passed = nil
+	if a.Status != AttemptStatusCompleted || a.Settings == nil || a.Settings.PassingScore == nil {
+		return
+	}
+	passed := a.Score+passTolerance >= *a.Settings.PassingScore
+	a.Passed = &passed
+}
+
+// Apply gives the attempt its quiz's settings, timing it from StartedAt if
+// the quiz has a time limit
+func (a *QuizAttempt) Apply(settings QuizSettings) {
+	a.Settings = &settings
+	a.ExpiresAt = nil
+	if settings.TimeLimit > 0 {
+		expiresAt := a.StartedAt.Add(time.Duration(settings.TimeLimit) * time.Second)
+		a.ExpiresAt = &expiresAt
+	}
+	a.Judge()
+}
+
+// Expired reports whether a timed attempt is out of time at now, grace included
+func (a *QuizAttempt) Expired(now time.Time) bool {
+	return a.ExpiresAt != nil && now.After(a.ExpiresAt.Add(TimeLimitGrace))
+}
+
+// RevealsAnswers reports whether the learner may see which answers were correct
+func (a *QuizAttempt) RevealsAnswers() bool {
+	return a.Settings == nil || a.Settings.Reveals(a.Status)
 }
 
-// Complete marks the quiz attempt as completed
+// HideAnswers withholds how the attempt's answers were graded while its
+// settings do not reveal it, and the running score while it is in
+// progress. It is meant for responses to learners, never before saving.
+func (a *QuizAttempt) HideAnswers() {
+	if a.RevealsAnswers() {
+		return
+	}
+	for i := range a.Answers {
+		a.Answers[i] = a.Answers[i].Hidden()
+	}
+	if a.Status == AttemptStatusInProgress {
+		a.CorrectAnswers = 0
+		a.Score = 0
+	}
+}
+
+// Hidden returns a copy of the answer without its grading
+func (ans Answer) Hidden() Answer {
+	ans.IsCorrect = false
+	ans.Score = 0
+	ans.ReviewComment = ""
+	return ans
+}
+
+// Complete marks the quiz attempt as completed, or as pending review while
+// any of its answers await a reviewer
 func (a *QuizAttempt) Complete() {
 	now := time.Now().UTC()
 	a.Status = AttemptStatusCompleted
+	if a.PendingReviews() > 0 {
+		a.Status = AttemptStatusPendingReview
+	}
 	a.CompletedAt = &now
 	a.UpdatedAt = now
+	a.Judge()
 }
 
 // Abandon marks the quiz attempt as abandoned
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// RevealMode selects when learners see which answers were correct
+type RevealMode string
+
+const (
+	// RevealImmediately grades each answer back as it is submitted
+	RevealImmediately RevealMode = "immediately"
+	// RevealAfterSubmission withholds grading until the attempt is completed
+	RevealAfterSubmission RevealMode = "after_submission"
+	// RevealNever only ever shows learners their overall score
+	RevealNever RevealMode = "never"
+)
+
+// TimeLimitGrace is how long after its time limit an attempt still accepts
+// answers, to make up for the time they take to reach the service
+const TimeLimitGrace = 5 * time.Second
+
+// QuizSettings are a quiz's delivery settings, as the content service
+// defines them. Attempts keep the settings they started with.
+type QuizSettings struct {
+	TimeLimit        int        `json:"timeLimit,omitempty"` // in seconds
+	ShuffleQuestions bool       `json:"shuffleQuestions,omitempty"`
+	ShuffleOptions   bool       `json:"shuffleOptions,omitempty"`
+	MaxAttempts      int        `json:"maxAttempts,omitempty"`
+	PassingScore     *float64   `json:"passingScore,omitempty"` // percentage
+	RevealAnswers    RevealMode `json:"revealAnswers,omitempty"`
+}
+
+// Reveals reports whether an attempt with the given status may show which
+// answers were correct
+func (s *QuizSettings) Reveals(status AttemptStatus) bool {
+	switch s.RevealAnswers {
+	case RevealNever:
+		return false
+	case RevealAfterSubmission:
+		return status != AttemptStatusInProgress
+	}
+	return true
+}
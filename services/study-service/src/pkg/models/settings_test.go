@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttemptSettings(t *testing.T) {
+	passingScore := 29.0
+	attempt := NewQuizAttempt(uuid.New(), uuid.New(), 100)
+	attempt.Apply(QuizSettings{TimeLimit: 60, PassingScore: &passingScore, RevealAnswers: RevealAfterSubmission})
+
+	require.NotNil(t, attempt.ExpiresAt)
+	assert.False(t, attempt.Expired(attempt.StartedAt.Add(time.Minute)))
+	assert.True(t, attempt.Expired(attempt.StartedAt.Add(time.Minute+TimeLimitGrace+time.Second)))
+
+	for i := 0; i < 29; i++ {
+		attempt.Submit(uuid.New(), "answer", true, 1)
+	}
+	assert.False(t, attempt.RevealsAnswers())
+	assert.Nil(t, attempt.Passed)
+
+	hidden := *attempt
+	hidden.Answers = append([]Answer{}, attempt.Answers...)
+	hidden.HideAnswers()
+	assert.False(t, hidden.Answers[0].IsCorrect)
+	assert.Zero(t, hidden.Score)
+	assert.True(t, attempt.Answers[0].IsCorrect)
+
+	attempt.Complete()
+	assert.True(t, attempt.RevealsAnswers())
+	require.NotNil(t, attempt.Passed)
+	assert.True(t, *attempt.Passed)
+}
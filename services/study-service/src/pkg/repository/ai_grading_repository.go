@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AI grading decisions
+const (
+	// AIDecisionGraded means the AI grade was confident enough to stand
+	AIDecisionGraded = "graded"
+	// AIDecisionReview means the answer was left for the quiz creator
+	AIDecisionReview = "review"
+	// AIDecisionFailed means grading failed and the answer was left for the quiz creator
+	AIDecisionFailed = "failed"
+)
+
+// AIGrading records how an AI model graded an answer and what was decided.
+// The grade fields are empty when grading failed, with Error saying why.
+type AIGrading struct {
+	ID           uuid.UUID  `json:"id"`
+	AnswerID     uuid.UUID  `json:"answerId"`
+	GenerationID *uuid.UUID `json:"generationId,omitempty"`
+	ModelUsed    *string    `json:"modelUsed,omitempty"`
+	Score        *float64   `json:"score,omitempty"`
+	Confidence   *float64   `json:"confidence,omitempty"`
+	Rationale    *string    `json:"rationale,omitempty"`
+	Threshold    float64    `json:"threshold"`
+	Decision     string     `json:"decision"`
+	Error        *string    `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// SaveAIGrading stores an AI grading decision
+func (r *PostgresQuizAttemptRepository) SaveAIGrading(ctx context.Context, grading *AIGrading) error {
+	query := `
+		INSERT INTO answer_ai_gradings (
+			id, answer_id, generation_id, model_used, score, confidence,
+			rationale, threshold, decision, error, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		grading.ID, grading.AnswerID, grading.GenerationID, grading.ModelUsed,
+		grading.Score, grading.Confidence, grading.Rationale,
+		grading.Threshold, grading.Decision, grading.Error, grading.CreatedAt,
+	)
+	return err
+}
+
+// ListAIGradings lists the AI grading decisions for an answer, oldest first
+func (r *PostgresQuizAttemptRepository) ListAIGradings(ctx context.Context, answerID uuid.UUID) ([]AIGrading, error) {
+	query := `
+		SELECT id, answer_id, generation_id, model_used, score, confidence,
+			rationale, threshold, decision, error, created_at
+		FROM answer_ai_gradings
+		WHERE answer_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, answerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gradings := []AIGrading{}
+	for rows.Next() {
+		var grading AIGrading
+		err := rows.Scan(
+			&grading.ID, &grading.AnswerID, &grading.GenerationID, &grading.ModelUsed,
+			&grading.Score, &grading.Confidence, &grading.Rationale,
+			&grading.Threshold, &grading.Decision, &grading.Error, &grading.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		gradings = append(gradings, grading)
+	}
+
+	return gradings, rows.Err()
+}
@@ -8,16 +8,24 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"math/rand"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/google/uuid"
+
+	"QuizApp/services/study-service/src/pkg/models"
 )
 
 var (
 	ErrAttemptNotFound = errors.New("quiz attempt not found")
+	ErrQuizNotFound    = errors.New("quiz not found")
+	ErrAnswerNotFound  = errors.New("answer not found")
+
+	// ErrNotEnoughQuestions is returned when a quiz's question pools hold
+	// fewer questions than its draw rules ask for
+	ErrNotEnoughQuestions = errors.New("not enough questions in the quiz's pools")
 )
 
 // QuizAttempt represents a quiz attempt in the database
@@ -25,6 +33,8 @@ type QuizAttempt struct {
 	ID                uuid.UUID  `json:"id"`
 	UserID            uuid.UUID  `json:"userId"`
 	QuizID            uuid.UUID  `json:"quizId"`
+	QuizVersion       int        `json:"quizVersion"`
+	QuizRevisionID    *uuid.UUID `json:"quizRevisionId,omitempty"`
 	Status            string     `json:"status"`
 	TotalQuestions    int        `json:"totalQuestions"`
 	CorrectAnswers    int        `json:"correctAnswers"`
@@ -34,28 +44,198 @@ type QuizAttempt struct {
 	CreatedAt         time.Time  `json:"createdAt"`
 	UpdatedAt         time.Time  `json:"updatedAt"`
 	Answers           []Answer   `json:"answers,omitempty"`
+
+	// DrawSeed is the seed the attempt's questions were drawn from pools
+	// with, recorded so the draw can be reproduced; it is not read back
+	DrawSeed *int64 `json:"-"`
+	// Questions are stored with the attempt by CreateAttempt; see GetAttemptQuestions
+	Questions []*Question `json:"-"`
+	// Settings are the quiz's settings when the attempt started; attempts
+	// started before quizzes had settings have none
+	Settings *models.QuizSettings `json:"settings,omitempty"`
 	
 	// This field is for application logic only, not stored in DB
 	CurrentQuestionIndex int       `json:"currentQuestionIndex"`
 }
 
-// Answer represents an answer to a quiz question
+// Answer represents an answer to a quiz question. MatchedRule and
+// MatchedAnswer record which accepted answer of an open-ended question the
+// answer matched, by its rule and position. Answers that NeedsReview wait
+// for the quiz creator to grade them; the Review fields record who did.
 type Answer struct {
-	ID         uuid.UUID `json:"id"`
-	AttemptID  uuid.UUID `json:"attemptId"`
-	QuestionID uuid.UUID `json:"questionId"`
-	Answer     string    `json:"answer"`
-	IsCorrect  bool      `json:"isCorrect"`
-	CreatedAt  time.Time `json:"createdAt"`
+	ID            uuid.UUID  `json:"id"`
+	AttemptID     uuid.UUID  `json:"attemptId"`
+	QuestionID    uuid.UUID  `json:"questionId"`
+	Answer        string     `json:"answer"`
+	IsCorrect     bool       `json:"isCorrect"`
+	Score         float64    `json:"score"`
+	MatchedRule   *string    `json:"matchedRule,omitempty"`
+	MatchedAnswer *int       `json:"matchedAnswer,omitempty"`
+	NeedsReview   bool       `json:"needsReview"`
+	ReviewComment *string    `json:"reviewComment,omitempty"`
+	ReviewedBy    *uuid.UUID `json:"reviewedBy,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
 }
 
-// Question represents a quiz question from the content service
+// Question represents a quiz question from the content service. Questions
+// drawn from a question pool have its PoolID.
 type Question struct {
-	ID            uuid.UUID `json:"id"`
-	Text          string    `json:"text"`
-	Options       []string  `json:"options"`
-	CorrectAnswer string    `json:"correctAnswer"`
-	Type          string    `json:"type"`
+	ID              uuid.UUID        `json:"id"`
+	PoolID          *uuid.UUID       `json:"poolId,omitempty"`
+	Difficulty      string           `json:"difficulty,omitempty"`
+	Text            string           `json:"text"`
+	Options         []string         `json:"options"`
+	Prompts         []string         `json:"prompts,omitempty"`
+	CorrectAnswer   string           `json:"correctAnswer,omitempty"`
+	CorrectAnswers  []string         `json:"correctAnswers,omitempty"`
+	Matches         []string         `json:"matches,omitempty"`
+	CorrectOrder    []string         `json:"correctOrder,omitempty"`
+	Scoring         string           `json:"scoring,omitempty"`
+	Grading         string           `json:"grading,omitempty"`
+	Numeric         *NumericAnswer   `json:"numeric,omitempty"`
+	Blanks          []ClozeBlank     `json:"blanks,omitempty"`
+	AcceptedAnswers []AcceptedAnswer `json:"acceptedAnswers,omitempty"`
+	Rubric          string           `json:"rubric,omitempty"`
+	Media           []Attachment     `json:"media,omitempty"`
+	OptionMedia     []*Attachment    `json:"optionMedia,omitempty"`
+	Explanation     string           `json:"explanation,omitempty"`
+	Type            string           `json:"type"`
+}
+
+// Attachment is an image or audio file shown with a question or one of its
+// options. The content service signs the URLs, which expire after a while.
+type Attachment struct {
+	MediaID      uuid.UUID `json:"mediaId"`
+	Kind         string    `json:"kind,omitempty"`
+	ContentType  string    `json:"contentType,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	AltText      string    `json:"altText,omitempty"`
+	URL          string    `json:"url,omitempty"`
+	ThumbnailURL string    `json:"thumbnailUrl,omitempty"`
+}
+
+// AcceptedAnswer is one answer an open-ended question accepts and the rule
+// it is matched by. For regex rules, Answer is the pattern.
+type AcceptedAnswer struct {
+	Answer      string `json:"answer"`
+	Match       string `json:"match"`
+	MaxDistance int    `json:"maxDistance,omitempty"`
+}
+
+// ClozeBlank is one blank of a cloze question: typed in, or chosen from its
+// options when Kind is "dropdown", and the answers it accepts
+type ClozeBlank struct {
+	Kind          string   `json:"kind"`
+	Options       []string `json:"options,omitempty"`
+	Answers       []string `json:"answers,omitempty"`
+	IgnoreCase    bool     `json:"ignoreCase,omitempty"`
+	IgnoreAccents bool     `json:"ignoreAccents,omitempty"`
+}
+
+// NumericAnswer is the answer key of a numeric question: the value, how far
+// off an answer may be, and the units it may be given in
+type NumericAnswer struct {
+	Value         float64       `json:"value"`
+	Tolerance     float64       `json:"tolerance,omitempty"`
+	ToleranceType string        `json:"toleranceType,omitempty"`
+	Unit          string        `json:"unit,omitempty"`
+	Units         []NumericUnit `json:"units,omitempty"`
+	UnitRequired  bool          `json:"unitRequired,omitempty"`
+}
+
+// NumericUnit is another unit a numeric answer may be given in, with the
+// factor converting it to the answer's unit
+type NumericUnit struct {
+	Unit       string  `json:"unit"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// Attachments returns every attachment of the question and its options
+func (q *Question) Attachments() []*Attachment {
+	var attachments []*Attachment
+	for i := range q.Media {
+		attachments = append(attachments, &q.Media[i])
+	}
+	for _, attachment := range q.OptionMedia {
+		if attachment != nil {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments
+}
+
+// ForTaker returns a copy of the question without its answer key or
+// explanation. The options of matching and ordering questions are shuffled,
+// since authors often list them in answer order; their media moves with them.
+func (q *Question) ForTaker() *Question {
+	taker := *q
+	if q.Type == "matching" || q.Type == "ordering" {
+		taker.reorderOptions(rand.Perm(len(q.Options)))
+	}
+	taker.CorrectAnswer = ""
+	taker.CorrectAnswers = nil
+	taker.Matches = nil
+	taker.CorrectOrder = nil
+	taker.Numeric = nil
+	taker.AcceptedAnswers = nil
+	if q.Blanks != nil {
+		taker.Blanks = make([]ClozeBlank, len(q.Blanks))
+		for i, blank := range q.Blanks {
+			taker.Blanks[i] = ClozeBlank{Kind: blank.Kind, Options: blank.Options}
+		}
+	}
+	taker.Rubric = ""
+	taker.Explanation = ""
+	return &taker
+}
+
+// WithShuffledOptions returns a copy of the question with the options of
+// multiple choice and multiple select questions in the order rng gives
+// them. Answers are graded by option text, so the order is only cosmetic.
+func (q *Question) WithShuffledOptions(rng *rand.Rand) *Question {
+	shuffled := *q
+	if q.Type == "multiple_choice" || q.Type == "multiple_select" {
+		shuffled.reorderOptions(rng.Perm(len(q.Options)))
+	}
+	return &shuffled
+}
+
+// reorderOptions puts the question's options, and their media, in the given order
+func (q *Question) reorderOptions(order []int) {
+	options, media := q.Options, q.OptionMedia
+	q.Options = make([]string, len(order))
+	for i, j := range order {
+		q.Options[i] = options[j]
+	}
+	if media != nil {
+		q.OptionMedia = make([]*Attachment, len(order))
+		for i, j := range order {
+			if j < len(media) {
+				q.OptionMedia[i] = media[j]
+			}
+		}
+	}
+}
+
+// Quiz represents the live revision of a quiz from the content service.
+// Besides its own questions, it may draw questions from question pools.
+type Quiz struct {
+	ID         uuid.UUID           `json:"id"`
+	CreatorID  uuid.UUID           `json:"creatorId"`
+	Version    int                 `json:"version"`
+	RevisionID *uuid.UUID          `json:"revisionId,omitempty"`
+	Questions  []*Question         `json:"questions"`
+	Draws      []PoolDraw          `json:"draws,omitempty"`
+	Settings   models.QuizSettings `json:"settings"`
+}
+
+// PoolDraw is a quiz's rule for drawing Count random questions from a pool
+type PoolDraw struct {
+	PoolID       uuid.UUID `json:"poolId"`
+	Count        int       `json:"count"`
+	Difficulties []string  `json:"difficulties,omitempty"`
 }
 
 // QuizAttemptRepository defines the interface for quiz attempt operations
@@ -64,9 +244,21 @@ type QuizAttemptRepository interface {
 	GetAttempt(ctx context.Context, id uuid.UUID) (*QuizAttempt, error)
 	UpdateAttempt(ctx context.Context, attempt *QuizAttempt) error
 	ListUserAttempts(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*QuizAttempt, error)
+	CountUserAttempts(ctx context.Context, userID, quizID uuid.UUID) (int, error)
 	AddAnswer(ctx context.Context, answer *Answer) error
 	GetAttemptAnswers(ctx context.Context, attemptID uuid.UUID) ([]Answer, error)
-	GetQuestions(ctx context.Context, quizID uuid.UUID) ([]*Question, error)
+	GetAnswer(ctx context.Context, id uuid.UUID) (*Answer, error)
+	ListPendingReviews(ctx context.Context, quizID uuid.UUID, limit, offset int) ([]PendingReview, error)
+	ReviewAnswer(ctx context.Context, answerID uuid.UUID, review func(*QuizAttempt) (*Answer, error)) (*QuizAttempt, *Answer, error)
+	SaveAIGrading(ctx context.Context, grading *AIGrading) error
+	ListAIGradings(ctx context.Context, answerID uuid.UUID) ([]AIGrading, error)
+	GetAttemptQuestions(ctx context.Context, attemptID uuid.UUID) ([]*Question, error)
+	GetQuiz(ctx context.Context, quizID uuid.UUID) (*Quiz, error)
+	CanViewQuiz(ctx context.Context, quizID, userID uuid.UUID) (bool, error)
+	GetQuestions(ctx context.Context, quizID uuid.UUID, revisionID *uuid.UUID) ([]*Question, error)
+	DrawQuestions(ctx context.Context, quizID uuid.UUID, seed int64) ([]*Question, error)
+	SignMedia(ctx context.Context, mediaIDs []uuid.UUID) (map[uuid.UUID]Attachment, error)
+	RecordQuizAttempt(ctx context.Context, quizID uuid.UUID) error
 }
 
 // PostgresQuizAttemptRepository implements QuizAttemptRepository for PostgreSQL
@@ -79,35 +271,115 @@ func NewPostgresQuizAttemptRepository(db *sql.DB) *PostgresQuizAttemptRepository
 	return &PostgresQuizAttemptRepository{db: db}
 }
 
-// CreateAttempt creates a new quiz attempt
+// CreateAttempt creates a new quiz attempt along with the questions it was given
 func (r *PostgresQuizAttemptRepository) CreateAttempt(ctx context.Context, attempt *QuizAttempt) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var settings []byte
+	if attempt.Settings != nil {
+		if settings, err = json.Marshal(attempt.Settings); err != nil {
+			return err
+		}
+	}
+
 	query := `
 		INSERT INTO quiz_attempts (
-			id, user_id, quiz_id, status, total_questions,
-			correct_answers, score, started_at, completed_at, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+			id, user_id, quiz_id, quiz_version, quiz_revision_id, status, total_questions,
+			correct_answers, score, started_at, completed_at, created_at, updated_at, draw_seed, settings
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
 
-	_, err := r.db.ExecContext(ctx, query,
-		attempt.ID, attempt.UserID, attempt.QuizID, attempt.Status,
+	_, err = tx.ExecContext(ctx, query,
+		attempt.ID, attempt.UserID, attempt.QuizID, attempt.QuizVersion, attempt.QuizRevisionID, attempt.Status,
 		attempt.TotalQuestions, attempt.CorrectAnswers, attempt.Score,
-		attempt.StartedAt, attempt.CompletedAt, attempt.CreatedAt, attempt.UpdatedAt,
+		attempt.StartedAt, attempt.CompletedAt, attempt.CreatedAt, attempt.UpdatedAt, attempt.DrawSeed, settings,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	for i, question := range attempt.Questions {
+		data, err := json.Marshal(question)
+		if err != nil {
+			return fmt.Errorf("failed to encode question %s: %v", question.ID, err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO attempt_questions (attempt_id, question_id, position, pool_id, question)
+			VALUES ($1, $2, $3, $4, $5)`,
+			attempt.ID, question.ID, i+1, question.PoolID, data,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAttemptQuestions retrieves the questions stored with an attempt, in the
+// order it was given them. Attempts started before questions were stored
+// with them have none.
+func (r *PostgresQuizAttemptRepository) GetAttemptQuestions(ctx context.Context, attemptID uuid.UUID) ([]*Question, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT question
+		FROM attempt_questions
+		WHERE attempt_id = $1
+		ORDER BY position`, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var questions []*Question
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		question := &Question{}
+		if err := json.Unmarshal(data, question); err != nil {
+			return nil, fmt.Errorf("invalid question stored with attempt %s: %v", attemptID, err)
+		}
+		questions = append(questions, question)
+	}
+	return questions, rows.Err()
+}
+
+// attemptColumns are the columns scanAttempt reads
+const attemptColumns = `id, user_id, quiz_id, quiz_version, quiz_revision_id, status, total_questions,
+			correct_answers, score, started_at, completed_at, created_at, updated_at, settings`
+
+// scanAttempt scans a row selected with attemptColumns, without its answers
+func scanAttempt(row rowScanner) (*QuizAttempt, error) {
+	attempt := &QuizAttempt{}
+	var settings []byte
+	err := row.Scan(
+		&attempt.ID, &attempt.UserID, &attempt.QuizID, &attempt.QuizVersion, &attempt.QuizRevisionID, &attempt.Status,
+		&attempt.TotalQuestions, &attempt.CorrectAnswers, &attempt.Score,
+		&attempt.StartedAt, &attempt.CompletedAt, &attempt.CreatedAt, &attempt.UpdatedAt, &settings,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(settings) > 0 {
+		attempt.Settings = &models.QuizSettings{}
+		if err := json.Unmarshal(settings, attempt.Settings); err != nil {
+			return nil, fmt.Errorf("invalid settings stored with attempt %s: %v", attempt.ID, err)
+		}
+	}
+	return attempt, nil
 }
 
 // GetAttempt retrieves a quiz attempt by ID
 func (r *PostgresQuizAttemptRepository) GetAttempt(ctx context.Context, id uuid.UUID) (*QuizAttempt, error) {
 	query := `
-		SELECT id, user_id, quiz_id, status, total_questions,
-			correct_answers, score, started_at, completed_at, created_at, updated_at
+		SELECT ` + attemptColumns + `
 		FROM quiz_attempts WHERE id = $1`
 
-	attempt := &QuizAttempt{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&attempt.ID, &attempt.UserID, &attempt.QuizID, &attempt.Status,
-		&attempt.TotalQuestions, &attempt.CorrectAnswers, &attempt.Score,
-		&attempt.StartedAt, &attempt.CompletedAt, &attempt.CreatedAt, &attempt.UpdatedAt,
-	)
+	attempt, err := scanAttempt(r.db.QueryRowContext(ctx, query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, ErrAttemptNotFound
@@ -158,8 +430,7 @@ func (r *PostgresQuizAttemptRepository) UpdateAttempt(ctx context.Context, attem
 // ListUserAttempts lists all quiz attempts for a user
 func (r *PostgresQuizAttemptRepository) ListUserAttempts(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*QuizAttempt, error) {
 	query := `
-		SELECT id, user_id, quiz_id, status, total_questions,
-			correct_answers, score, started_at, completed_at, created_at, updated_at
+		SELECT ` + attemptColumns + `
 		FROM quiz_attempts
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -173,12 +444,7 @@ func (r *PostgresQuizAttemptRepository) ListUserAttempts(ctx context.Context, us
 
 	var attempts []*QuizAttempt
 	for rows.Next() {
-		attempt := &QuizAttempt{}
-		err := rows.Scan(
-			&attempt.ID, &attempt.UserID, &attempt.QuizID, &attempt.Status,
-			&attempt.TotalQuestions, &attempt.CorrectAnswers, &attempt.Score,
-			&attempt.StartedAt, &attempt.CompletedAt, &attempt.CreatedAt, &attempt.UpdatedAt,
-		)
+		attempt, err := scanAttempt(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -200,29 +466,73 @@ func (r *PostgresQuizAttemptRepository) ListUserAttempts(ctx context.Context, us
 	return attempts, nil
 }
 
+// CountUserAttempts counts a user's attempts at a quiz, whatever their status
+func (r *PostgresQuizAttemptRepository) CountUserAttempts(ctx context.Context, userID, quizID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM quiz_attempts
+		WHERE user_id = $1 AND quiz_id = $2`, userID, quizID).Scan(&count)
+	return count, err
+}
+
 // AddAnswer adds a new answer to a quiz attempt
 func (r *PostgresQuizAttemptRepository) AddAnswer(ctx context.Context, answer *Answer) error {
 	query := `
 		INSERT INTO quiz_answers (
-			id, attempt_id, question_id, answer, is_correct, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6)`
+			id, attempt_id, question_id, answer, is_correct, score,
+			matched_rule, matched_answer, needs_review, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 
 	_, err := r.db.ExecContext(ctx, query,
 		answer.ID, answer.AttemptID, answer.QuestionID,
-		answer.Answer, answer.IsCorrect, answer.CreatedAt,
+		answer.Answer, answer.IsCorrect, answer.Score,
+		answer.MatchedRule, answer.MatchedAnswer, answer.NeedsReview, answer.CreatedAt,
 	)
 	return err
 }
 
+// answerColumns are the quiz_answers columns read by scanAnswer
+const answerColumns = `id, attempt_id, question_id, answer, is_correct, score,
+	matched_rule, matched_answer, needs_review, review_comment, reviewed_by, reviewed_at, created_at`
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAnswer scans a row selected with answerColumns, followed by extra
+func scanAnswer(row rowScanner, extra ...interface{}) (Answer, error) {
+	var answer Answer
+	dest := []interface{}{
+		&answer.ID, &answer.AttemptID, &answer.QuestionID,
+		&answer.Answer, &answer.IsCorrect, &answer.Score,
+		&answer.MatchedRule, &answer.MatchedAnswer,
+		&answer.NeedsReview, &answer.ReviewComment, &answer.ReviewedBy, &answer.ReviewedAt,
+		&answer.CreatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return answer, err
+}
+
 // GetAttemptAnswers retrieves all answers for a quiz attempt
 func (r *PostgresQuizAttemptRepository) GetAttemptAnswers(ctx context.Context, attemptID uuid.UUID) ([]Answer, error) {
+	return queryAttemptAnswers(ctx, r.db, attemptID)
+}
+
+// queryer is a *sql.DB or *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryAttemptAnswers retrieves all answers for a quiz attempt in the order they were given
+func queryAttemptAnswers(ctx context.Context, db queryer, attemptID uuid.UUID) ([]Answer, error) {
 	query := `
-		SELECT id, attempt_id, question_id, answer, is_correct, created_at
+		SELECT ` + answerColumns + `
 		FROM quiz_answers
 		WHERE attempt_id = $1
 		ORDER BY created_at ASC`
 
-	rows, err := r.db.QueryContext(ctx, query, attemptID)
+	rows, err := db.QueryContext(ctx, query, attemptID)
 	if err != nil {
 		return nil, err
 	}
@@ -230,22 +540,112 @@ func (r *PostgresQuizAttemptRepository) GetAttemptAnswers(ctx context.Context, a
 
 	var answers []Answer
 	for rows.Next() {
-		var answer Answer
-		err := rows.Scan(
-			&answer.ID, &answer.AttemptID, &answer.QuestionID,
-			&answer.Answer, &answer.IsCorrect, &answer.CreatedAt,
-		)
+		answer, err := scanAnswer(rows)
 		if err != nil {
 			return nil, err
 		}
 		answers = append(answers, answer)
 	}
 
-	return answers, nil
+	return answers, rows.Err()
+}
+
+// GetQuiz retrieves the live revision of a quiz, with answer keys, from the content service
+func (r *PostgresQuizAttemptRepository) GetQuiz(ctx context.Context, quizID uuid.UUID) (*Quiz, error) {
+	var quiz Quiz
+	if err := getFromContentService(ctx, fmt.Sprintf("/quizzes/%s?view=author", quizID), &quiz); err != nil {
+		return nil, err
+	}
+	return &quiz, nil
 }
 
-// GetQuestions retrieves all questions for a quiz from the content service
-func (r *PostgresQuizAttemptRepository) GetQuestions(ctx context.Context, quizID uuid.UUID) ([]*Question, error) {
+// CanViewQuiz asks the content service whether a user may view a quiz. The
+// service reads quizzes with their answer keys whoever they are for, so
+// it checks this before reading a quiz for a learner.
+func (r *PostgresQuizAttemptRepository) CanViewQuiz(ctx context.Context, quizID, userID uuid.UUID) (bool, error) {
+	var access struct {
+		CanView bool `json:"canView"`
+	}
+	if err := getFromContentService(forUser(ctx, userID), fmt.Sprintf("/quizzes/%s/access", quizID), &access); err != nil {
+		return false, err
+	}
+	return access.CanView, nil
+}
+
+// GetQuestions retrieves the questions of a quiz from the content service. When
+// revisionID is set the questions are taken from that revision, so an attempt
+// is always graded against the quiz as it was when the attempt started.
+func (r *PostgresQuizAttemptRepository) GetQuestions(ctx context.Context, quizID uuid.UUID, revisionID *uuid.UUID) ([]*Question, error) {
+	if revisionID != nil {
+		var revision struct {
+			Quiz Quiz `json:"quiz"`
+		}
+		path := fmt.Sprintf("/quizzes/%s/revisions/%s?view=author", quizID, revisionID)
+		if err := getFromContentService(ctx, path, &revision); err != nil {
+			return nil, err
+		}
+		return revision.Quiz.Questions, nil
+	}
+
+	var questions []*Question
+	if err := getFromContentService(ctx, fmt.Sprintf("/quizzes/%s/questions?view=author", quizID), &questions); err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+// DrawQuestions draws questions from a quiz's pools, with answer keys, as its
+// draw rules ask. The content service draws the same questions for the same seed.
+func (r *PostgresQuizAttemptRepository) DrawQuestions(ctx context.Context, quizID uuid.UUID, seed int64) ([]*Question, error) {
+	var questions []*Question
+	if err := getFromContentService(ctx, fmt.Sprintf("/quizzes/%s/draw?seed=%d", quizID, seed), &questions); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// SignMedia gets fresh links to uploaded media from the content service, for
+// questions stored with attempts after their links have expired. Media that
+// no longer exists is left out of the result.
+func (r *PostgresQuizAttemptRepository) SignMedia(ctx context.Context, mediaIDs []uuid.UUID) (map[uuid.UUID]Attachment, error) {
+	signed := make(map[uuid.UUID]Attachment)
+	if len(mediaIDs) == 0 {
+		return signed, nil
+	}
+	body := struct {
+		MediaIDs []uuid.UUID `json:"mediaIds"`
+	}{MediaIDs: mediaIDs}
+	if err := callContentService(ctx, http.MethodPost, "/media/urls", body, &signed); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// RecordQuizAttempt reports a started attempt to the content service, which
+// counts them so quiz listings can sort by attempts
+func (r *PostgresQuizAttemptRepository) RecordQuizAttempt(ctx context.Context, quizID uuid.UUID) error {
+	return callContentService(ctx, http.MethodPost, fmt.Sprintf("/quizzes/%s/attempts", quizID), nil, nil)
+}
+
+// getFromContentService performs a trusted GET against the content service and
+// decodes the "data" field of its response into out
+func getFromContentService(ctx context.Context, path string, out interface{}) error {
+	return callContentService(ctx, http.MethodGet, path, nil, out)
+}
+
+// userKey is the context key of the user content service requests are made for
+type userKey struct{}
+
+// forUser makes content service requests made with the returned context
+// name userID as the user they are made for
+func forUser(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// callContentService performs a trusted request against the content service,
+// sending in as JSON if set, and decodes the "data" field of its response into out
+func callContentService(ctx context.Context, method, path string, in, out interface{}) error {
 	// Get content service URL from environment variable or use default
 	contentServiceURL := os.Getenv("CONTENT_SERVICE_URL")
 	if contentServiceURL == "" {
@@ -257,39 +657,56 @@ func (r *PostgresQuizAttemptRepository) GetQuestions(ctx context.Context, quizID
 		contentServiceURL = "http://localhost:8081"
 	}
 
-	// Adding detailed logging for debugging
-	log.Printf("DEBUG: Fetching questions from content service for quiz ID: %s using URL: %s", quizID.String(), contentServiceURL)
-	
-	// We already have the quiz ID - no need to query the database for it
-	
-	requestURL := fmt.Sprintf("%s/quizzes/%s/questions", contentServiceURL, quizID)
-	log.Printf("DEBUG: Making request to: %s", requestURL)
-	
-	resp, err := http.Get(requestURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch questions from content service: %v", err)
+	// Grading needs the answer keys, so request the author view as a trusted service
+	requestURL := contentServiceURL + path
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode content service request: %v", err)
+		}
+		body = bytes.NewReader(data)
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-	log.Printf("DEBUG: Content service response status: %d", resp.StatusCode)
-	log.Printf("DEBUG: Content service response body: %s", string(body))
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return fmt.Errorf("failed to build content service request: %v", err)
+	}
+	req.Header.Set("X-Service-Token", os.Getenv("INTERNAL_SERVICE_TOKEN"))
+	if userID, ok := ctx.Value(userKey{}).(uuid.UUID); ok {
+		req.Header.Set("X-User-ID", userID.String())
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
-	resp.Body = io.NopCloser(bytes.NewBuffer(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch from content service: %v", err)
+	}
+	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("content service returned status %d: %s", resp.StatusCode, string(body))
+	respBody, _ := io.ReadAll(resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrQuizNotFound
+	case http.StatusConflict:
+		// Draws from pools that are too small are the only conflicts it reports
+		return fmt.Errorf("%w: %s", ErrNotEnoughQuestions, string(respBody))
+	default:
+		return fmt.Errorf("content service returned status %d: %s", resp.StatusCode, string(respBody))
 	}
 
-	var response struct {
+	response := struct {
 		Success bool        `json:"success"`
-		Data    []*Question `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+		Data    interface{} `json:"data"`
+	}{Data: out}
+	if err := json.NewDecoder(bytes.NewReader(respBody)).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
 	}
 
-	log.Printf("DEBUG: Returning %d questions for quiz %s", len(response.Data), quizID.String())
-	
-	return response.Data, nil
-} 
\ No newline at end of file
+	return nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PendingReview is an answer waiting for the quiz creator to grade it,
+// with the attempt it belongs to
+type PendingReview struct {
+	Answer
+	UserID         uuid.UUID  `json:"userId"`
+	QuizRevisionID *uuid.UUID `json:"quizRevisionId,omitempty"`
+}
+
+// GetAnswer retrieves an answer by ID
+func (r *PostgresQuizAttemptRepository) GetAnswer(ctx context.Context, id uuid.UUID) (*Answer, error) {
+	query := `SELECT ` + answerColumns + ` FROM quiz_answers WHERE id = $1`
+
+	answer, err := scanAnswer(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAnswerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+// ListPendingReviews lists the answers to a quiz that wait for review, oldest first
+func (r *PostgresQuizAttemptRepository) ListPendingReviews(ctx context.Context, quizID uuid.UUID, limit, offset int) ([]PendingReview, error) {
+	query := `
+		SELECT a.id, a.attempt_id, a.question_id, a.answer, a.is_correct, a.score,
+			a.matched_rule, a.matched_answer, a.needs_review, a.review_comment, a.reviewed_by, a.reviewed_at, a.created_at,
+			t.user_id, t.quiz_revision_id
+		FROM quiz_answers a
+		JOIN quiz_attempts t ON t.id = a.attempt_id
+		WHERE t.quiz_id = $1 AND a.needs_review
+		ORDER BY a.created_at ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, quizID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []PendingReview{}
+	for rows.Next() {
+		var review PendingReview
+		review.Answer, err = scanAnswer(rows, &review.UserID, &review.QuizRevisionID)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+// ReviewAnswer grades an answer and rescores its attempt in one transaction.
+// The attempt is locked and loaded with its answers, then passed to review,
+// which returns the graded answer; both are written back unless review fails.
+func (r *PostgresQuizAttemptRepository) ReviewAnswer(ctx context.Context, answerID uuid.UUID, review func(*QuizAttempt) (*Answer, error)) (*QuizAttempt, *Answer, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var attemptID uuid.UUID
+	err = tx.QueryRowContext(ctx, `SELECT attempt_id FROM quiz_answers WHERE id = $1`, answerID).Scan(&attemptID)
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrAnswerNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := `
+		SELECT ` + attemptColumns + `
+		FROM quiz_attempts WHERE id = $1
+		FOR UPDATE`
+
+	attempt, err := scanAttempt(tx.QueryRowContext(ctx, query, attemptID))
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrAttemptNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	attempt.Answers, err = queryAttemptAnswers(ctx, tx, attempt.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attempt.CurrentQuestionIndex = len(attempt.Answers)
+
+	answer, err := review(attempt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE quiz_answers
+		SET is_correct = $1, score = $2, needs_review = $3,
+			review_comment = $4, reviewed_by = $5, reviewed_at = $6
+		WHERE id = $7`,
+		answer.IsCorrect, answer.Score, answer.NeedsReview,
+		answer.ReviewComment, answer.ReviewedBy, answer.ReviewedAt, answer.ID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attempt.UpdatedAt = time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE quiz_attempts
+		SET status = $1, correct_answers = $2, score = $3,
+			completed_at = $4, updated_at = $5
+		WHERE id = $6`,
+		attempt.Status, attempt.CorrectAnswers, attempt.Score,
+		attempt.CompletedAt, attempt.UpdatedAt, attempt.ID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return attempt, answer, nil
+}